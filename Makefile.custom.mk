@@ -39,6 +39,7 @@ $(CONTROLLER_GEN): $(TOOLS_DIR)/controller-gen/go.mod
 generate:
 	@$(MAKE) generate-deepcopy
 	@$(MAKE) generate-manifests
+	@$(MAKE) generate-values-schema
 
 .PHONY: verify
 verify:
@@ -61,6 +62,21 @@ generate-manifests: $(CONTROLLER_GEN)
 	paths=./$(API_DIR)/... \
 	output:dir="./$(CRD_DIR)"
 
+.PHONY: generate-values-schema
+generate-values-schema:
+	@echo "$(GEN_COLOR)Generating cluster values schema$(NO_COLOR)"
+	go run . values-schema > docs/cluster-values.schema.json
+
+# ENVTEST_K8S_VERSION is the version of the kube-apiserver and etcd binaries
+# the envtest suite runs against.
+ENVTEST_K8S_VERSION ?= 1.36.x
+
+.PHONY: test-envtest
+test-envtest:
+	@echo "$(BUILD_COLOR)Running envtest suite$(NO_COLOR)"
+	KUBEBUILDER_ASSETS="$$(go run sigs.k8s.io/controller-runtime/tools/setup-envtest@release-0.24 use $(ENVTEST_K8S_VERSION) --bin-dir $(TOOLS_BIN_DIR) -p path)" \
+	go test -tags envtest -count 1 ./service/envtest/...
+
 .PHONY: clean-generated
 clean-generated:
 	@echo "$(GEN_COLOR)Cleaning generated files$(NO_COLOR)"
@@ -1,6 +1,7 @@
 package chartoperator
 
 type ChartOperator struct {
-	Catalog string
-	Version string
+	Catalog             string
+	CompatibilityMatrix string
+	Version             string
 }
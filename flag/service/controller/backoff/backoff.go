@@ -0,0 +1,8 @@
+package backoff
+
+// Backoff is a data structure to hold the configuration flags of the
+// per-cluster exponential backoff applied to failing resources.
+type Backoff struct {
+	InitialInterval string
+	MaxInterval     string
+}
@@ -0,0 +1,8 @@
+package client
+
+// Client is a data structure to hold Kubernetes API client rate limiting
+// configuration flags.
+type Client struct {
+	Burst string
+	QPS   string
+}
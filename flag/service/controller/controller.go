@@ -1,5 +1,20 @@
 package controller
 
+import (
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/controller/backoff"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/controller/client"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/controller/leaderelection"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/controller/orphansweeper"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/controller/retry"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/controller/sharding"
+)
+
 type Controller struct {
-	ResyncPeriod string
+	Backoff        backoff.Backoff
+	Client         client.Client
+	LeaderElection leaderelection.LeaderElection
+	OrphanSweeper  orphansweeper.OrphanSweeper
+	ResyncPeriod   string
+	Retry          retry.Retry
+	Sharding       sharding.Sharding
 }
@@ -0,0 +1,12 @@
+package leaderelection
+
+// LeaderElection is a data structure to hold leader election specific
+// configuration flags.
+type LeaderElection struct {
+	Enabled        string
+	LeaseDuration  string
+	LeaseName      string
+	LeaseNamespace string
+	RenewDeadline  string
+	RetryPeriod    string
+}
@@ -0,0 +1,9 @@
+package orphansweeper
+
+// OrphanSweeper is a data structure to hold orphan sweeper specific
+// configuration flags.
+type OrphanSweeper struct {
+	DryRun   string
+	Enabled  string
+	Interval string
+}
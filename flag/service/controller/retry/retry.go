@@ -0,0 +1,8 @@
+package retry
+
+// Retry is a data structure to hold the configuration flags of the retries
+// executed within a single reconciliation of a resource.
+type Retry struct {
+	Interval   string
+	MaxRetries string
+}
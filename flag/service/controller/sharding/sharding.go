@@ -0,0 +1,12 @@
+package sharding
+
+// Sharding is a data structure to hold sharding specific configuration flags.
+type Sharding struct {
+	Enabled        string
+	Label          string
+	LeaseDuration  string
+	LeaseName      string
+	LeaseNamespace string
+	RenewPeriod    string
+	Strategy       string
+}
@@ -0,0 +1,7 @@
+package aws
+
+// AWS is a data structure to hold guest cluster AWS specific configuration
+// flags.
+type AWS struct {
+	ManagementAccountID string
+}
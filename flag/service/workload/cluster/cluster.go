@@ -2,15 +2,21 @@ package cluster
 
 import (
 	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/workload/cluster/aws"
 	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/workload/cluster/calico"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/workload/cluster/extravalues"
 	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/workload/cluster/kubernetes"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/workload/cluster/metadata"
 )
 
 // Cluster is a data structure to hold cluster specific configuration flags.
 type Cluster struct {
-	BaseDomain string
-	Calico     calico.Calico
-	Kubernetes kubernetes.Kubernetes
-	Owner      string
-	Proxy      proxy.Proxy
+	AWS         aws.AWS
+	BaseDomain  string
+	Calico      calico.Calico
+	ExtraValues extravalues.ExtraValues
+	Kubernetes  kubernetes.Kubernetes
+	Metadata    metadata.Metadata
+	Owner       string
+	Proxy       proxy.Proxy
 }
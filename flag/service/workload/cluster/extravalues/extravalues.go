@@ -0,0 +1,13 @@
+package extravalues
+
+import (
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/workload/cluster/extravalues/topologyvariables"
+)
+
+// ExtraValues is a data structure to hold the references of the
+// installation wide extra cluster values.
+type ExtraValues struct {
+	ConfigMap         string
+	Secret            string
+	TopologyVariables topologyvariables.TopologyVariables
+}
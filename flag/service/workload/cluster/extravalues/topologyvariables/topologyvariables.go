@@ -0,0 +1,8 @@
+package topologyvariables
+
+// TopologyVariables is a data structure to hold the allowlists of ClusterClass
+// topology variables rendered into the cluster values ConfigMap and Secret.
+type TopologyVariables struct {
+	ConfigMap string
+	Secret    string
+}
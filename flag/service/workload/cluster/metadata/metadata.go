@@ -0,0 +1,8 @@
+package metadata
+
+// Metadata is a data structure to hold the allowlists of Cluster labels and
+// annotations rendered into the cluster values.
+type Metadata struct {
+	Annotations string
+	Labels      string
+}
@@ -109,10 +109,33 @@ func mainE(ctx context.Context) error {
 	daemonCommand.PersistentFlags().String(f.Service.App.AppOperator.Catalog, "", "Catalog for app-operator app CR.")
 	daemonCommand.PersistentFlags().String(f.Service.App.AppOperator.Version, "", "Version for app-operator app CR.")
 	daemonCommand.PersistentFlags().String(f.Service.App.ChartOperator.Catalog, "", "Catalog for chart-operator app CR.")
+	daemonCommand.PersistentFlags().String(f.Service.App.ChartOperator.CompatibilityMatrix, "", "Path of the YAML file mapping Kubernetes version ranges to chart-operator versions. When empty the configured version is used for all clusters.")
 	daemonCommand.PersistentFlags().String(f.Service.App.ChartOperator.Version, "", "Version for chart-operator app CR.")
 
 	daemonCommand.PersistentFlags().String(f.Service.Image.Registry.Domain, "gsoci.azurecr.io", "Image registry.")
 	daemonCommand.PersistentFlags().String(f.Service.Controller.ResyncPeriod, "5m", "Duration after which a complete sync with all known cluster-objects the controller watches is performed.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Backoff.InitialInterval, "10s", "Duration a resource is skipped for a cluster after it failed for the first time. Doubles with every consecutive failure.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Backoff.MaxInterval, "5m", "Maximum duration a failing resource is skipped for a cluster.")
+	daemonCommand.PersistentFlags().Int(f.Service.Controller.Client.Burst, 0, "Burst of the Kubernetes API client. When 0 the client-go default is used.")
+	daemonCommand.PersistentFlags().Float64(f.Service.Controller.Client.QPS, 0, "Queries per second of the Kubernetes API client. When 0 the client-go default is used.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Retry.Interval, "1s", "Duration between retries of a failing resource within a single reconciliation.")
+	daemonCommand.PersistentFlags().Int(f.Service.Controller.Retry.MaxRetries, 3, "Number of retries of a failing resource within a single reconciliation.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.LeaderElection.Enabled, false, "Whether to use lease based leader election so that only one replica reconciles clusters.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.LeaderElection.LeaseDuration, "15s", "Duration non-leader replicas wait before trying to acquire the lease.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.LeaderElection.LeaseName, "cluster-apps-operator", "Name of the Lease used for leader election.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.LeaderElection.LeaseNamespace, "", "Namespace of the Lease used for leader election. When empty the namespace of the pod is used.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.LeaderElection.RenewDeadline, "10s", "Duration the leader retries renewing the lease before giving up leadership.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.LeaderElection.RetryPeriod, "2s", "Duration replicas wait between lease acquisition and renewal attempts.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.OrphanSweeper.DryRun, true, "Whether the orphan sweeper only logs orphaned objects instead of deleting them.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.OrphanSweeper.Enabled, false, "Whether to periodically delete generated objects whose Cluster no longer exists.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.OrphanSweeper.Interval, "1h", "Duration between runs of the orphan sweeper.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.Sharding.Enabled, false, "Whether to distribute clusters across all replicas. Cannot be combined with leader election.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Sharding.Label, "", "Cluster label used as shard key with the label strategy.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Sharding.LeaseDuration, "30s", "Duration after which a replica that stopped renewing its Lease is no longer considered a shard member.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Sharding.LeaseName, "cluster-apps-operator-shard", "Name prefix of the Leases used for shard membership.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Sharding.LeaseNamespace, "", "Namespace of the Leases used for shard membership. When empty the namespace of the pod is used.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Sharding.RenewPeriod, "10s", "Duration between renewals of the shard membership Lease.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Sharding.Strategy, "hash", "How clusters are assigned to shards, one of hash, label or namespace.")
 
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.Address, "http://127.0.0.1:6443", "Address used to connect to Kubernetes. When empty in-cluster config is created.")
 	daemonCommand.PersistentFlags().Bool(f.Service.Kubernetes.InCluster, false, "Whether to use the in-cluster config to authenticate with Kubernetes.")
@@ -122,10 +145,17 @@ func mainE(ctx context.Context) error {
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.KeyFile, "", "Key file path to use to authenticate with Kubernetes.")
 
 	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.BaseDomain, "", "Cluster owner base domain.")
+	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.AWS.ManagementAccountID, "", "AWS account of the management cluster. Used as the account of CAPA workload clusters using the AWSClusterControllerIdentity.")
 	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.Calico.CIDR, "", "Prefix length for the CIDR block used by Calico.")
 	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.Calico.Subnet, "", "Network address for the CIDR block used by Calico.")
+	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.ExtraValues.ConfigMap, "", "ConfigMap with installation wide extra cluster values, as namespace/name. Merged into the cluster values ConfigMap of every cluster.")
+	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.ExtraValues.Secret, "", "Secret with installation wide extra cluster values, as namespace/name. Merged into the cluster values Secret of every cluster.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Workload.Cluster.ExtraValues.TopologyVariables.ConfigMap, []string{}, "ClusterClass topology variables rendered into the cluster values ConfigMap, as name=path. The value of the variable is set at the dotted path, which defaults to the variable name.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Workload.Cluster.ExtraValues.TopologyVariables.Secret, []string{}, "ClusterClass topology variables rendered into the cluster values Secret, as name=path. The value of the variable is set at the dotted path, which defaults to the variable name.")
 	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.Kubernetes.API.ClusterIPRange, "", "CIDR Range for Pods in cluster.")
 	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.Kubernetes.ClusterDomain, "cluster.local", "Internal Kubernetes domain.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Workload.Cluster.Metadata.Annotations, []string{}, "Cluster annotations rendered into the metadata section of the cluster values. Entries ending with * match all annotations with that prefix.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Workload.Cluster.Metadata.Labels, []string{}, "Cluster labels rendered into the metadata section of the cluster values. Entries ending with * match all labels with that prefix.")
 	daemonCommand.PersistentFlags().String(f.Service.Workload.Cluster.Owner, "", "Management cluster codename.")
 
 	daemonCommand.PersistentFlags().String(f.Service.Proxy.NoProxy, "", "Installation specific no_proxy values.")
@@ -137,6 +167,8 @@ func mainE(ctx context.Context) error {
 			* inject into cluster values
 	*/
 
+	newCommand.CobraCommand().AddCommand(newValuesSchemaCommand())
+
 	err = newCommand.CobraCommand().Execute()
 	if err != nil {
 		return microerror.Mask(err)
@@ -0,0 +1,21 @@
+package jsonschema
+
+import "github.com/giantswarm/microerror"
+
+var validationFailedError = &microerror.Error{
+	Kind: "validationFailedError",
+}
+
+// IsValidationFailed asserts validationFailedError.
+func IsValidationFailed(err error) bool {
+	return microerror.Cause(err) == validationFailedError
+}
+
+var unsupportedSchemaError = &microerror.Error{
+	Kind: "unsupportedSchemaError",
+}
+
+// IsUnsupportedSchema asserts unsupportedSchemaError.
+func IsUnsupportedSchema(err error) bool {
+	return microerror.Cause(err) == unsupportedSchemaError
+}
@@ -0,0 +1,134 @@
+// Package jsonschema generates JSON schemas from Go types and validates
+// documents against them. It only supports the subset of JSON schema needed
+// to describe plain Go structs marshalled with encoding/json, which are the
+// keywords $schema, $id, title, type, const, properties, required,
+// additionalProperties and items. Parse rejects schemas using any other
+// keyword, so constraints this package cannot validate are never silently
+// ignored.
+package jsonschema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON schema. Type is either a string or a list of strings and
+// AdditionalProperties is either false or a *Schema.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// Generate returns the schema of the given value's type. Struct fields are
+// required unless they are pointers or tagged with omitempty. Structs do
+// not allow additional properties.
+func Generate(v interface{}) *Schema {
+	s := generate(reflect.TypeOf(v))
+	s.Schema = Draft
+
+	return s
+}
+
+func generate(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return generate(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// Nil slices are marshalled as null.
+		return &Schema{Type: []string{"array", "null"}, Items: generate(t.Elem())}
+	case reflect.Map:
+		// Nil maps are marshalled as null.
+		return &Schema{Type: []string{"object", "null"}, AdditionalProperties: generate(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{
+			Type:                 "object",
+			Properties:           map[string]*Schema{},
+			AdditionalProperties: false,
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			name, omitEmpty := jsonName(f)
+			if name == "-" {
+				continue
+			}
+
+			s.Properties[name] = generate(f.Type)
+			if !omitEmpty && f.Type.Kind() != reflect.Ptr {
+				s.Required = append(s.Required, name)
+			}
+		}
+
+		sort.Strings(s.Required)
+
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// Lookup returns the schema of the value at the given path of keys, or nil if
+// the schema does not declare it. Keys of maps are declared by their
+// additionalProperties schema.
+func (s *Schema) Lookup(path []string) *Schema {
+	for _, k := range path {
+		if p, ok := s.Properties[k]; ok {
+			s = p
+			continue
+		}
+
+		a, ok := s.AdditionalProperties.(*Schema)
+		if !ok {
+			return nil
+		}
+		s = a
+	}
+
+	return s
+}
+
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+
+	var omitEmpty bool
+	for _, p := range parts[1:] {
+		if p == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty
+}
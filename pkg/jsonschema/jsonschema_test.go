@@ -0,0 +1,152 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+type testNested struct {
+	Enabled bool `json:"enabled"`
+}
+
+type testValues struct {
+	Version  string            `json:"version"`
+	Name     string            `json:"name"`
+	Port     int32             `json:"port"`
+	Labels   map[string]string `json:"labels"`
+	Nested   testNested        `json:"nested"`
+	Optional *string           `json:"optional,omitempty"`
+	List     []string          `json:"list,omitempty"`
+}
+
+func Test_Schema_Validate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		doc           string
+		expectedError bool
+	}{
+		{
+			name:          "case 0: valid document",
+			doc:           `{"version": "1", "name": "eggs2", "port": 6443, "labels": {"a": "b"}, "nested": {"enabled": true}}`,
+			expectedError: false,
+		},
+		{
+			name:          "case 1: optional fields and null map are valid",
+			doc:           `{"version": "1", "name": "eggs2", "port": 6443, "labels": null, "nested": {"enabled": false}, "optional": "", "list": ["a"]}`,
+			expectedError: false,
+		},
+		{
+			name:          "case 2: missing required field",
+			doc:           `{"version": "1", "port": 6443, "labels": {}, "nested": {"enabled": true}}`,
+			expectedError: true,
+		},
+		{
+			name:          "case 3: unknown field",
+			doc:           `{"version": "1", "name": "eggs2", "port": 6443, "labels": {}, "nested": {"enabled": true, "unknown": 1}}`,
+			expectedError: true,
+		},
+		{
+			name:          "case 4: wrong type",
+			doc:           `{"version": "1", "name": "eggs2", "port": 6443.5, "labels": {}, "nested": {"enabled": true}}`,
+			expectedError: true,
+		},
+		{
+			name:          "case 5: wrong map value type",
+			doc:           `{"version": "1", "name": "eggs2", "port": 6443, "labels": {"a": 1}, "nested": {"enabled": true}}`,
+			expectedError: true,
+		},
+		{
+			name:          "case 6: wrong const",
+			doc:           `{"version": "2", "name": "eggs2", "port": 6443, "labels": {}, "nested": {"enabled": true}}`,
+			expectedError: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			s := Generate(testValues{})
+			s.Properties["version"].Const = "1"
+
+			var doc interface{}
+			err := json.Unmarshal([]byte(tc.doc), &doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = s.Validate(doc)
+			if tc.expectedError && !IsValidationFailed(err) {
+				t.Fatalf("expected validation error, got %#v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+		})
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	generated, err := json.Marshal(Generate(testValues{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name          string
+		schema        string
+		doc           string
+		expectedError bool
+	}{
+		{
+			name:   "case 0: generated schema round trips",
+			schema: string(generated),
+			doc:    `{"version": "1", "name": "eggs2", "port": 6443, "labels": {"a": 1}, "nested": {"enabled": true}}`,
+		},
+		{
+			name:          "case 1: unsupported keyword",
+			schema:        `{"type": "string", "pattern": "^[a-z]+$"}`,
+			expectedError: true,
+		},
+		{
+			name:          "case 2: unsupported keyword in nested schema",
+			schema:        `{"type": "object", "additionalProperties": {"type": "string", "enum": ["a"]}}`,
+			expectedError: true,
+		},
+		{
+			name:          "case 3: unsupported type",
+			schema:        `{"type": ["string", "date"]}`,
+			expectedError: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			s, err := Parse([]byte(tc.schema))
+			if tc.expectedError {
+				if !IsUnsupportedSchema(err) {
+					t.Fatalf("expected unsupported schema error, got %#v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+
+			// The parsed schema must still reject the wrong map value type.
+			var doc interface{}
+			err = json.Unmarshal([]byte(tc.doc), &doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = s.Validate(doc)
+			if !IsValidationFailed(err) {
+				t.Fatalf("expected validation error, got %#v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/giantswarm/microerror"
+)
+
+var types = map[string]bool{
+	"array":   true,
+	"boolean": true,
+	"integer": true,
+	"null":    true,
+	"number":  true,
+	"object":  true,
+	"string":  true,
+}
+
+// Parse decodes the given JSON schema. It fails for keywords and values not
+// supported by Validate.
+func Parse(b []byte) (*Schema, error) {
+	var s Schema
+	err := json.Unmarshal(b, &s)
+	if IsUnsupportedSchema(err) {
+		return nil, microerror.Mask(err)
+	} else if err != nil {
+		return nil, microerror.Maskf(unsupportedSchemaError, "%s", err.Error())
+	}
+
+	return &s, nil
+}
+
+// UnmarshalJSON decodes the schema strictly, see Parse.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	// schema has the fields of Schema without its methods, so decoding
+	// it does not recurse into UnmarshalJSON.
+	type schema Schema
+
+	var raw struct {
+		schema
+		Type                 json.RawMessage `json:"type,omitempty"`
+		AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+
+	err := d.Decode(&raw)
+	if err != nil {
+		return microerror.Maskf(unsupportedSchemaError, "%s", err.Error())
+	}
+
+	*s = Schema(raw.schema)
+
+	s.Type, err = parseType(raw.Type)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	s.AdditionalProperties, err = parseAdditionalProperties(raw.AdditionalProperties)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func parseType(b json.RawMessage) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var t string
+	if json.Unmarshal(b, &t) == nil {
+		if !types[t] {
+			return nil, microerror.Maskf(unsupportedSchemaError, "type %#q is not supported", t)
+		}
+		return t, nil
+	}
+
+	var list []string
+	err := json.Unmarshal(b, &list)
+	if err != nil {
+		return nil, microerror.Maskf(unsupportedSchemaError, "type must be a string or a list of strings, got %s", string(b))
+	}
+	for _, t := range list {
+		if !types[t] {
+			return nil, microerror.Maskf(unsupportedSchemaError, "type %#q is not supported", t)
+		}
+	}
+
+	return list, nil
+}
+
+func parseAdditionalProperties(b json.RawMessage) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var allowed bool
+	if json.Unmarshal(b, &allowed) == nil {
+		return allowed, nil
+	}
+
+	var s Schema
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &s, nil
+}
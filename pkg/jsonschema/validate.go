@@ -0,0 +1,128 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Validate validates the given document against the schema. The document
+// must consist of the types produced by encoding/json when unmarshalling
+// into an interface{}. All violations are reported in a single error.
+func (s *Schema) Validate(doc interface{}) error {
+	var violations []string
+	s.validate("", doc, &violations)
+
+	if len(violations) > 0 {
+		return microerror.Maskf(validationFailedError, "%s", strings.Join(violations, ", "))
+	}
+
+	return nil
+}
+
+func (s *Schema) validate(path string, doc interface{}, violations *[]string) {
+	if path == "" {
+		path = "."
+	}
+
+	if s.Const != nil && !reflect.DeepEqual(s.Const, doc) {
+		*violations = append(*violations, fmt.Sprintf("%s must be %v", path, s.Const))
+		return
+	}
+
+	if !s.matchesType(doc) {
+		*violations = append(*violations, fmt.Sprintf("%s must be of type %v", path, s.Type))
+		return
+	}
+
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for _, r := range s.Required {
+			if _, ok := v[r]; !ok {
+				*violations = append(*violations, fmt.Sprintf("%s is required", join(path, r)))
+			}
+		}
+
+		var keys []string
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if p, ok := s.Properties[k]; ok {
+				p.validate(join(path, k), v[k], violations)
+				continue
+			}
+
+			switch a := s.AdditionalProperties.(type) {
+			case bool:
+				if !a {
+					*violations = append(*violations, fmt.Sprintf("%s is not allowed", join(path, k)))
+				}
+			case *Schema:
+				a.validate(join(path, k), v[k], violations)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	}
+}
+
+func (s *Schema) matchesType(doc interface{}) bool {
+	var types []string
+	switch t := s.Type.(type) {
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	default:
+		return true
+	}
+
+	for _, t := range types {
+		if typeOf(doc) == t || (t == "number" && typeOf(doc) == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeOf(doc interface{}) string {
+	switch v := doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", doc)
+	}
+}
+
+func join(path, key string) string {
+	if path == "." {
+		return "." + key
+	}
+
+	return path + "." + key
+}
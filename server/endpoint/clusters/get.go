@@ -0,0 +1,100 @@
+package clusters
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service"
+)
+
+const (
+	// GetMethod is the HTTP method the get endpoint is registered for.
+	GetMethod = "GET"
+	// GetName identifies the get endpoint.
+	GetName = "clusters/get"
+	// GetPath is the HTTP request path the get endpoint is registered for.
+	GetPath = "/clusters/{namespace}/{name}"
+)
+
+type getRequest struct {
+	Namespace string
+	Name      string
+}
+
+// GetEndpoint returns the reconcile state of a single watched cluster.
+type GetEndpoint struct {
+	logger  micrologger.Logger
+	service *service.Service
+}
+
+// NewGet creates a new configured get endpoint.
+func NewGet(config Config) (*GetEndpoint, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Service == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Service must not be empty", config)
+	}
+
+	e := &GetEndpoint{
+		logger:  config.Logger,
+		service: config.Service,
+	}
+
+	return e, nil
+}
+
+func (e *GetEndpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		vars := mux.Vars(r)
+
+		request := getRequest{
+			Namespace: vars["namespace"],
+			Name:      vars["name"],
+		}
+
+		return request, nil
+	}
+}
+
+func (e *GetEndpoint) Encoder() kithttp.EncodeResponseFunc {
+	return encodeJSON
+}
+
+func (e *GetEndpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r, ok := request.(getRequest)
+		if !ok {
+			return nil, microerror.Maskf(wrongTypeError, "expected '%T' got '%T'", getRequest{}, request)
+		}
+
+		status, ok := e.service.ClusterStatus.Get(r.Namespace, r.Name)
+		if !ok {
+			return nil, microerror.Maskf(notFoundError, "cluster '%s/%s' is not known", r.Namespace, r.Name)
+		}
+
+		return status, nil
+	}
+}
+
+func (e *GetEndpoint) Method() string {
+	return GetMethod
+}
+
+func (e *GetEndpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *GetEndpoint) Name() string {
+	return GetName
+}
+
+func (e *GetEndpoint) Path() string {
+	return GetPath
+}
@@ -0,0 +1,90 @@
+package clusters
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service"
+)
+
+const (
+	// ListMethod is the HTTP method the list endpoint is registered for.
+	ListMethod = "GET"
+	// ListName identifies the list endpoint.
+	ListName = "clusters/list"
+	// ListPath is the HTTP request path the list endpoint is registered for.
+	ListPath = "/clusters"
+)
+
+// Config represents the configuration used to create the clusters endpoints.
+type Config struct {
+	Logger  micrologger.Logger
+	Service *service.Service
+}
+
+// ListEndpoint returns the reconcile state of all watched clusters.
+type ListEndpoint struct {
+	logger  micrologger.Logger
+	service *service.Service
+}
+
+// NewList creates a new configured list endpoint.
+func NewList(config Config) (*ListEndpoint, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Service == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Service must not be empty", config)
+	}
+
+	e := &ListEndpoint{
+		logger:  config.Logger,
+		service: config.Service,
+	}
+
+	return e, nil
+}
+
+func (e *ListEndpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		return nil, nil
+	}
+}
+
+func (e *ListEndpoint) Encoder() kithttp.EncodeResponseFunc {
+	return encodeJSON
+}
+
+func (e *ListEndpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return e.service.ClusterStatus.List(), nil
+	}
+}
+
+func (e *ListEndpoint) Method() string {
+	return ListMethod
+}
+
+func (e *ListEndpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *ListEndpoint) Name() string {
+	return ListName
+}
+
+func (e *ListEndpoint) Path() string {
+	return ListPath
+}
+
+func encodeJSON(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	return json.NewEncoder(w).Encode(response)
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
+	"github.com/giantswarm/cluster-apps-operator/v3/server/endpoint/clusters"
+	"github.com/giantswarm/cluster-apps-operator/v3/server/endpoint/readyz"
 	"github.com/giantswarm/cluster-apps-operator/v3/service"
 )
 
@@ -15,13 +17,42 @@ type Config struct {
 }
 
 type Endpoint struct {
-	Healthz *healthz.Endpoint
-	Version *version.Endpoint
+	Cluster  *clusters.GetEndpoint
+	Clusters *clusters.ListEndpoint
+	Healthz  *healthz.Endpoint
+	Readyz   *readyz.Endpoint
+	Version  *version.Endpoint
 }
 
 func New(config Config) (*Endpoint, error) {
 	var err error
 
+	var clusterEndpoint *clusters.GetEndpoint
+	{
+		c := clusters.Config{
+			Logger:  config.Logger,
+			Service: config.Service,
+		}
+
+		clusterEndpoint, err = clusters.NewGet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clustersEndpoint *clusters.ListEndpoint
+	{
+		c := clusters.Config{
+			Logger:  config.Logger,
+			Service: config.Service,
+		}
+
+		clustersEndpoint, err = clusters.NewList(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var healthzEndpoint *healthz.Endpoint
 	{
 		c := healthz.Config{
@@ -34,6 +65,19 @@ func New(config Config) (*Endpoint, error) {
 		}
 	}
 
+	var readyzEndpoint *readyz.Endpoint
+	{
+		c := readyz.Config{
+			Logger:   config.Logger,
+			Services: config.Service.Readiness,
+		}
+
+		readyzEndpoint, err = readyz.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var versionEndpoint *version.Endpoint
 	{
 		c := version.Config{
@@ -48,8 +92,11 @@ func New(config Config) (*Endpoint, error) {
 	}
 
 	e := &Endpoint{
-		Healthz: healthzEndpoint,
-		Version: versionEndpoint,
+		Cluster:  clusterEndpoint,
+		Clusters: clustersEndpoint,
+		Healthz:  healthzEndpoint,
+		Readyz:   readyzEndpoint,
+		Version:  versionEndpoint,
 	}
 
 	return e, nil
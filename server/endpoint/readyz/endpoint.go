@@ -0,0 +1,114 @@
+// Package readyz implements the readiness endpoint. Unlike the healthz
+// endpoint, which only tells whether the process is alive, it executes the
+// readiness checks of the service and fails if any of them fails.
+package readyz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "GET"
+	// Name identifies the endpoint.
+	Name = "readyz"
+	// Path is the HTTP request path this endpoint is registered for.
+	Path = "/readyz"
+)
+
+// Config represents the configuration used to create a readyz endpoint.
+type Config struct {
+	Logger   micrologger.Logger
+	Services []healthz.Service
+}
+
+type Endpoint struct {
+	logger   micrologger.Logger
+	services []healthz.Service
+}
+
+// New creates a new configured readyz endpoint.
+func New(config Config) (*Endpoint, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if len(config.Services) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Services must not be empty", config)
+	}
+
+	e := &Endpoint{
+		logger:   config.Logger,
+		services: config.Services,
+	}
+
+	return e, nil
+}
+
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		return nil, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		rs, ok := response.([]healthz.Response)
+		if !ok {
+			return microerror.Maskf(wrongTypeError, "expected '%T' got '%T'", []healthz.Response{}, response)
+		}
+		if healthz.Responses(rs).HasFailed() {
+			for _, r := range rs {
+				if r.Failed {
+					e.logger.Debugf(ctx, "readiness check %#q failed: %s", r.Name, r.Message)
+				}
+			}
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		var responses []healthz.Response
+
+		for _, s := range e.services {
+			res, err := s.GetHealthz(ctx)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			responses = append(responses, res)
+		}
+
+		return responses, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
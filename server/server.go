@@ -14,6 +14,7 @@ import (
 
 	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
 	"github.com/giantswarm/cluster-apps-operator/v3/server/endpoint"
+	"github.com/giantswarm/cluster-apps-operator/v3/server/endpoint/clusters"
 	"github.com/giantswarm/cluster-apps-operator/v3/service"
 )
 
@@ -50,7 +51,10 @@ func New(config Config) (microserver.Server, error) {
 			Viper:       config.Viper,
 
 			Endpoints: []microserver.Endpoint{
+				endpointCollection.Cluster,
+				endpointCollection.Clusters,
 				endpointCollection.Healthz,
+				endpointCollection.Readyz,
 				endpointCollection.Version,
 			},
 			ErrorEncoder: encodeError,
@@ -91,7 +95,14 @@ func encodeError(ctx context.Context, err error, w http.ResponseWriter) {
 	rErr := err.(microserver.ResponseError)
 	uErr := rErr.Underlying()
 
-	rErr.SetCode(microserver.CodeInternalError)
 	rErr.SetMessage(uErr.Error())
+
+	if clusters.IsNotFound(uErr) {
+		rErr.SetCode(microserver.CodeResourceNotFound)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	rErr.SetCode(microserver.CodeInternalError)
 	w.WriteHeader(http.StatusInternalServerError)
 }
@@ -11,8 +11,9 @@ import (
 	"github.com/giantswarm/micrologger"
 	"github.com/giantswarm/operatorkit/v7/pkg/controller"
 	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8slabels "k8s.io/apimachinery/pkg/labels"
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
@@ -36,12 +37,17 @@ var (
 type ClusterConfig struct {
 	K8sClient k8sclient.Interface
 	Logger    micrologger.Logger
+
+	// ClusterGroupVersion is the CAPI version Clusters are listed in.
+	ClusterGroupVersion schema.GroupVersion
 }
 
 type Cluster struct {
 	context   context.Context
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+
+	clusterGroupVersion schema.GroupVersion
 }
 
 func NewCluster(config ClusterConfig) (*Cluster, error) {
@@ -51,19 +57,28 @@ func NewCluster(config ClusterConfig) (*Cluster, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.ClusterGroupVersion.Empty() {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClusterGroupVersion must not be empty", config)
+	}
 
 	np := &Cluster{
 		context:   context.Background(),
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+
+		clusterGroupVersion: config.ClusterGroupVersion,
 	}
 
 	return np, nil
 }
 
 func (c *Cluster) Collect(ch chan<- prometheus.Metric) error {
-	var clusterList capi.ClusterList
+	// Only the metadata of Clusters is needed, which is the same in all CAPI
+	// versions.
+	var clusterList metav1.PartialObjectMetadataList
 	{
+		clusterList.SetGroupVersionKind(c.clusterGroupVersion.WithKind("ClusterList"))
+
 		err := c.k8sClient.CtrlClient().List(
 			c.context,
 			&clusterList,
@@ -71,6 +71,8 @@ func TestClusterCollector(t *testing.T) {
 				clusterConfig := ClusterConfig{
 					K8sClient: fakeClient,
 					Logger:    microloggertest.New(),
+
+					ClusterGroupVersion: capi.GroupVersion,
 				}
 
 				clusterCollector, err = NewCluster(clusterConfig)
@@ -177,6 +179,8 @@ func Test_getNumberOfApps(t *testing.T) {
 				clusterConfig := ClusterConfig{
 					K8sClient: fakeClient,
 					Logger:    microloggertest.New(),
+
+					ClusterGroupVersion: capi.GroupVersion,
 				}
 
 				clusterCollector, err = NewCluster(clusterConfig)
@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// workQueueDepth is the work queue depth metric controller-runtime
+	// registers for every controller, see
+	// sigs.k8s.io/controller-runtime/pkg/metrics.Registry.
+	workQueueDepth = "workqueue_depth"
+
+	labelController = "controller"
+)
+
+var (
+	queueDepth *prometheus.Desc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "controller", "queue_depth"),
+		"Number of clusters waiting in the work queue of the cluster controller.",
+		nil,
+		nil,
+	)
+)
+
+type QueueConfig struct {
+	// Gatherer gathers the work queue metrics of controller-runtime, which
+	// the operatorkit controller is built on.
+	Gatherer prometheus.Gatherer
+
+	// ControllerName is the name of the operatorkit controller.
+	ControllerName string
+}
+
+// Queue exposes the work queue depth of the cluster controller. The work
+// queue metrics of controller-runtime are registered in its own registry,
+// which is not served by the operator, and the operatorkit controller does
+// not expose its queue otherwise.
+type Queue struct {
+	gatherer prometheus.Gatherer
+
+	controllerName string
+}
+
+func NewQueue(config QueueConfig) (*Queue, error) {
+	if config.Gatherer == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Gatherer must not be empty", config)
+	}
+	if config.ControllerName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControllerName must not be empty", config)
+	}
+
+	q := &Queue{
+		gatherer: config.Gatherer,
+
+		controllerName: config.ControllerName,
+	}
+
+	return q, nil
+}
+
+func (q *Queue) Collect(ch chan<- prometheus.Metric) error {
+	families, err := q.gatherer.Gather()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The depth is reported per priority, so all series of the controller
+	// are summed up. It is 0 as long as the controller has not booted, e.g.
+	// on replicas not holding the leader lease.
+	var depth float64
+	for _, f := range families {
+		if f.GetName() != workQueueDepth {
+			continue
+		}
+
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == labelController && l.GetValue() == q.controllerName {
+					depth += m.GetGauge().GetValue()
+					break
+				}
+			}
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		queueDepth,
+		prometheus.GaugeValue,
+		depth,
+	)
+
+	return nil
+}
+
+func (q *Queue) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- queueDepth
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestQueueCollector(t *testing.T) {
+	testcases := []struct {
+		name     string
+		depths   map[[2]string]float64
+		expected float64
+	}{
+		{
+			name:     "no work queue registered yet",
+			expected: 0,
+		},
+		{
+			name: "priorities of the controller are summed up",
+			depths: map[[2]string]float64{
+				{"cluster-apps-operator-cluster-controller", ""}:  3,
+				{"cluster-apps-operator-cluster-controller", "1"}: 2,
+			},
+			expected: 5,
+		},
+		{
+			name: "other controllers are ignored",
+			depths: map[[2]string]float64{
+				{"cluster-apps-operator-cluster-controller", ""}: 3,
+				{"other-controller", ""}:                         7,
+			},
+			expected: 3,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+
+			depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Subsystem: "workqueue",
+				Name:      "depth",
+			}, []string{"name", "controller", "priority"})
+			registry.MustRegister(depth)
+
+			for k, v := range test.depths {
+				depth.WithLabelValues(k[0], k[0], k[1]).Set(v)
+			}
+
+			queueCollector, err := NewQueue(QueueConfig{
+				Gatherer: registry,
+
+				ControllerName: "cluster-apps-operator-cluster-controller",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ch := make(chan prometheus.Metric, 1)
+			err = queueCollector.Collect(ch)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var m dto.Metric
+			err = (<-ch).Write(&m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if m.GetGauge().GetValue() != test.expected {
+				t.Fatalf("Expected queue depth %v but got %v", test.expected, m.GetGauge().GetValue())
+			}
+		})
+	}
+}
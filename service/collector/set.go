@@ -5,11 +5,20 @@ import (
 	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type SetConfig struct {
 	K8sClient k8sclient.Interface
 	Logger    micrologger.Logger
+
+	ClusterGroupVersion schema.GroupVersion
+
+	// Gatherer gathers the work queue metrics of the cluster controller named
+	// ControllerName.
+	Gatherer       prometheus.Gatherer
+	ControllerName string
 }
 
 // Set is basically only a wrapper for the operator's collector implementations.
@@ -25,7 +34,12 @@ func NewSet(config SetConfig) (*Set, error) {
 
 	var clusterCollector *Cluster
 	{
-		c := ClusterConfig(config)
+		c := ClusterConfig{
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+
+			ClusterGroupVersion: config.ClusterGroupVersion,
+		}
 
 		clusterCollector, err = NewCluster(c)
 		if err != nil {
@@ -33,11 +47,26 @@ func NewSet(config SetConfig) (*Set, error) {
 		}
 	}
 
+	var queueCollector *Queue
+	{
+		c := QueueConfig{
+			Gatherer: config.Gatherer,
+
+			ControllerName: config.ControllerName,
+		}
+
+		queueCollector, err = NewQueue(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var collectorSet *collector.Set
 	{
 		c := collector.SetConfig{
 			Collectors: []collector.Interface{
 				clusterCollector,
+				queueCollector,
 			},
 			Logger: config.Logger,
 		}
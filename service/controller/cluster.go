@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"context"
 	"time"
 
+	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
@@ -15,7 +17,8 @@ import (
 	"github.com/giantswarm/operatorkit/v7/pkg/resource/wrapper/metricsresource"
 	"github.com/giantswarm/operatorkit/v7/pkg/resource/wrapper/retryresource"
 	"k8s.io/apimachinery/pkg/labels"
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
@@ -23,34 +26,86 @@ import (
 	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/app"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/clusterconfigmap"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/clustersecret"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/ownerreference"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/wrapper/ratelimitresource"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/wrapper/shardresource"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/wrapper/statusresource"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/capiversion"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/cni"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/compatibility"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/podcidr"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/sharding"
 )
 
 type ClusterConfig struct {
+	// ClusterGroupVersion is the CAPI version Clusters are reconciled in,
+	// see capiversion.Preferred.
+	ClusterGroupVersion schema.GroupVersion
+	// CNI is optional. When set, the CNI of the clusters is detected and
+	// rendered into their cluster values.
+	CNI *cni.Detector
+	// EventRecorder emits events for the reconciled Clusters.
+	EventRecorder record.EventRecorder
+	// ExtraValues is optional. When set, user supplied values are merged
+	// into the cluster values ConfigMap and Secret.
+	ExtraValues  *extravalues.ExtraValues
 	K8sClient    k8sclient.Interface
 	Logger       micrologger.Logger
 	PodCIDR      podcidr.Interface
+	Recorder     *clusterstatus.Recorder
 	ResyncPeriod time.Duration
+	// Backoff and Retry control how aggressively failing resources are
+	// retried.
+	BackoffInitialInterval time.Duration
+	BackoffMaxInterval     time.Duration
+	RetryInterval          time.Duration
+	RetryMaxRetries        uint64
+	// Sharding is optional. When set, only clusters owned by this replica
+	// are reconciled.
+	Sharding sharding.Interface
+	// ChartOperatorCompatibility is optional. When set, the chart-operator
+	// version of clusters without Release is selected by their Kubernetes
+	// version.
+	ChartOperatorCompatibility *compatibility.Matrix
 
 	AppOperatorCatalog   string
 	AppOperatorVersion   string
 	ChartOperatorCatalog string
 	ChartOperatorVersion string
 	BaseDomain           string
+	ClusterDomain        string
 	ClusterIPRange       string
 	DNSIP                string
 	ManagementClusterID  string
+	MetadataAnnotations  []string
+	MetadataLabels       []string
 	RegistryDomain       string
 	Proxy                proxy.Proxy
+	// AWSManagementAccountID is the account of CAPA clusters using the
+	// AWSClusterControllerIdentity.
+	AWSManagementAccountID string
 }
 
 type Cluster struct {
 	*controller.Controller
 }
 
+// ClusterControllerName returns the name of the operatorkit controller
+// reconciling Clusters.
+func ClusterControllerName() string {
+	return project.Name() + "-cluster-controller"
+}
+
 func NewCluster(config ClusterConfig) (*Cluster, error) {
 	var err error
 
+	if !capiversion.IsSupported(config.ClusterGroupVersion) {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClusterGroupVersion %#q is not supported", config, config.ClusterGroupVersion.String())
+	}
+
 	resources, err := newClusterResources(config)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -64,10 +119,16 @@ func NewCluster(config ClusterConfig) (*Cluster, error) {
 		}
 
 		c := controller.Config{
+			// InitCtx gives every reconciliation its own cache of the
+			// infrastructure objects, so they are fetched at most once per
+			// reconciliation and dropped with it, also when it fails.
+			InitCtx: func(ctx context.Context, obj interface{}) (context.Context, error) {
+				return infra.NewContext(ctx), nil
+			},
 			K8sClient: config.K8sClient,
 			Logger:    config.Logger,
 			NewRuntimeObjectFunc: func() client.Object {
-				return new(capi.Cluster)
+				return capiversion.NewCluster(config.ClusterGroupVersion)
 			},
 			Resources: resources,
 
@@ -75,7 +136,7 @@ func NewCluster(config ClusterConfig) (*Cluster, error) {
 
 			// Name is used to compute finalizer names. This here results in something
 			// like operatorkit.giantswarm.io/cluster-apps-operator-cluster-controller.
-			Name:     project.Name() + "-cluster-controller",
+			Name:     ClusterControllerName(),
 			Selector: selector,
 		}
 
@@ -95,11 +156,28 @@ func NewCluster(config ClusterConfig) (*Cluster, error) {
 func newClusterResources(config ClusterConfig) ([]resource.Interface, error) {
 	var err error
 
+	var resolver *infra.Resolver
+	{
+		c := infra.ResolverConfig{
+			CtrlClient: config.K8sClient.CtrlClient(),
+		}
+
+		resolver, err = infra.NewResolver(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var appResource resource.Interface
 	{
 		c := app.Config{
-			CtrlClient: config.K8sClient.CtrlClient(),
-			Logger:     config.Logger,
+			CtrlClient:    config.K8sClient.CtrlClient(),
+			EventRecorder: config.EventRecorder,
+			Logger:        config.Logger,
+			Recorder:      config.Recorder,
+			Resolver:      resolver,
+
+			ChartOperatorCompatibility: config.ChartOperatorCompatibility,
 
 			AppOperatorCatalog:   config.AppOperatorCatalog,
 			AppOperatorVersion:   config.AppOperatorVersion,
@@ -116,16 +194,25 @@ func newClusterResources(config ClusterConfig) ([]resource.Interface, error) {
 	var clusterConfigMapGetter configmapresource.StateGetter
 	{
 		c := clusterconfigmap.Config{
-			BaseDomain: config.BaseDomain,
-			K8sClient:  config.K8sClient,
-			Logger:     config.Logger,
-			PodCIDR:    config.PodCIDR,
-
+			BaseDomain:  config.BaseDomain,
+			CNI:         config.CNI,
+			ExtraValues: config.ExtraValues,
+			K8sClient:   config.K8sClient,
+			Logger:      config.Logger,
+			PodCIDR:     config.PodCIDR,
+			Recorder:    config.Recorder,
+			Resolver:    resolver,
+
+			ClusterDomain:       config.ClusterDomain,
 			ClusterIPRange:      config.ClusterIPRange,
 			DNSIP:               config.DNSIP,
 			ManagementClusterID: config.ManagementClusterID,
+			MetadataAnnotations: config.MetadataAnnotations,
+			MetadataLabels:      config.MetadataLabels,
 			RegistryDomain:      config.RegistryDomain,
 			Proxy:               config.Proxy,
+
+			AWSManagementAccountID: config.AWSManagementAccountID,
 		}
 
 		clusterConfigMapGetter, err = clusterconfigmap.New(c)
@@ -161,9 +248,12 @@ func newClusterResources(config ClusterConfig) ([]resource.Interface, error) {
 	var clusterSecretGetter secretresource.StateGetter
 	{
 		c := clustersecret.Config{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
-			Proxy:     config.Proxy,
+			ExtraValues: config.ExtraValues,
+			K8sClient:   config.K8sClient,
+			Logger:      config.Logger,
+			Proxy:       config.Proxy,
+			Recorder:    config.Recorder,
+			Resolver:    resolver,
 		}
 
 		clusterSecretGetter, err = clustersecret.New(c)
@@ -196,16 +286,33 @@ func newClusterResources(config ClusterConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	var ownerReferenceResource resource.Interface
+	{
+		c := ownerreference.Config{
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+		}
+
+		ownerReferenceResource, err = ownerreference.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	resources := []resource.Interface{
 		// clusterConfigMapResource is executed before the app resource so the
 		// app CRs are accepted by the validation webhook.
 		clusterConfigMapResource,
 		clusterSecretResource,
+		ownerReferenceResource,
 		appResource,
 	}
 
 	{
 		c := retryresource.WrapConfig{
+			BackOffFactory: func() backoff.Interface {
+				return backoff.NewMaxRetries(config.RetryMaxRetries, config.RetryInterval)
+			},
 			Logger: config.Logger,
 		}
 
@@ -215,6 +322,17 @@ func newClusterResources(config ClusterConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	{
+		c := statusresource.WrapConfig{
+			Recorder: config.Recorder,
+		}
+
+		resources, err = statusresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	{
 		c := metricsresource.WrapConfig{}
 
@@ -224,6 +342,32 @@ func newClusterResources(config ClusterConfig) ([]resource.Interface, error) {
 		}
 	}
 
+	{
+		c := ratelimitresource.WrapConfig{
+			Logger: config.Logger,
+
+			BackoffInitialInterval: config.BackoffInitialInterval,
+			BackoffMaxInterval:     config.BackoffMaxInterval,
+		}
+
+		resources, err = ratelimitresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	if config.Sharding != nil {
+		c := shardresource.WrapConfig{
+			Logger:   config.Logger,
+			Sharding: config.Sharding,
+		}
+
+		resources, err = shardresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	return resources, nil
 }
 
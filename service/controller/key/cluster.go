@@ -0,0 +1,339 @@
+package key
+
+import (
+	"encoding/json"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Cluster is a CAPI Cluster served as either v1beta1 or v1beta2. Its spec
+// must only be read using the version-neutral accessors of this package.
+type Cluster interface {
+	client.Object
+}
+
+// ClusterNetwork is the version-neutral network configuration of a Cluster.
+type ClusterNetwork struct {
+	// APIServerPort is spec.clusterNetwork.apiServerPort, the port kubeadm
+	// binds the API server pods to. It is 0 when not set.
+	APIServerPort int32
+	Pods          []string
+	ServiceDomain string
+	Services      []string
+}
+
+// ObjectReference is a version-neutral reference from a Cluster to its
+// infrastructure or control plane object. v1beta2 Clusters reference objects
+// by API group only, so APIVersion is empty for them. Referenced objects
+// always live in the namespace of the Cluster and are fetched by GroupKind
+// with the infrastructure Resolver.
+type ObjectReference struct {
+	APIGroup   string
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+}
+
+func (r ObjectReference) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: r.APIGroup, Kind: r.Kind}
+}
+
+// GroupVersionKind returns the kind of the referenced object with the version
+// pinned by v1beta1 references. The version is empty for v1beta2 references,
+// which only carry the API group.
+func (r ObjectReference) GroupVersionKind() schema.GroupVersionKind {
+	gv, _ := schema.ParseGroupVersion(r.APIVersion)
+
+	return schema.GroupVersionKind{Group: r.APIGroup, Version: gv.Version, Kind: r.Kind}
+}
+
+// ClusterNetworkOf returns the network configuration of the Cluster.
+func ClusterNetworkOf(cr Cluster) ClusterNetwork {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		n := c.Spec.ClusterNetwork
+		if n == nil {
+			return ClusterNetwork{}
+		}
+
+		network := ClusterNetwork{
+			ServiceDomain: n.ServiceDomain,
+		}
+		if n.APIServerPort != nil {
+			network.APIServerPort = *n.APIServerPort
+		}
+		if n.Pods != nil {
+			network.Pods = n.Pods.CIDRBlocks
+		}
+		if n.Services != nil {
+			network.Services = n.Services.CIDRBlocks
+		}
+
+		return network
+	case *capi.Cluster:
+		n := c.Spec.ClusterNetwork
+		return ClusterNetwork{
+			APIServerPort: n.APIServerPort,
+			Pods:          n.Pods.CIDRBlocks,
+			ServiceDomain: n.ServiceDomain,
+			Services:      n.Services.CIDRBlocks,
+		}
+	}
+
+	return ClusterNetwork{}
+}
+
+// ClusterCondition returns the condition of the given type of the Cluster, or
+// nil if it is not set. Conditions of v1beta1 Clusters are read from their
+// v1beta2 status.
+func ClusterCondition(cr Cluster, conditionType string) *metav1.Condition {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		if c.Status.V1Beta2 == nil {
+			return nil
+		}
+		return meta.FindStatusCondition(c.Status.V1Beta2.Conditions, conditionType)
+	case *capi.Cluster:
+		return meta.FindStatusCondition(c.Status.Conditions, conditionType)
+	}
+
+	return nil
+}
+
+// RemoveClusterCondition removes the condition of the given type from the
+// Cluster and returns whether it was set.
+func RemoveClusterCondition(cr Cluster, conditionType string) bool {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		if c.Status.V1Beta2 == nil {
+			return false
+		}
+		return meta.RemoveStatusCondition(&c.Status.V1Beta2.Conditions, conditionType)
+	case *capi.Cluster:
+		return meta.RemoveStatusCondition(&c.Status.Conditions, conditionType)
+	}
+
+	return false
+}
+
+// SetClusterCondition sets the condition on the Cluster and returns whether
+// it changed. Conditions of v1beta1 Clusters are written to their v1beta2
+// status, which holds metav1.Conditions like v1beta2 Clusters do.
+func SetClusterCondition(cr Cluster, condition metav1.Condition) bool {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		if c.Status.V1Beta2 == nil {
+			c.Status.V1Beta2 = &capiv1beta1.ClusterV1Beta2Status{}
+		}
+		condition.ObservedGeneration = c.Generation
+		return meta.SetStatusCondition(&c.Status.V1Beta2.Conditions, condition)
+	case *capi.Cluster:
+		condition.ObservedGeneration = c.Generation
+		return meta.SetStatusCondition(&c.Status.Conditions, condition)
+	}
+
+	return false
+}
+
+// ClusterOwnerReferences returns the owner references of objects generated
+// for the Cluster, so they are garbage collected once the Cluster is gone.
+// They are not controller references, since an object can only have one and
+// existing objects may already have one of another controller.
+func ClusterOwnerReferences(cr Cluster) []metav1.OwnerReference {
+	gv := capi.GroupVersion
+	if _, ok := cr.(*capiv1beta1.Cluster); ok {
+		gv = capiv1beta1.GroupVersion
+	}
+
+	return []metav1.OwnerReference{
+		{
+			APIVersion: gv.String(),
+			Kind:       "Cluster",
+			Name:       cr.GetName(),
+			UID:        cr.GetUID(),
+		},
+	}
+}
+
+// HasClusterOwnerReferences returns true if the given object already carries
+// the owner references of ClusterOwnerReferences.
+func HasClusterOwnerReferences(cr Cluster, obj metav1.Object) bool {
+	for _, desired := range ClusterOwnerReferences(cr) {
+		var found bool
+		for _, current := range obj.GetOwnerReferences() {
+			if current.UID == desired.UID && current.APIVersion == desired.APIVersion {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ClusterOwnerReferencesPatch returns a strategic merge patch adding the owner
+// references of ClusterOwnerReferences to an object. Other owner references
+// of the object are kept.
+func ClusterOwnerReferencesPatch(cr Cluster) ([]byte, error) {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"ownerReferences": ClusterOwnerReferences(cr),
+		},
+	}
+
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return b, nil
+}
+
+// ControlPlaneEndpointHost returns the host of the control plane endpoint of
+// the Cluster, or an empty string if it is not set yet.
+func ControlPlaneEndpointHost(cr Cluster) string {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		return c.Spec.ControlPlaneEndpoint.Host
+	case *capi.Cluster:
+		return c.Spec.ControlPlaneEndpoint.Host
+	}
+
+	return ""
+}
+
+// ControlPlaneEndpointPort returns the port of the control plane endpoint of
+// the Cluster, or 0 if it is not set yet.
+func ControlPlaneEndpointPort(cr Cluster) int32 {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		return c.Spec.ControlPlaneEndpoint.Port
+	case *capi.Cluster:
+		return c.Spec.ControlPlaneEndpoint.Port
+	}
+
+	return 0
+}
+
+// ControlPlaneRef returns the reference to the control plane object of the
+// Cluster, or nil if it is not set.
+func ControlPlaneRef(cr Cluster) *ObjectReference {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		return fromV1Beta1Reference(c.Spec.ControlPlaneRef, c.Namespace)
+	case *capi.Cluster:
+		return fromContractVersionedReference(c.Spec.ControlPlaneRef, c.Namespace)
+	}
+
+	return nil
+}
+
+// InfrastructureRef returns the reference to the infrastructure cluster
+// object of the Cluster, or nil if it is not set.
+func InfrastructureRef(cr Cluster) *ObjectReference {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		return fromV1Beta1Reference(c.Spec.InfrastructureRef, c.Namespace)
+	case *capi.Cluster:
+		return fromContractVersionedReference(c.Spec.InfrastructureRef, c.Namespace)
+	}
+
+	return nil
+}
+
+// TopologyVersion returns the Kubernetes version of the topology of a
+// ClusterClass based Cluster, or an empty string if it is not set.
+func TopologyVersion(cr Cluster) string {
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		if c.Spec.Topology == nil {
+			return ""
+		}
+		return c.Spec.Topology.Version
+	case *capi.Cluster:
+		return c.Spec.Topology.Version
+	}
+
+	return ""
+}
+
+// TopologyVariables returns the raw JSON values of the topology variables of
+// a ClusterClass based Cluster by name. Variables scoped to a machine
+// deployment or pool are not included.
+func TopologyVariables(cr Cluster) map[string][]byte {
+	variables := map[string][]byte{}
+
+	switch c := cr.(type) {
+	case *capiv1beta1.Cluster:
+		if c.Spec.Topology == nil {
+			return variables
+		}
+		for _, v := range c.Spec.Topology.Variables {
+			variables[v.Name] = v.Value.Raw
+		}
+	case *capi.Cluster:
+		for _, v := range c.Spec.Topology.Variables {
+			variables[v.Name] = v.Value.Raw
+		}
+	}
+
+	return variables
+}
+
+// ToCluster accepts v1beta1 and v1beta2 Clusters.
+func ToCluster(v interface{}) (Cluster, error) {
+	switch c := v.(type) {
+	case *capiv1beta1.Cluster:
+		if c != nil {
+			return c, nil
+		}
+	case *capi.Cluster:
+		if c != nil {
+			return c, nil
+		}
+	}
+
+	return nil, microerror.Maskf(wrongTypeError, "expected '%T' or '%T', got '%T'", &capiv1beta1.Cluster{}, &capi.Cluster{}, v)
+}
+
+func fromContractVersionedReference(ref capi.ContractVersionedObjectReference, namespace string) *ObjectReference {
+	if !ref.IsDefined() {
+		return nil
+	}
+
+	return &ObjectReference{
+		APIGroup:  ref.APIGroup,
+		Kind:      ref.Kind,
+		Name:      ref.Name,
+		Namespace: namespace,
+	}
+}
+
+func fromV1Beta1Reference(ref *corev1.ObjectReference, namespace string) *ObjectReference {
+	if ref == nil {
+		return nil
+	}
+
+	gv, _ := schema.ParseGroupVersion(ref.APIVersion)
+
+	// The namespace of v1beta1 references is deprecated and always matches
+	// the namespace of the Cluster.
+	return &ObjectReference{
+		APIGroup:   gv.Group,
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Name:       ref.Name,
+		Namespace:  namespace,
+	}
+}
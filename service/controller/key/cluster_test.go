@@ -0,0 +1,174 @@
+package key
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+)
+
+func Test_ClusterAccessors(t *testing.T) {
+	apiServerPort := int32(6443)
+
+	testCases := []struct {
+		name                      string
+		cluster                   Cluster
+		expectedControlPlaneRef   *ObjectReference
+		expectedInfrastructureRef *ObjectReference
+		expectedNetwork           ClusterNetwork
+		expectedEndpointHost      string
+		expectedTopologyVersion   string
+		expectedOwnerAPIVersion   string
+	}{
+		{
+			name: "case 0: v1beta1 Cluster",
+			cluster: &capiv1beta1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "abc12", Namespace: "org-acme"},
+				Spec: capiv1beta1.ClusterSpec{
+					ClusterNetwork: &capiv1beta1.ClusterNetwork{
+						APIServerPort: &apiServerPort,
+						Pods:          &capiv1beta1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+						ServiceDomain: "cluster.local",
+						Services:      &capiv1beta1.NetworkRanges{CIDRBlocks: []string{"172.31.0.0/16"}},
+					},
+					ControlPlaneEndpoint: capiv1beta1.APIEndpoint{Host: "api.abc12.example.com", Port: 443},
+					Topology:             &capiv1beta1.Topology{Version: "v1.30.4"},
+					ControlPlaneRef: &corev1.ObjectReference{
+						APIVersion: "controlplane.cluster.x-k8s.io/v1beta1",
+						Kind:       "KubeadmControlPlane",
+						Name:       "abc12",
+						Namespace:  "org-acme",
+					},
+					InfrastructureRef: &corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2",
+						Kind:       "AWSCluster",
+						Name:       "abc12",
+						Namespace:  "org-acme",
+					},
+				},
+			},
+			expectedControlPlaneRef: &ObjectReference{
+				APIGroup:   "controlplane.cluster.x-k8s.io",
+				APIVersion: "controlplane.cluster.x-k8s.io/v1beta1",
+				Kind:       "KubeadmControlPlane",
+				Name:       "abc12",
+				Namespace:  "org-acme",
+			},
+			expectedInfrastructureRef: &ObjectReference{
+				APIGroup:   "infrastructure.cluster.x-k8s.io",
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2",
+				Kind:       "AWSCluster",
+				Name:       "abc12",
+				Namespace:  "org-acme",
+			},
+			expectedNetwork: ClusterNetwork{
+				APIServerPort: 6443,
+				Pods:          []string{"192.168.0.0/16"},
+				ServiceDomain: "cluster.local",
+				Services:      []string{"172.31.0.0/16"},
+			},
+			expectedEndpointHost:    "api.abc12.example.com",
+			expectedTopologyVersion: "v1.30.4",
+			expectedOwnerAPIVersion: "cluster.x-k8s.io/v1beta1",
+		},
+		{
+			name: "case 1: v1beta2 Cluster",
+			cluster: &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "abc12", Namespace: "org-acme"},
+				Spec: capi.ClusterSpec{
+					ClusterNetwork: capi.ClusterNetwork{
+						APIServerPort: 6443,
+						Pods:          capi.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+						ServiceDomain: "cluster.local",
+						Services:      capi.NetworkRanges{CIDRBlocks: []string{"172.31.0.0/16"}},
+					},
+					ControlPlaneEndpoint: capi.APIEndpoint{Host: "api.abc12.example.com", Port: 6443},
+					Topology:             capi.Topology{Version: "v1.31.1"},
+					ControlPlaneRef: capi.ContractVersionedObjectReference{
+						APIGroup: "controlplane.cluster.x-k8s.io",
+						Kind:     "KubeadmControlPlane",
+						Name:     "abc12",
+					},
+					InfrastructureRef: capi.ContractVersionedObjectReference{
+						APIGroup: "infrastructure.cluster.x-k8s.io",
+						Kind:     "AWSCluster",
+						Name:     "abc12",
+					},
+				},
+			},
+			expectedControlPlaneRef: &ObjectReference{
+				APIGroup:  "controlplane.cluster.x-k8s.io",
+				Kind:      "KubeadmControlPlane",
+				Name:      "abc12",
+				Namespace: "org-acme",
+			},
+			expectedInfrastructureRef: &ObjectReference{
+				APIGroup:  "infrastructure.cluster.x-k8s.io",
+				Kind:      "AWSCluster",
+				Name:      "abc12",
+				Namespace: "org-acme",
+			},
+			expectedNetwork: ClusterNetwork{
+				APIServerPort: 6443,
+				Pods:          []string{"192.168.0.0/16"},
+				ServiceDomain: "cluster.local",
+				Services:      []string{"172.31.0.0/16"},
+			},
+			expectedEndpointHost:    "api.abc12.example.com",
+			expectedTopologyVersion: "v1.31.1",
+			expectedOwnerAPIVersion: "cluster.x-k8s.io/v1beta2",
+		},
+		{
+			name:                    "case 2: v1beta1 Cluster without spec",
+			cluster:                 &capiv1beta1.Cluster{},
+			expectedOwnerAPIVersion: "cluster.x-k8s.io/v1beta1",
+		},
+		{
+			name:                    "case 3: v1beta2 Cluster without spec",
+			cluster:                 &capi.Cluster{},
+			expectedOwnerAPIVersion: "cluster.x-k8s.io/v1beta2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if ref := ControlPlaneRef(tc.cluster); !reflect.DeepEqual(ref, tc.expectedControlPlaneRef) {
+				t.Fatalf("expected control plane ref %#v, got %#v", tc.expectedControlPlaneRef, ref)
+			}
+
+			ref := InfrastructureRef(tc.cluster)
+			if !reflect.DeepEqual(ref, tc.expectedInfrastructureRef) {
+				t.Fatalf("expected infrastructure ref %#v, got %#v", tc.expectedInfrastructureRef, ref)
+			}
+
+			if network := ClusterNetworkOf(tc.cluster); !reflect.DeepEqual(network, tc.expectedNetwork) {
+				t.Fatalf("expected network %#v, got %#v", tc.expectedNetwork, network)
+			}
+
+			if host := ControlPlaneEndpointHost(tc.cluster); host != tc.expectedEndpointHost {
+				t.Fatalf("expected endpoint host %#v, got %#v", tc.expectedEndpointHost, host)
+			}
+
+			if v := TopologyVersion(tc.cluster); v != tc.expectedTopologyVersion {
+				t.Fatalf("expected topology version %#v, got %#v", tc.expectedTopologyVersion, v)
+			}
+
+			refs := ClusterOwnerReferences(tc.cluster)
+			if len(refs) != 1 || refs[0].APIVersion != tc.expectedOwnerAPIVersion || refs[0].Kind != "Cluster" || refs[0].Name != tc.cluster.GetName() {
+				t.Fatalf("expected owner reference to %s Cluster %#q, got %#v", tc.expectedOwnerAPIVersion, tc.cluster.GetName(), refs)
+			}
+			if refs[0].Controller != nil {
+				t.Fatalf("expected owner reference not to be a controller reference")
+			}
+			if !HasClusterOwnerReferences(tc.cluster, &metav1.ObjectMeta{OwnerReferences: refs}) {
+				t.Fatalf("expected owner references %#v to be found", refs)
+			}
+			if HasClusterOwnerReferences(tc.cluster, &metav1.ObjectMeta{}) {
+				t.Fatalf("expected missing owner references not to be found")
+			}
+		})
+	}
+}
@@ -7,11 +7,10 @@ import (
 
 	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
 
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
-
-	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
 )
 
 const (
@@ -21,6 +20,8 @@ const (
 
 	fluxLabelKustomizationName      = "kustomize.toolkit.fluxcd.io/name"
 	fluxLabelKustomizationNamespace = "kustomize.toolkit.fluxcd.io/namespace"
+
+	organizationNamespacePrefix = "org-"
 )
 
 func AppOperatorAppName(getter LabelsGetter) string {
@@ -47,6 +48,10 @@ func ClusterCAName(getter LabelsGetter) string {
 	return fmt.Sprintf("%s-ca", ClusterID(getter))
 }
 
+func ClusterDescription(getter AnnotationsGetter) string {
+	return getter.GetAnnotations()[annotation.ClusterDescription]
+}
+
 func ClusterID(getter LabelsGetter) string {
 	clusterID := getter.GetLabels()[label.Cluster]
 	// If the Giant Swarm cluster name is empty, attempt to retrieve it from the
@@ -84,28 +89,6 @@ func IsBundle(appName string) bool {
 	return strings.HasSuffix(appName, "-bundle")
 }
 
-// IsEKS check if the cluster is EKS cluster
-func IsEKS(cluster capi.Cluster) bool {
-	return cluster.Spec.ControlPlaneRef != nil &&
-		cluster.Spec.ControlPlaneRef.Kind == "AWSManagedControlPlane" &&
-		cluster.Spec.InfrastructureRef != nil &&
-		cluster.Spec.InfrastructureRef.Kind == "AWSManagedCluster"
-}
-
-// IsKamaji check if the control plane is backed by kamaji
-func IsKamaji(cluster capi.Cluster) bool {
-	return cluster.Spec.ControlPlaneRef != nil &&
-		cluster.Spec.ControlPlaneRef.Kind == "KamajiControlPlane"
-}
-
-// IsAKS checks if the cluster uses the ASO-based Azure provider
-func IsAKS(cluster capi.Cluster) bool {
-	return cluster.Spec.ControlPlaneRef != nil &&
-		cluster.Spec.ControlPlaneRef.Kind == infra.AzureASOManagedControlPlaneKind &&
-		cluster.Spec.InfrastructureRef != nil &&
-		cluster.Spec.InfrastructureRef.Kind == infra.AzureASOManagedClusterKind
-}
-
 func IsDeleted(getter DeletionTimestampGetter) bool {
 	return getter.GetDeletionTimestamp() != nil
 }
@@ -114,47 +97,54 @@ func KubeConfigSecretName(getter LabelsGetter) string {
 	return fmt.Sprintf("%s-kubeconfig", ClusterID(getter))
 }
 
-func PodCIDR(cr capi.Cluster) string {
-	if cr.Spec.ClusterNetwork == nil {
-		return ""
+// Organization returns the organization owning the cluster. It is taken from
+// the organization label and falls back to the name of the org-* namespace
+// the cluster lives in.
+func Organization(getter ObjectGetter) string {
+	if organization := getter.GetLabels()[label.Organization]; organization != "" {
+		return organization
 	}
-	if cr.Spec.ClusterNetwork.Pods == nil {
-		return ""
-	}
-	if len(cr.Spec.ClusterNetwork.Pods.CIDRBlocks) == 0 {
-		return ""
+
+	if strings.HasPrefix(getter.GetNamespace(), organizationNamespacePrefix) {
+		return strings.TrimPrefix(getter.GetNamespace(), organizationNamespacePrefix)
 	}
 
-	return cr.Spec.ClusterNetwork.Pods.CIDRBlocks[0]
+	return ""
 }
 
-// ServiceCIDR returns the services CIDR configured on the Cluster CR, or an
-// empty string if it is not set.
-func ServiceCIDR(cr capi.Cluster) string {
-	if cr.Spec.ClusterNetwork == nil {
-		return ""
-	}
-	if cr.Spec.ClusterNetwork.Services == nil {
-		return ""
-	}
-	if len(cr.Spec.ClusterNetwork.Services.CIDRBlocks) == 0 {
+func PodCIDR(cr Cluster) string {
+	pods := ClusterNetworkOf(cr).Pods
+	if len(pods) == 0 {
 		return ""
 	}
 
-	return cr.Spec.ClusterNetwork.Services.CIDRBlocks[0]
+	return pods[0]
 }
 
-func ToCluster(v interface{}) (capi.Cluster, error) {
-	if v == nil {
-		return capi.Cluster{}, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &capi.Cluster{}, v)
+// ClusterDomain returns the service domain configured on the Cluster CR, or
+// the given default if it is not set.
+func ClusterDomain(cr Cluster, defaultDomain string) string {
+	domain := ClusterNetworkOf(cr).ServiceDomain
+	if domain == "" {
+		return defaultDomain
 	}
 
-	p, ok := v.(*capi.Cluster)
-	if !ok {
-		return capi.Cluster{}, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &capi.Cluster{}, v)
+	return domain
+}
+
+func ReleaseVersion(getter LabelsGetter) string {
+	return getter.GetLabels()[label.ReleaseVersion]
+}
+
+// ServiceCIDR returns the services CIDR configured on the Cluster CR, or an
+// empty string if it is not set.
+func ServiceCIDR(cr Cluster) string {
+	services := ClusterNetworkOf(cr).Services
+	if len(services) == 0 {
+		return ""
 	}
 
-	return *p, nil
+	return services[0]
 }
 
 // IsManagedByFlux checks if App is considered to be managed by Flux.
@@ -10,6 +10,7 @@ import (
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capiv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 )
 
 // A mock object that implements LabelsGetter interface
@@ -48,35 +49,110 @@ func Test_ClusterID(t *testing.T) {
 	}
 }
 
+func Test_ClusterDomain(t *testing.T) {
+	testCases := []struct {
+		description    string
+		cluster        Cluster
+		expectedDomain string
+	}{
+		{
+			description:    "service domain of v1beta1 cluster takes precedence",
+			cluster:        &capi.Cluster{Spec: capi.ClusterSpec{ClusterNetwork: &capi.ClusterNetwork{ServiceDomain: "eggs2.internal"}}},
+			expectedDomain: "eggs2.internal",
+		},
+		{
+			description:    "service domain of v1beta2 cluster takes precedence",
+			cluster:        &capiv1beta2.Cluster{Spec: capiv1beta2.ClusterSpec{ClusterNetwork: capiv1beta2.ClusterNetwork{ServiceDomain: "eggs2.internal"}}},
+			expectedDomain: "eggs2.internal",
+		},
+		{
+			description:    "default used without cluster network",
+			cluster:        &capi.Cluster{},
+			expectedDomain: "cluster.local",
+		},
+		{
+			description:    "default used without service domain",
+			cluster:        &capiv1beta2.Cluster{},
+			expectedDomain: "cluster.local",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if domain := ClusterDomain(tc.cluster, "cluster.local"); domain != tc.expectedDomain {
+				t.Fatalf("ClusterDomain %s doesn't match. expected: %s", domain, tc.expectedDomain)
+			}
+		})
+	}
+}
+
+func Test_Organization(t *testing.T) {
+	testCases := []struct {
+		description          string
+		customObject         ObjectGetter
+		expectedOrganization string
+	}{
+		{
+			description:          "organization label takes precedence",
+			customObject:         &capi.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Labels: map[string]string{label.Organization: "giantswarm"}}},
+			expectedOrganization: "giantswarm",
+		},
+		{
+			description:          "organization derived from org namespace",
+			customObject:         &capi.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme"}},
+			expectedOrganization: "acme",
+		},
+		{
+			description:          "no organization outside of org namespaces",
+			customObject:         &capi.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}},
+			expectedOrganization: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if o := Organization(tc.customObject); o != tc.expectedOrganization {
+				t.Fatalf("Organization %s doesn't match. expected: %s", o, tc.expectedOrganization)
+			}
+		})
+	}
+}
+
 func Test_ToCluster(t *testing.T) {
 	testCases := []struct {
 		description          string
 		inputObject          interface{}
-		expectedCustomObject capi.Cluster
+		expectedCustomObject Cluster
 		expectedError        error
 	}{
 		{
-			description:          "reference to empty value Cluster returns empty Cluster",
+			description:          "reference to empty value v1beta1 Cluster returns empty Cluster",
 			inputObject:          &capi.Cluster{},
-			expectedCustomObject: capi.Cluster{},
+			expectedCustomObject: &capi.Cluster{},
+			expectedError:        nil,
+		},
+		{
+			description:          "reference to empty value v1beta2 Cluster returns empty Cluster",
+			inputObject:          &capiv1beta2.Cluster{},
+			expectedCustomObject: &capiv1beta2.Cluster{},
 			expectedError:        nil,
 		},
 		{
 			description:          "non-pointer value of Cluster must return wrongTypeError",
 			inputObject:          capi.Cluster{},
-			expectedCustomObject: capi.Cluster{},
+			expectedCustomObject: nil,
 			expectedError:        wrongTypeError,
 		},
 		{
 			description:          "wrong type must return wrongTypeError",
 			inputObject:          &capi.Machine{},
-			expectedCustomObject: capi.Cluster{},
+			expectedCustomObject: nil,
 			expectedError:        wrongTypeError,
 		},
 		{
 			description:          "nil interface{} must return wrongTypeError",
 			inputObject:          nil,
-			expectedCustomObject: capi.Cluster{},
+			expectedCustomObject: nil,
 			expectedError:        wrongTypeError,
 		},
 	}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+const (
+	// AppVersionResolvedCondition is set on every Cluster. It is false when
+	// the catalog or version of one of its App CRs cannot be resolved, in
+	// which case existing App CRs keep their current catalog and version.
+	AppVersionResolvedCondition = "AppVersionResolved"
+
+	AppVersionFoundReason = "AppVersionFound"
+	// AppVersionNotFoundReason is also the reason of the warning event
+	// emitted for the Cluster when the version of one of its App CRs has no
+	// AppCatalogEntry.
+	AppVersionNotFoundReason = "AppVersionNotFound"
+)
+
+// unresolvableVersion returns why the catalog or version of the App CR cannot
+// be resolved, or an empty string if they can. Catalogs and AppCatalogEntries
+// are managed by app-operator, so the respective check is skipped when their
+// CRD is not served.
+func (r *Resource) unresolvableVersion(ctx context.Context, app *v1alpha1.App) (string, error) {
+	exists, err := r.catalogExists(ctx, app)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	if !exists {
+		return fmt.Sprintf("App %#q catalog %#q does not exist", app.Spec.Name, app.Spec.Catalog), nil
+	}
+
+	exists, err = r.appVersionExists(ctx, app)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	if !exists {
+		return fmt.Sprintf("App %#q version %#q has no AppCatalogEntry in catalog %#q", app.Spec.Name, app.Spec.Version, app.Spec.Catalog), nil
+	}
+
+	return "", nil
+}
+
+// catalogExists returns whether the Catalog of the App CR exists. The App CRs
+// of the operator do not set a catalog namespace, so Catalogs are matched by
+// name in all namespaces. There are only a few of them and they are only
+// looked up when the version of an App CR changes.
+func (r *Resource) catalogExists(ctx context.Context, app *v1alpha1.App) (bool, error) {
+	var catalogs v1alpha1.CatalogList
+	{
+		err := r.ctrlClient.List(ctx, &catalogs)
+		if meta.IsNoMatchError(err) {
+			r.logger.Debugf(ctx, "catalogs not served, skipping catalog validation of app '%s/%s'", app.Namespace, app.Name)
+			return true, nil
+		} else if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	for _, catalog := range catalogs.Items {
+		if catalog.Name != app.Spec.Catalog {
+			continue
+		}
+		if app.Spec.CatalogNamespace == "" || catalog.Namespace == app.Spec.CatalogNamespace {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// appVersionExists returns whether the catalog has an AppCatalogEntry for the
+// app in the given version.
+func (r *Resource) appVersionExists(ctx context.Context, app *v1alpha1.App) (bool, error) {
+	var entries v1alpha1.AppCatalogEntryList
+	{
+		err := r.ctrlClient.List(ctx, &entries, client.MatchingLabels{
+			label.AppKubernetesName: app.Spec.Name,
+			label.CatalogName:       app.Spec.Catalog,
+		})
+		if meta.IsNoMatchError(err) {
+			r.logger.Debugf(ctx, "appcatalogentries not served, skipping version validation of app '%s/%s'", app.Namespace, app.Name)
+			return true, nil
+		} else if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
+
+	for _, entry := range entries.Items {
+		if entry.Spec.AppName != app.Spec.Name || entry.Spec.Catalog.Name != app.Spec.Catalog {
+			continue
+		}
+		if normalizeVersion(entry.Spec.Version) == normalizeVersion(app.Spec.Version) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// reportUnresolvableVersion emits a warning event for the Cluster and
+// increments the unresolvable version metric for the given App CR.
+func (r *Resource) reportUnresolvableVersion(ctx context.Context, cr key.Cluster, app *v1alpha1.App, message string) {
+	r.logger.Debugf(ctx, "unresolvable version of app '%s/%s': %s", app.Namespace, app.Name, message)
+	r.eventRecorder.Event(cr, corev1.EventTypeWarning, AppVersionNotFoundReason, message)
+
+	unresolvableVersionCounter.WithLabelValues(app.Spec.Name, app.Spec.Catalog).Inc()
+}
+
+// appVersionCondition returns the AppVersionResolvedCondition for the
+// messages of the unresolvable App CRs of the cluster.
+func appVersionCondition(unresolvable []string) *metav1.Condition {
+	if len(unresolvable) == 0 {
+		return &metav1.Condition{
+			Type:    AppVersionResolvedCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  AppVersionFoundReason,
+			Message: "All app versions exist in their catalogs",
+		}
+	}
+
+	return &metav1.Condition{
+		Type:    AppVersionResolvedCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  AppVersionNotFoundReason,
+		Message: strings.Join(unresolvable, "; "),
+	}
+}
+
+// isVersionChanged returns whether the desired App CR requests a different
+// catalog or version than the current one, or is not created yet.
+func isVersionChanged(current, desired *v1alpha1.App) bool {
+	if current == nil {
+		return true
+	}
+
+	return current.Spec.Catalog != desired.Spec.Catalog || normalizeVersion(current.Spec.Version) != normalizeVersion(desired.Spec.Version)
+}
+
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
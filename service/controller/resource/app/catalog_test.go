@@ -0,0 +1,298 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+func Test_EnsureCreated_VersionValidation(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		catalogs             []string
+		entries              []*v1alpha1.AppCatalogEntry
+		currentAppOperator   *v1alpha1.App
+		currentChartOperator *v1alpha1.App
+		expectedVersion      string
+		// expectedAppOperatorVersion defaults to the configured app-operator
+		// version.
+		expectedAppOperatorVersion string
+		expectedEvent              bool
+	}{
+		{
+			name:     "case 0: app created when version exists",
+			catalogs: []string{"control-plane-catalog", "default"},
+			entries: []*v1alpha1.AppCatalogEntry{
+				newAppCatalogEntry("app-operator", "control-plane-catalog", "7.0.0"),
+				newAppCatalogEntry("chart-operator", "default", "4.0.0"),
+			},
+			expectedVersion: "4.0.0",
+		},
+		{
+			name:     "case 1: app created with warning when version does not exist",
+			catalogs: []string{"control-plane-catalog", "default"},
+			entries: []*v1alpha1.AppCatalogEntry{
+				newAppCatalogEntry("app-operator", "control-plane-catalog", "7.0.0"),
+				newAppCatalogEntry("chart-operator", "default", "3.0.0"),
+			},
+			expectedVersion: "4.0.0",
+			expectedEvent:   true,
+		},
+		{
+			name:     "case 2: existing app not patched to version which does not exist",
+			catalogs: []string{"control-plane-catalog", "default"},
+			entries: []*v1alpha1.AppCatalogEntry{
+				newAppCatalogEntry("app-operator", "control-plane-catalog", "7.0.0"),
+				newAppCatalogEntry("chart-operator", "default", "3.0.0"),
+			},
+			currentChartOperator: newChartOperatorApp("3.0.0"),
+			expectedVersion:      "3.0.0",
+			expectedEvent:        true,
+		},
+		{
+			name:     "case 3: existing app patched to version which exists",
+			catalogs: []string{"control-plane-catalog", "default"},
+			entries: []*v1alpha1.AppCatalogEntry{
+				newAppCatalogEntry("app-operator", "control-plane-catalog", "7.0.0"),
+				newAppCatalogEntry("chart-operator", "default", "v4.0.0"),
+			},
+			currentChartOperator: newChartOperatorApp("3.0.0"),
+			expectedVersion:      "4.0.0",
+		},
+		{
+			name:     "case 4: entries without app and catalog labels are not matched",
+			catalogs: []string{"control-plane-catalog", "default"},
+			entries: []*v1alpha1.AppCatalogEntry{
+				newAppCatalogEntry("app-operator", "control-plane-catalog", "7.0.0"),
+				unlabelled(newAppCatalogEntry("chart-operator", "default", "4.0.0")),
+			},
+			expectedVersion: "4.0.0",
+			expectedEvent:   true,
+		},
+		{
+			name:     "case 5: existing app not patched when catalog does not exist",
+			catalogs: []string{"control-plane-catalog"},
+			entries: []*v1alpha1.AppCatalogEntry{
+				newAppCatalogEntry("app-operator", "control-plane-catalog", "7.0.0"),
+				newAppCatalogEntry("chart-operator", "default", "4.0.0"),
+			},
+			currentChartOperator: newChartOperatorApp("3.0.0"),
+			expectedVersion:      "3.0.0",
+			expectedEvent:        true,
+		},
+		{
+			name:     "case 6: chart-operator labelled with app-operator version kept when new one does not exist",
+			catalogs: []string{"control-plane-catalog", "default"},
+			entries: []*v1alpha1.AppCatalogEntry{
+				newAppCatalogEntry("app-operator", "control-plane-catalog", "6.0.0"),
+				newAppCatalogEntry("chart-operator", "default", "4.0.0"),
+			},
+			currentAppOperator:         newAppOperatorApp("6.0.0"),
+			currentChartOperator:       newChartOperatorApp("4.0.0"),
+			expectedVersion:            "4.0.0",
+			expectedAppOperatorVersion: "6.0.0",
+			expectedEvent:              true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			for _, addToScheme := range []func(*runtime.Scheme) error{corev1.AddToScheme, v1alpha1.AddToScheme, capi.AddToScheme} {
+				err := addToScheme(scheme)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "demo0",
+					Namespace: "org-acme",
+					Labels: map[string]string{
+						label.Cluster: "demo0",
+					},
+				},
+			}
+
+			objects := []runtime.Object{cluster}
+			for _, name := range tc.catalogs {
+				objects = append(objects, newCatalog(name))
+			}
+			for _, entry := range tc.entries {
+				objects = append(objects, entry)
+			}
+			if tc.currentAppOperator != nil {
+				objects = append(objects, tc.currentAppOperator)
+			}
+			if tc.currentChartOperator != nil {
+				objects = append(objects, tc.currentChartOperator)
+			}
+
+			eventRecorder := record.NewFakeRecorder(10)
+
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(objects...).
+				WithStatusSubresource(&capi.Cluster{}).
+				Build()
+
+			r, err := New(Config{
+				CtrlClient:    ctrlClient,
+				EventRecorder: eventRecorder,
+				Logger:        microloggertest.New(),
+				Resolver:      infrastructuretest.NewResolver(t, ctrlClient),
+
+				AppOperatorCatalog:   "control-plane-catalog",
+				AppOperatorVersion:   "7.0.0",
+				ChartOperatorCatalog: "default",
+				ChartOperatorVersion: "4.0.0",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = r.EnsureCreated(context.Background(), cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expectedAppOperatorVersion := tc.expectedAppOperatorVersion
+			if expectedAppOperatorVersion == "" {
+				expectedAppOperatorVersion = "7.0.0"
+			}
+
+			var app v1alpha1.App
+			err = r.ctrlClient.Get(context.Background(), types.NamespacedName{Namespace: "org-acme", Name: "demo0-app-operator"}, &app)
+			if err != nil {
+				t.Fatalf("expected app-operator app to be created, got %v", err)
+			} else if app.Spec.Version != expectedAppOperatorVersion {
+				t.Fatalf("expected app-operator version %#q, got %#q", expectedAppOperatorVersion, app.Spec.Version)
+			}
+
+			err = r.ctrlClient.Get(context.Background(), types.NamespacedName{Namespace: "org-acme", Name: "demo0-chart-operator"}, &app)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if app.Spec.Version != tc.expectedVersion {
+				t.Fatalf("expected chart-operator version %#q, got %#q", tc.expectedVersion, app.Spec.Version)
+			} else if app.Labels[label.AppOperatorVersion] != expectedAppOperatorVersion {
+				t.Fatalf("expected chart-operator app-operator version label %#q, got %#q", expectedAppOperatorVersion, app.Labels[label.AppOperatorVersion])
+			}
+
+			var events []string
+			close(eventRecorder.Events)
+			for e := range eventRecorder.Events {
+				events = append(events, e)
+			}
+
+			if !tc.expectedEvent && len(events) > 0 {
+				t.Fatalf("expected no events, got %v", events)
+			}
+			if tc.expectedEvent && (len(events) != 1 || !strings.Contains(events[0], AppVersionNotFoundReason)) {
+				t.Fatalf("expected one %s event, got %v", AppVersionNotFoundReason, events)
+			}
+
+			var updated capi.Cluster
+			err = r.ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), &updated)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedStatus := metav1.ConditionTrue
+			if tc.expectedEvent {
+				expectedStatus = metav1.ConditionFalse
+			}
+			condition := meta.FindStatusCondition(updated.Status.Conditions, AppVersionResolvedCondition)
+			if condition == nil || condition.Status != expectedStatus {
+				t.Fatalf("expected condition %s with status %s, got %#v", AppVersionResolvedCondition, expectedStatus, condition)
+			}
+		})
+	}
+}
+
+func unlabelled(entry *v1alpha1.AppCatalogEntry) *v1alpha1.AppCatalogEntry {
+	entry.Labels = nil
+	return entry
+}
+
+func newCatalog(name string) *v1alpha1.Catalog {
+	return &v1alpha1.Catalog{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "giantswarm",
+		},
+	}
+}
+
+func newAppCatalogEntry(app, catalog, version string) *v1alpha1.AppCatalogEntry {
+	return &v1alpha1.AppCatalogEntry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      catalog + "-" + app + "-" + version,
+			Namespace: "giantswarm",
+			Labels: map[string]string{
+				label.AppKubernetesName: app,
+				label.CatalogName:       catalog,
+			},
+		},
+		Spec: v1alpha1.AppCatalogEntrySpec{
+			AppName: app,
+			Catalog: v1alpha1.AppCatalogEntrySpecCatalog{
+				Name:      catalog,
+				Namespace: "giantswarm",
+			},
+			Version: version,
+		},
+	}
+}
+
+func newAppOperatorApp(version string) *v1alpha1.App {
+	return &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo0-app-operator",
+			Namespace: "org-acme",
+			Labels: map[string]string{
+				label.Cluster:   "demo0",
+				label.ManagedBy: project.Name(),
+			},
+		},
+		Spec: v1alpha1.AppSpec{
+			Catalog: "control-plane-catalog",
+			Name:    "app-operator",
+			Version: version,
+		},
+	}
+}
+
+func newChartOperatorApp(version string) *v1alpha1.App {
+	return &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo0-chart-operator",
+			Namespace: "org-acme",
+			Labels: map[string]string{
+				label.Cluster:   "demo0",
+				label.ManagedBy: project.Name(),
+			},
+		},
+		Spec: v1alpha1.AppSpec{
+			Catalog: "default",
+			Name:    "chart-operator",
+			Version: version,
+		},
+	}
+}
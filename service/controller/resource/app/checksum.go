@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AnnotationValuesChecksum is set on App CRs to the checksum of the values
+// ConfigMap and Secret they reference. Any change of the values updates the
+// App CR, which makes app-operator and chart-operator apply the new values
+// right away instead of waiting for their next resync.
+const AnnotationValuesChecksum = "cluster-apps-operator.giantswarm.io/values-checksum"
+
+// setValuesChecksum sets AnnotationValuesChecksum on the App. Values which do
+// not exist yet do not contribute to the checksum, so it changes once they are
+// created.
+func (r *Resource) setValuesChecksum(ctx context.Context, app *v1alpha1.App) error {
+	h := sha256.New()
+
+	if ref := app.Spec.Config.ConfigMap; ref.Name != "" {
+		var cm corev1.ConfigMap
+		err := r.ctrlClient.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, &cm)
+		if apierrors.IsNotFound(err) {
+			r.logger.Debugf(ctx, "values configmap '%s/%s' of app '%s/%s' not found", ref.Namespace, ref.Name, app.Namespace, app.Name)
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			data := map[string][]byte{}
+			for k, v := range cm.Data {
+				data[k] = []byte(v)
+			}
+			writeValues(h, "configmap", cm.Namespace, cm.Name, data)
+		}
+	}
+
+	if ref := app.Spec.Config.Secret; ref.Name != "" {
+		var secret corev1.Secret
+		err := r.ctrlClient.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, &secret)
+		if apierrors.IsNotFound(err) {
+			r.logger.Debugf(ctx, "values secret '%s/%s' of app '%s/%s' not found", ref.Namespace, ref.Name, app.Namespace, app.Name)
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			writeValues(h, "secret", secret.Namespace, secret.Name, secret.Data)
+		}
+	}
+
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	app.Annotations[AnnotationValuesChecksum] = hex.EncodeToString(h.Sum(nil))
+
+	return nil
+}
+
+// writeValues writes the values document in a stable order, so the checksum
+// only depends on its content.
+func writeValues(w io.Writer, kind, namespace, name string, data map[string][]byte) {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	_, _ = fmt.Fprintf(w, "%s/%s/%s\n", kind, namespace, name)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(w, "%s %d\n", k, len(data[k]))
+		_, _ = w.Write(data[k])
+	}
+}
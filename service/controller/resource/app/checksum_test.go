@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+func Test_setValuesChecksum(t *testing.T) {
+	base := []runtime.Object{
+		newValuesConfigMap("clusterDNSIP: 172.31.0.10\n"),
+		newValuesSecret("proxy: {}\n"),
+	}
+
+	testCases := []struct {
+		name          string
+		objects       []runtime.Object
+		expectedEqual bool
+	}{
+		{
+			name:          "case 0: same values give same checksum",
+			objects:       base,
+			expectedEqual: true,
+		},
+		{
+			name: "case 1: changed configmap changes checksum",
+			objects: []runtime.Object{
+				newValuesConfigMap("clusterDNSIP: 172.31.0.11\n"),
+				newValuesSecret("proxy: {}\n"),
+			},
+		},
+		{
+			name: "case 2: changed secret changes checksum",
+			objects: []runtime.Object{
+				newValuesConfigMap("clusterDNSIP: 172.31.0.10\n"),
+				newValuesSecret("proxy:\n  noProxy: example.com\n"),
+			},
+		},
+		{
+			name: "case 3: missing secret changes checksum",
+			objects: []runtime.Object{
+				newValuesConfigMap("clusterDNSIP: 172.31.0.10\n"),
+			},
+		},
+	}
+
+	baseChecksum := valuesChecksum(t, base)
+	if baseChecksum == "" {
+		t.Fatalf("expected checksum annotation to be set")
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checksum := valuesChecksum(t, tc.objects)
+			if (checksum == baseChecksum) != tc.expectedEqual {
+				t.Fatalf("expected checksum equal %t, got %#q and %#q", tc.expectedEqual, baseChecksum, checksum)
+			}
+		})
+	}
+}
+
+func valuesChecksum(t *testing.T, objects []runtime.Object) string {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	err := corev1.AddToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctrlClient := clientfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(objects...).
+		Build()
+
+	r, err := New(Config{
+		CtrlClient:    ctrlClient,
+		EventRecorder: record.NewFakeRecorder(10),
+		Logger:        microloggertest.New(),
+		Resolver:      infrastructuretest.NewResolver(t, ctrlClient),
+
+		AppOperatorCatalog:   "control-plane-catalog",
+		AppOperatorVersion:   "7.0.0",
+		ChartOperatorCatalog: "default",
+		ChartOperatorVersion: "4.0.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &v1alpha1.App{
+		Spec: v1alpha1.AppSpec{
+			Config: v1alpha1.AppSpecConfig{
+				ConfigMap: v1alpha1.AppSpecConfigConfigMap{Name: "demo0-cluster-values", Namespace: "org-acme"},
+				Secret:    v1alpha1.AppSpecConfigSecret{Name: "demo0-cluster-values", Namespace: "org-acme"},
+			},
+		},
+	}
+
+	err = r.setValuesChecksum(context.Background(), app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app.Annotations[AnnotationValuesChecksum]
+}
+
+func newValuesConfigMap(values string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo0-cluster-values", Namespace: "org-acme"},
+		Data:       map[string]string{"values": values},
+	}
+}
+
+func newValuesSecret(values string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo0-cluster-values", Namespace: "org-acme"},
+		Data:       map[string][]byte{"values": []byte(values)},
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+// compatibleChartOperator selects the chart-operator version and catalog of
+// the compatibility matrix entry matching the Kubernetes version of the
+// cluster. Clusters held back on a version older than the newest one of the
+// matrix are reported by chartOperatorHeldBackGauge.
+func (r *Resource) compatibleChartOperator(ctx context.Context, cr key.Cluster, versions operatorVersions) (operatorVersions, error) {
+	if r.chartOperatorCompatibility == nil {
+		return versions, nil
+	}
+
+	deleteChartOperatorHeldBack(cr)
+
+	kubernetesVersion, err := r.kubernetesVersion(ctx, cr)
+	if err != nil {
+		return operatorVersions{}, microerror.Mask(err)
+	}
+	if kubernetesVersion == "" {
+		r.logger.Debugf(ctx, "kubernetes version of cluster '%s/%s' not known, using chart-operator version %#q", cr.GetNamespace(), key.ClusterID(cr), versions.ChartOperatorVersion)
+		return versions, nil
+	}
+
+	entry, ok := r.chartOperatorCompatibility.Lookup(kubernetesVersion)
+	if !ok {
+		return versions, nil
+	}
+
+	versions.ChartOperatorVersion = entry.Version
+	if entry.Catalog != "" {
+		versions.ChartOperatorCatalog = entry.Catalog
+	}
+
+	newest, _ := r.chartOperatorCompatibility.Newest()
+	if normalizeVersion(entry.Version) != normalizeVersion(newest.Version) {
+		r.logger.Debugf(ctx, "cluster '%s/%s' runs kubernetes %#q, holding back chart-operator on version %#q instead of %#q", cr.GetNamespace(), key.ClusterID(cr), kubernetesVersion, entry.Version, newest.Version)
+
+		chartOperatorHeldBackGauge.WithLabelValues(cr.GetNamespace(), key.ClusterID(cr), kubernetesVersion, entry.Version).Set(1)
+	}
+
+	return versions, nil
+}
+
+// kubernetesVersion returns the Kubernetes version of the cluster topology, or
+// the version of its control plane object for clusters not based on a
+// ClusterClass. It returns an empty string if neither is known yet.
+func (r *Resource) kubernetesVersion(ctx context.Context, cr key.Cluster) (string, error) {
+	if v := key.TopologyVersion(cr); v != "" {
+		return v, nil
+	}
+
+	ref := key.ControlPlaneRef(cr)
+	if ref == nil {
+		return "", nil
+	}
+
+	controlPlane, err := r.resolver.Get(ctx, cr, ref.GroupVersionKind(), ref.Namespace, ref.Name)
+	if infra.IsNotFound(err) || infra.IsKindNotServed(err) {
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	v, _, err := unstructured.NestedString(controlPlane.Object, "spec", "version")
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return v, nil
+}
+
+func deleteChartOperatorHeldBack(cr key.Cluster) {
+	chartOperatorHeldBackGauge.DeletePartialMatch(map[string]string{
+		"namespace":  cr.GetNamespace(),
+		"cluster_id": key.ClusterID(cr),
+	})
+}
@@ -0,0 +1,253 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/compatibility"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+func Test_operatorVersions_Compatibility(t *testing.T) {
+	matrix, err := compatibility.New([]compatibility.Entry{
+		{MaxKubernetesVersion: "1.25.0", Catalog: "default-legacy", Version: "2.0.0"},
+		{MinKubernetesVersion: "1.25.0", MaxKubernetesVersion: "1.30.0", Version: "3.0.0"},
+		{MinKubernetesVersion: "1.30.0", Version: "4.0.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The newest version of this matrix is ahead of the configured one.
+	aheadMatrix, err := compatibility.New([]compatibility.Entry{
+		{MaxKubernetesVersion: "1.30.0", Version: "4.0.0"},
+		{MinKubernetesVersion: "1.30.0", Version: "5.0.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name             string
+		cluster          *capi.Cluster
+		controlPlane     *unstructured.Unstructured
+		release          *unstructured.Unstructured
+		matrix           *compatibility.Matrix
+		expectedCatalog  string
+		expectedVersion  string
+		expectedHeldBack []string
+	}{
+		{
+			name:            "case 0: no matrix uses flags",
+			cluster:         newCompatibilityTestCluster("v1.24.3", false),
+			expectedCatalog: "default",
+			expectedVersion: "4.0.0",
+		},
+		{
+			name:             "case 1: topology version selects held back version and catalog",
+			cluster:          newCompatibilityTestCluster("v1.24.3", false),
+			matrix:           matrix,
+			expectedCatalog:  "default-legacy",
+			expectedVersion:  "2.0.0",
+			expectedHeldBack: []string{"v1.24.3", "2.0.0"},
+		},
+		{
+			name:            "case 2: topology version selects configured version",
+			cluster:         newCompatibilityTestCluster("v1.31.1", false),
+			matrix:          matrix,
+			expectedCatalog: "default",
+			expectedVersion: "4.0.0",
+		},
+		{
+			name:             "case 3: control plane version used without topology",
+			cluster:          newCompatibilityTestCluster("", true),
+			controlPlane:     newControlPlane("v1.27.2"),
+			matrix:           matrix,
+			expectedCatalog:  "default",
+			expectedVersion:  "3.0.0",
+			expectedHeldBack: []string{"v1.27.2", "3.0.0"},
+		},
+		{
+			name:            "case 4: missing control plane uses flags",
+			cluster:         newCompatibilityTestCluster("", true),
+			matrix:          matrix,
+			expectedCatalog: "default",
+			expectedVersion: "4.0.0",
+		},
+		{
+			name: "case 5: release takes precedence over matrix",
+			cluster: func() *capi.Cluster {
+				cluster := newCompatibilityTestCluster("v1.24.3", false)
+				cluster.Labels[label.ReleaseVersion] = "25.0.0"
+				return cluster
+			}(),
+			release: newRelease("25.0.0",
+				map[string]interface{}{"name": "chart-operator", "version": "4.2.0"},
+			),
+			matrix:          matrix,
+			expectedCatalog: "default",
+			expectedVersion: "4.2.0",
+		},
+		{
+			name:            "case 6: newest version of the matrix is not held back",
+			cluster:         newCompatibilityTestCluster("v1.31.1", false),
+			matrix:          aheadMatrix,
+			expectedCatalog: "default",
+			expectedVersion: "5.0.0",
+		},
+		{
+			name:             "case 7: configured version held back behind newest version of the matrix",
+			cluster:          newCompatibilityTestCluster("v1.29.0", false),
+			matrix:           aheadMatrix,
+			expectedCatalog:  "default",
+			expectedVersion:  "4.0.0",
+			expectedHeldBack: []string{"v1.29.0", "4.0.0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chartOperatorHeldBackGauge.Reset()
+
+			scheme := runtime.NewScheme()
+			for _, addToScheme := range []func(*runtime.Scheme) error{capiv1beta1.AddToScheme, capi.AddToScheme} {
+				err := addToScheme(scheme)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			objects := []runtime.Object{tc.cluster}
+			if tc.controlPlane != nil {
+				objects = append(objects, tc.controlPlane)
+			}
+			if tc.release != nil {
+				objects = append(objects, tc.release)
+			}
+
+			mapperObjects := append([]runtime.Object{newControlPlane("")}, objects...)
+
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(infrastructuretest.NewRESTMapper(mapperObjects...)).
+				WithRuntimeObjects(objects...).
+				WithStatusSubresource(&capi.Cluster{}).
+				Build()
+
+			r, err := New(Config{
+				CtrlClient:    ctrlClient,
+				EventRecorder: record.NewFakeRecorder(10),
+				Logger:        microloggertest.New(),
+				Resolver:      infrastructuretest.NewResolver(t, ctrlClient),
+
+				ChartOperatorCompatibility: tc.matrix,
+
+				AppOperatorCatalog:   "control-plane-catalog",
+				AppOperatorVersion:   "7.0.0",
+				ChartOperatorCatalog: "default",
+				ChartOperatorVersion: "4.0.0",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			versions, _, err := r.operatorVersions(context.Background(), tc.cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if versions.ChartOperatorCatalog != tc.expectedCatalog {
+				t.Fatalf("expected chart-operator catalog %#q, got %#q", tc.expectedCatalog, versions.ChartOperatorCatalog)
+			}
+			if versions.ChartOperatorVersion != tc.expectedVersion {
+				t.Fatalf("expected chart-operator version %#q, got %#q", tc.expectedVersion, versions.ChartOperatorVersion)
+			}
+
+			heldBack := chartOperatorHeldBackSeries(t)
+			if tc.expectedHeldBack == nil {
+				if len(heldBack) != 0 {
+					t.Fatalf("expected no held back series, got %v", heldBack)
+				}
+				return
+			}
+			expected := append([]string{"org-acme", "demo0"}, tc.expectedHeldBack...)
+			if len(heldBack) != 1 || fmt.Sprint(heldBack[0]) != fmt.Sprint(expected) {
+				t.Fatalf("expected held back series %v, got %v", expected, heldBack)
+			}
+		})
+	}
+}
+
+func newCompatibilityTestCluster(topologyVersion string, controlPlaneRef bool) *capi.Cluster {
+	cluster := newReleaseTestCluster("")
+	if topologyVersion != "" {
+		cluster.Spec.Topology = capi.Topology{Version: topologyVersion}
+	}
+	if controlPlaneRef {
+		cluster.Spec.ControlPlaneRef = capi.ContractVersionedObjectReference{
+			APIGroup: "controlplane.cluster.x-k8s.io",
+			Kind:     "KubeadmControlPlane",
+			Name:     "demo0",
+		}
+	}
+
+	return cluster
+}
+
+func newControlPlane(kubernetesVersion string) *unstructured.Unstructured {
+	controlPlane := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"version": kubernetesVersion,
+			},
+		},
+	}
+	controlPlane.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "controlplane.cluster.x-k8s.io",
+		Version: "v1beta2",
+		Kind:    "KubeadmControlPlane",
+	})
+	controlPlane.SetName("demo0")
+	controlPlane.SetNamespace("org-acme")
+
+	return controlPlane
+}
+
+// chartOperatorHeldBackSeries returns the label values of every series of
+// chartOperatorHeldBackGauge.
+func chartOperatorHeldBackSeries(t *testing.T) [][]string {
+	t.Helper()
+
+	ch := make(chan prometheus.Metric, 10)
+	chartOperatorHeldBackGauge.Collect(ch)
+	close(ch)
+
+	var series [][]string
+	for m := range ch {
+		var metric dto.Metric
+		err := m.Write(&metric)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		values := map[string]string{}
+		for _, l := range metric.GetLabel() {
+			values[l.GetName()] = l.GetValue()
+		}
+		series = append(series, []string{values["namespace"], values["cluster_id"], values["kubernetes_version"], values["version"]})
+	}
+
+	return series
+}
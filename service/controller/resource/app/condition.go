@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+// updateClusterCondition sets the condition of the given type on the cluster,
+// or removes it if condition is nil. It is best effort. The cluster is fetched
+// again on conflicts with other controllers writing its status, and other
+// failures are only logged since the condition is written again in the next
+// reconciliation.
+func (r *Resource) updateClusterCondition(ctx context.Context, cr key.Cluster, conditionType string, condition *metav1.Condition) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := cr.DeepCopyObject().(key.Cluster)
+		err := r.ctrlClient.Get(ctx, client.ObjectKeyFromObject(cr), current)
+		if err != nil {
+			return err
+		}
+
+		modified := current.DeepCopyObject().(key.Cluster)
+
+		var changed bool
+		if condition == nil {
+			changed = key.RemoveClusterCondition(modified, conditionType)
+		} else {
+			changed = key.SetClusterCondition(modified, *condition)
+		}
+		if !changed {
+			return nil
+		}
+
+		// The optimistic lock keeps conditions set by other controllers
+		// in the meantime from being overwritten.
+		patch := client.MergeFromWithOptions(current, client.MergeFromWithOptimisticLock{})
+
+		return r.ctrlClient.Status().Patch(ctx, modified, patch)
+	})
+	if err != nil {
+		r.logger.Errorf(ctx, err, "failed to update condition %#q of cluster '%s/%s'", conditionType, cr.GetNamespace(), cr.GetName())
+	}
+}
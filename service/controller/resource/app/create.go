@@ -14,11 +14,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
 )
 
 func (r Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
@@ -32,9 +32,63 @@ func (r Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
-	for _, app := range r.desiredApps(ctx, cr) {
+	r.recorder.Update(cr, func(s *clusterstatus.ClusterStatus) {
+		s.Apps = appStatuses(currentApps)
+	})
+
+	versions, releaseCondition, err := r.operatorVersions(ctx, cr)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var unresolvable []string
+	var appOperatorVersion string
+	for _, app := range r.desiredApps(ctx, cr, versions) {
 		currentApp := findAppByName(currentApps, app.Name, app.Namespace)
 
+		// Existing App CRs are not patched to a catalog or version which
+		// cannot be resolved, so a misconfigured catalog or version does
+		// not break working apps. New App CRs are created anyway, since
+		// app-operator only keeps AppCatalogEntries for the newest
+		// versions of an app and older ones may still be installable.
+		if isVersionChanged(currentApp, app) {
+			message, err := r.unresolvableVersion(ctx, app)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			if message != "" {
+				if currentApp != nil {
+					message += fmt.Sprintf(", keeping version %#q of catalog %#q", currentApp.Spec.Version, currentApp.Spec.Catalog)
+				}
+				r.reportUnresolvableVersion(ctx, cr, app, message)
+				unresolvable = append(unresolvable, message)
+
+				if currentApp != nil {
+					app.Spec.Catalog = currentApp.Spec.Catalog
+					app.Spec.Version = currentApp.Spec.Version
+				}
+			}
+		}
+
+		// The chart-operator App is reconciled by the app-operator named in
+		// its version label, so it must name the app-operator version which
+		// is actually applied. desiredApps returns the app-operator App first.
+		if app.Name == key.AppOperatorAppName(cr) {
+			appOperatorVersion = app.Spec.Version
+		} else if appOperatorVersion != "" && app.Labels[label.AppOperatorVersion] != uniqueOperatorVersion {
+			app.Labels[label.AppOperatorVersion] = appOperatorVersion
+		}
+
+		err = r.setValuesChecksum(ctx, app)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = setLastAppliedMetadata(app)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
 		if currentApp == nil {
 			r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating app '%s/%s'", app.Namespace, app.Name))
 
@@ -61,9 +115,13 @@ func (r Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 				return microerror.Mask(err)
 			}
 
+			// Labels and annotations of other controllers and humans are
+			// kept, only the keys owned by the operator are updated.
+			previous := lastAppliedMetadata(&currentApp)
+
 			modifiedApp := currentApp.DeepCopy()
-			modifiedApp.Annotations = app.Annotations
-			modifiedApp.Labels = app.Labels
+			modifiedApp.Annotations = mergeOwned(currentApp.Annotations, app.Annotations, previous.Annotations)
+			modifiedApp.Labels = mergeOwned(currentApp.Labels, app.Labels, previous.Labels)
 			modifiedApp.Spec = app.Spec
 
 			err = r.ctrlClient.Patch(ctx, modifiedApp, client.MergeFrom(&currentApp))
@@ -75,15 +133,18 @@ func (r Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
 		}
 	}
 
+	r.updateClusterCondition(ctx, cr, ReleaseResolvedCondition, releaseCondition)
+	r.updateClusterCondition(ctx, cr, AppVersionResolvedCondition, appVersionCondition(unresolvable))
+
 	return nil
 }
 
-func (r *Resource) currentApps(ctx context.Context, cr capi.Cluster) ([]*v1alpha1.App, error) {
+func (r *Resource) currentApps(ctx context.Context, cr key.Cluster) ([]*v1alpha1.App, error) {
 	var apps []*v1alpha1.App
 	{
-		r.logger.Debugf(ctx, "finding apps for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(&cr))
+		r.logger.Debugf(ctx, "finding apps for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(cr))
 
-		selector, err := labels.Parse(fmt.Sprintf("%s=%s,%s=%s", label.Cluster, key.ClusterID(&cr), label.ManagedBy, project.Name()))
+		selector, err := labels.Parse(fmt.Sprintf("%s=%s,%s=%s", label.Cluster, key.ClusterID(cr), label.ManagedBy, project.Name()))
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -104,43 +165,67 @@ func (r *Resource) currentApps(ctx context.Context, cr capi.Cluster) ([]*v1alpha
 			apps = append(apps, item.DeepCopy())
 		}
 
-		r.logger.Debugf(ctx, "found %d apps for cluster '%s/%s'", len(apps), cr.GetNamespace(), key.ClusterID(&cr))
+		r.logger.Debugf(ctx, "found %d apps for cluster '%s/%s'", len(apps), cr.GetNamespace(), key.ClusterID(cr))
+	}
+
+	// Owner references are not part of hasAppChanged, so existing apps
+	// without them would never be updated. They are patched here instead,
+	// like the configmaps and secrets of the cluster. Custom resources do not
+	// support strategic merge patches, so the merge patch carries the whole
+	// list and is guarded by the resource version to keep concurrent changes.
+	for i, item := range apps {
+		if key.HasClusterOwnerReferences(cr, item) {
+			continue
+		}
+
+		r.logger.Debugf(ctx, "adding owner references to app '%s/%s'", item.Namespace, item.Name)
+
+		patched := item.DeepCopy()
+		patched.OwnerReferences = append(patched.OwnerReferences, key.ClusterOwnerReferences(cr)...)
+
+		err := r.ctrlClient.Patch(ctx, patched, client.MergeFromWithOptions(item, client.MergeFromWithOptimisticLock{}))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		apps[i] = patched
+
+		r.logger.Debugf(ctx, "added owner references to app '%s/%s'", item.Namespace, item.Name)
 	}
 
 	return apps, nil
 }
 
-func (r *Resource) desiredApps(ctx context.Context, cr capi.Cluster) []*v1alpha1.App {
+func (r *Resource) desiredApps(ctx context.Context, cr key.Cluster, versions operatorVersions) []*v1alpha1.App {
 	appSpecs := []AppSpec{
 		{
 			App: "app-operator",
 			// app-operator is deployed by the management cluster
 			// instance.
 			AppOperatorVersion: uniqueOperatorVersion,
-			AppName:            key.AppOperatorAppName(&cr),
-			Catalog:            r.appOperatorCatalog,
-			ConfigMapName:      key.AppOperatorValuesResourceName(&cr),
+			AppName:            key.AppOperatorAppName(cr),
+			Catalog:            versions.AppOperatorCatalog,
+			ConfigMapName:      key.AppOperatorValuesResourceName(cr),
 			ConfigMapNamespace: cr.GetNamespace(),
 			InCluster:          true,
 			TargetNamespace:    cr.GetNamespace(),
 			UseUpgradeForce:    false,
-			Version:            r.appOperatorVersion,
+			Version:            versions.AppOperatorVersion,
 		},
 		{
 			App: "chart-operator",
 			// chart-operator is deployed by the workload cluster
 			// instance.
-			AppOperatorVersion: r.appOperatorVersion,
-			AppName:            key.ChartOperatorAppName(&cr),
-			Catalog:            r.chartOperatorCatalog,
-			ConfigMapName:      key.ClusterValuesResourceName(&cr),
+			AppOperatorVersion: versions.AppOperatorVersion,
+			AppName:            key.ChartOperatorAppName(cr),
+			Catalog:            versions.ChartOperatorCatalog,
+			ConfigMapName:      key.ClusterValuesResourceName(cr),
 			ConfigMapNamespace: cr.GetNamespace(),
 			InCluster:          false,
 			TargetNamespace:    "giantswarm",
-			SecretName:         key.ClusterValuesResourceName(&cr),
+			SecretName:         key.ClusterValuesResourceName(cr),
 			SecretNamespace:    cr.GetNamespace(),
 			UseUpgradeForce:    false,
-			Version:            r.chartOperatorVersion,
+			Version:            versions.ChartOperatorVersion,
 		},
 	}
 
@@ -153,7 +238,7 @@ func (r *Resource) desiredApps(ctx context.Context, cr capi.Cluster) []*v1alpha1
 	return apps
 }
 
-func (r *Resource) newApp(ctx context.Context, cr capi.Cluster, appSpec AppSpec) *v1alpha1.App {
+func (r *Resource) newApp(ctx context.Context, cr key.Cluster, appSpec AppSpec) *v1alpha1.App {
 	var kubeConfig v1alpha1.AppSpecKubeConfig
 
 	if appSpec.InCluster || key.IsBundle(appSpec.App) {
@@ -163,10 +248,10 @@ func (r *Resource) newApp(ctx context.Context, cr capi.Cluster, appSpec AppSpec)
 	} else {
 		kubeConfig = v1alpha1.AppSpecKubeConfig{
 			Context: v1alpha1.AppSpecKubeConfigContext{
-				Name: key.KubeConfigSecretName(&cr),
+				Name: key.KubeConfigSecretName(cr),
 			},
 			Secret: v1alpha1.AppSpecKubeConfigSecret{
-				Name:      key.KubeConfigSecretName(&cr),
+				Name:      key.KubeConfigSecretName(cr),
 				Namespace: cr.GetNamespace(),
 			},
 		}
@@ -178,7 +263,7 @@ func (r *Resource) newApp(ctx context.Context, cr capi.Cluster, appSpec AppSpec)
 	// so the cluster-operator for the wc deploys the apps to the WC.
 	appOperatorVersion := appSpec.AppOperatorVersion
 	if key.IsBundle(appSpec.App) {
-		appName = fmt.Sprintf("%s-%s", key.ClusterID(&cr), appName)
+		appName = fmt.Sprintf("%s-%s", key.ClusterID(cr), appName)
 		appOperatorVersion = uniqueOperatorVersion
 		appNamespace = cr.GetNamespace()
 	}
@@ -195,11 +280,12 @@ func (r *Resource) newApp(ctx context.Context, cr capi.Cluster, appSpec AppSpec)
 			Labels: map[string]string{
 				label.AppKubernetesName:  appSpec.App,
 				label.AppOperatorVersion: appOperatorVersion,
-				label.Cluster:            key.ClusterID(&cr),
+				label.Cluster:            key.ClusterID(cr),
 				label.ManagedBy:          project.Name(),
 			},
-			Name:      appName,
-			Namespace: cr.GetNamespace(),
+			Name:            appName,
+			Namespace:       cr.GetNamespace(),
+			OwnerReferences: key.ClusterOwnerReferences(cr),
 		},
 		Spec: v1alpha1.AppSpec{
 			Catalog: appSpec.Catalog,
@@ -221,6 +307,22 @@ func (r *Resource) newApp(ctx context.Context, cr capi.Cluster, appSpec AppSpec)
 	}
 }
 
+func appStatuses(apps []*v1alpha1.App) []clusterstatus.AppStatus {
+	var statuses []clusterstatus.AppStatus
+
+	for _, app := range apps {
+		statuses = append(statuses, clusterstatus.AppStatus{
+			Name:    app.Name,
+			Catalog: app.Spec.Catalog,
+			Version: app.Spec.Version,
+			Status:  app.Status.Release.Status,
+			Reason:  app.Status.Release.Reason,
+		})
+	}
+
+	return statuses
+}
+
 func hasAppChanged(current, desired *v1alpha1.App) bool {
 	if current == nil || desired == nil {
 		return false
@@ -228,10 +330,12 @@ func hasAppChanged(current, desired *v1alpha1.App) bool {
 	if !reflect.DeepEqual(current.Spec, desired.Spec) {
 		return true
 	}
-	if !reflect.DeepEqual(current.Annotations, desired.Annotations) {
+
+	previous := lastAppliedMetadata(current)
+	if hasOwnedChanged(current.Annotations, desired.Annotations, previous.Annotations) {
 		return true
 	}
-	if !reflect.DeepEqual(current.Labels, desired.Labels) {
+	if hasOwnedChanged(current.Labels, desired.Labels, previous.Labels) {
 		return true
 	}
 
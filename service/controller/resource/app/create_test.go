@@ -1,11 +1,21 @@
 package app
 
 import (
+	"context"
 	"strconv"
 	"testing"
 
 	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake" //nolint:staticcheck
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
 )
 
 func Test_hasAppChanged(t *testing.T) {
@@ -133,6 +143,99 @@ func Test_hasAppChanged(t *testing.T) {
 			},
 			result: true,
 		},
+		{
+			name: "return false when only labels of other controllers are added",
+			current: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "eggs2-app-operator",
+					Namespace: "org-test",
+					Annotations: map[string]string{
+						"app-operator.giantswarm.io/paused": "true",
+					},
+					Labels: map[string]string{
+						"giantswarm.io/cluster":            "eggs2",
+						"kustomize.toolkit.fluxcd.io/name": "flux",
+					},
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "control-plane-catalog",
+					Name:    "app-operator",
+				},
+			},
+			desired: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "eggs2-app-operator",
+					Namespace: "org-test",
+					Labels: map[string]string{
+						"giantswarm.io/cluster": "eggs2",
+					},
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "control-plane-catalog",
+					Name:    "app-operator",
+				},
+			},
+			result: false,
+		},
+		{
+			name: "return true when previously applied annotation is no longer desired",
+			current: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "eggs2-app-operator",
+					Namespace: "org-test",
+					Annotations: map[string]string{
+						"chart-operator.giantswarm.io/force-helm-upgrade": "true",
+						AnnotationLastAppliedMetadata:                     `{"annotations":["chart-operator.giantswarm.io/force-helm-upgrade"]}`,
+					},
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "control-plane-catalog",
+					Name:    "app-operator",
+				},
+			},
+			desired: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "eggs2-app-operator",
+					Namespace: "org-test",
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "control-plane-catalog",
+					Name:    "app-operator",
+				},
+			},
+			result: true,
+		},
+		{
+			name: "return false when only owner references differ",
+			current: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "eggs2-app-operator",
+					Namespace: "org-test",
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "cluster.x-k8s.io/v1beta2",
+							Kind:       "Cluster",
+							Name:       "eggs2",
+						},
+					},
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "control-plane-catalog",
+					Name:    "app-operator",
+				},
+			},
+			desired: &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "eggs2-app-operator",
+					Namespace: "org-test",
+				},
+				Spec: v1alpha1.AppSpec{
+					Catalog: "control-plane-catalog",
+					Name:    "app-operator",
+				},
+			},
+			result: false,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -145,3 +248,98 @@ func Test_hasAppChanged(t *testing.T) {
 		})
 	}
 }
+
+func Test_currentApps_OwnerReferences(t *testing.T) {
+	cluster := &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "eggs2",
+			Namespace: "org-test",
+			UID:       "eggs2-uid",
+			Labels: map[string]string{
+				label.Cluster: "eggs2",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		ownerReferences []metav1.OwnerReference
+		expectedOwners  int
+	}{
+		{
+			name:           "case 0: missing owner references are added",
+			expectedOwners: 1,
+		},
+		{
+			name:            "case 1: existing owner references are kept",
+			ownerReferences: key.ClusterOwnerReferences(cluster),
+			expectedOwners:  1,
+		},
+		{
+			name: "case 2: owner references of other objects are kept",
+			ownerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "example.com/v1",
+					Kind:       "Other",
+					Name:       "other",
+					UID:        "other-uid",
+				},
+			},
+			expectedOwners: 2,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			app := &v1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "eggs2-app-operator",
+					Namespace: "org-test",
+					Labels: map[string]string{
+						label.Cluster:   "eggs2",
+						label.ManagedBy: project.Name(),
+					},
+					OwnerReferences: tc.ownerReferences,
+				},
+			}
+
+			scheme := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(scheme)
+
+			ctrlClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(app).
+				Build()
+
+			r := &Resource{
+				ctrlClient: ctrlClient,
+				logger:     microloggertest.New(),
+			}
+
+			apps, err := r.currentApps(context.Background(), cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(apps) != 1 {
+				t.Fatalf("expected 1 app, got %d", len(apps))
+			}
+
+			var stored v1alpha1.App
+			err = ctrlClient.Get(context.Background(), types.NamespacedName{Name: "eggs2-app-operator", Namespace: "org-test"}, &stored)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, a := range []*v1alpha1.App{apps[0], &stored} {
+				if !key.HasClusterOwnerReferences(cluster, a) {
+					t.Fatalf("expected owner references of the cluster, got %#v", a.OwnerReferences)
+				}
+				if len(a.OwnerReferences) != tc.expectedOwners {
+					t.Fatalf("expected %d owner references, got %#v", tc.expectedOwners, a.OwnerReferences)
+				}
+			}
+		})
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/giantswarm/operatorkit/v7/pkg/controller/context/finalizerskeptcontext"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
@@ -54,16 +53,18 @@ func (r Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 		for _, app := range apps {
 			appNames = append(appNames, app.Name)
 		}
-		r.logger.Debugf(ctx, "waiting for %d apps to be deleted for cluster '%s/%s': %s", len(apps), cr.GetNamespace(), key.ClusterID(&cr), strings.Join(appNames, ", "))
+		r.logger.Debugf(ctx, "waiting for %d apps to be deleted for cluster '%s/%s': %s", len(apps), cr.GetNamespace(), key.ClusterID(cr), strings.Join(appNames, ", "))
 		return r.cancel(ctx)
 	}
 
-	desiredApps := r.desiredApps(ctx, cr)
+	// Only the names of the desired apps are needed for their deletion, so
+	// the Release of the cluster is not looked up.
+	desiredApps := r.desiredApps(ctx, cr, r.flagOperatorVersions())
 
 	// There are no more app CRs to manage so we can delete chart-operator.
-	err = r.waitForAppDeletion(ctx, cr, key.ChartOperatorAppName(&cr), desiredApps)
+	err = r.waitForAppDeletion(ctx, cr, key.ChartOperatorAppName(cr), desiredApps)
 	if IsNotDeleted(err) {
-		r.logger.Debugf(ctx, "%s not deleted yet", key.ChartOperatorAppName(&cr))
+		r.logger.Debugf(ctx, "%s not deleted yet", key.ChartOperatorAppName(cr))
 
 		finalizerskeptcontext.SetKept(ctx)
 		r.logger.Debugf(ctx, "keeping finalizers")
@@ -76,9 +77,9 @@ func (r Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 
 	// Once chart-operator is deleted we can delete app-operator and remove
 	// the finalizer.
-	err = r.waitForAppDeletion(ctx, cr, fmt.Sprintf("%s-app-operator", key.ClusterID(&cr)), desiredApps)
+	err = r.waitForAppDeletion(ctx, cr, fmt.Sprintf("%s-app-operator", key.ClusterID(cr)), desiredApps)
 	if IsNotDeleted(err) {
-		r.logger.Debugf(ctx, "%s not deleted yet", key.AppOperatorAppName(&cr))
+		r.logger.Debugf(ctx, "%s not deleted yet", key.AppOperatorAppName(cr))
 
 		finalizerskeptcontext.SetKept(ctx)
 		r.logger.Debugf(ctx, "keeping finalizers")
@@ -89,6 +90,11 @@ func (r Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
 		return microerror.Mask(err)
 	}
 
+	// Both operator apps are gone so the cluster status is not needed
+	// anymore.
+	r.recorder.Delete(cr.GetNamespace(), cr.GetName())
+	deleteChartOperatorHeldBack(cr)
+
 	return nil
 }
 
@@ -138,13 +144,13 @@ func (r Resource) deleteClusterApps(ctx context.Context, apps []*v1alpha1.App) e
 
 // getClusterApps gets all the App CRs with matching selector, not managed
 // by the `cluster-apps-operator`.
-func (r Resource) getClusterApps(ctx context.Context, cr capi.Cluster) ([]*v1alpha1.App, error) {
+func (r Resource) getClusterApps(ctx context.Context, cr key.Cluster) ([]*v1alpha1.App, error) {
 	var apps []*v1alpha1.App
 
-	r.logger.Debugf(ctx, "finding apps for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(&cr))
+	r.logger.Debugf(ctx, "finding apps for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(cr))
 
 	// Get all apps for cluster not being managed by cluster-apps-operator.
-	selector, err := labels.Parse(fmt.Sprintf("%s=%s,%s!=%s", label.Cluster, key.ClusterID(&cr), label.ManagedBy, project.Name()))
+	selector, err := labels.Parse(fmt.Sprintf("%s=%s,%s!=%s", label.Cluster, key.ClusterID(cr), label.ManagedBy, project.Name()))
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -165,7 +171,7 @@ func (r Resource) getClusterApps(ctx context.Context, cr capi.Cluster) ([]*v1alp
 		apps = append(apps, item.DeepCopy())
 	}
 
-	r.logger.Debugf(ctx, "found %d app(s) for cluster '%s/%s'", len(apps), cr.GetNamespace(), key.ClusterID(&cr))
+	r.logger.Debugf(ctx, "found %d app(s) for cluster '%s/%s'", len(apps), cr.GetNamespace(), key.ClusterID(cr))
 
 	return apps, nil
 }
@@ -173,7 +179,7 @@ func (r Resource) getClusterApps(ctx context.Context, cr capi.Cluster) ([]*v1alp
 // waitForAppDeletion deletes the app CR and waits for a short period. If the
 // deletion takes longer we check again in the next resync period to allow
 // processing other clusters.
-func (r Resource) waitForAppDeletion(ctx context.Context, cr capi.Cluster, appName string, desiredApps []*v1alpha1.App) error {
+func (r Resource) waitForAppDeletion(ctx context.Context, cr key.Cluster, appName string, desiredApps []*v1alpha1.App) error {
 	var err error
 
 	app := findAppByName(desiredApps, appName, cr.GetNamespace())
@@ -12,10 +12,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake" //nolint:staticcheck
 
 	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
 )
 
 func Test_EnsureDeleted(t *testing.T) {
@@ -301,7 +303,9 @@ func Test_EnsureDeleted(t *testing.T) {
 					WithScheme(scheme).
 					WithRuntimeObjects(g8sObjs...).
 					Build()
+				tc.config.EventRecorder = record.NewFakeRecorder(10)
 				tc.config.Logger = microloggertest.New()
+				tc.config.Resolver = infrastructuretest.NewResolver(t, tc.config.CtrlClient)
 
 				resource, err = New(tc.config)
 				if err != nil {
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/golden"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+// Test_Golden reconciles every case in the shared golden test data and
+// compares the App CRs it applied against its apps.golden.yaml. Run it with
+// UPDATE_GOLDEN=1 to regenerate the golden files.
+func Test_Golden(t *testing.T) {
+	scheme := golden.NewScheme(t)
+
+	for _, tc := range golden.Cases(t, "../testdata/golden", scheme) {
+		t.Run(tc.Name, func(t *testing.T) {
+			objects := append([]runtime.Object{
+				newAppCatalogEntry("app-operator", "control-plane-catalog", "7.0.0"),
+				newAppCatalogEntry("chart-operator", "default", "4.0.0"),
+			}, tc.Objects...)
+
+			// The input objects carry their kinds, the typed App CRs and
+			// AppCatalogEntries created by the tests do not.
+			mapperObjects := append([]runtime.Object{
+				&v1alpha1.App{
+					TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "App"},
+					ObjectMeta: metav1.ObjectMeta{Namespace: "giantswarm"},
+				},
+				&v1alpha1.AppCatalogEntry{
+					TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "AppCatalogEntry"},
+					ObjectMeta: metav1.ObjectMeta{Namespace: "giantswarm"},
+				},
+			}, tc.Objects...)
+
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(infrastructuretest.NewRESTMapper(mapperObjects...)).
+				WithRuntimeObjects(objects...).
+				WithStatusSubresource(&capiv1beta1.Cluster{}, &capi.Cluster{}).
+				Build()
+
+			c := Config{
+				CtrlClient:    ctrlClient,
+				EventRecorder: record.NewFakeRecorder(10),
+				Logger:        microloggertest.New(),
+				Resolver:      infrastructuretest.NewResolver(t, ctrlClient),
+
+				AppOperatorCatalog:   "control-plane-catalog",
+				AppOperatorVersion:   "7.0.0",
+				ChartOperatorCatalog: "default",
+				ChartOperatorVersion: "4.0.0",
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = r.EnsureCreated(context.Background(), tc.Cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var apps v1alpha1.AppList
+			err = ctrlClient.List(context.Background(), &apps, client.InNamespace(tc.Cluster.GetNamespace()), client.MatchingLabels{label.ManagedBy: project.Name()})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var applied []interface{}
+			for i := range apps.Items {
+				applied = append(applied, &apps.Items[i])
+			}
+
+			golden.Assert(t, tc.Path("apps.golden.yaml"), applied...)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	PrometheusNamespace = "cluster_apps_operator"
+	PrometheusSubsystem = "app"
+)
+
+var (
+	unresolvableVersionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "unresolvable_version_total",
+			Help:      "Number of App CRs whose catalog does not exist or whose version has no AppCatalogEntry in their catalog.",
+		},
+		[]string{"app", "catalog"},
+	)
+	chartOperatorHeldBackGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "chart_operator_held_back",
+			Help:      "Clusters whose chart-operator is held back on an older version by the compatibility matrix.",
+		},
+		[]string{"namespace", "cluster_id", "kubernetes_version", "version"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(unresolvableVersionCounter)
+	prometheus.MustRegister(chartOperatorHeldBackGauge)
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/microerror"
+)
+
+// AnnotationLastAppliedMetadata is set on App CRs to the label and annotation
+// keys set by the operator. Only these keys are managed by the operator, so
+// labels and annotations added by app-operator, Flux or humans are kept, and
+// keys the operator set before but no longer wants are removed.
+const AnnotationLastAppliedMetadata = "cluster-apps-operator.giantswarm.io/last-applied-metadata"
+
+type appliedMetadata struct {
+	Annotations []string `json:"annotations,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// setLastAppliedMetadata records the label and annotation keys of the desired
+// App in AnnotationLastAppliedMetadata.
+func setLastAppliedMetadata(app *v1alpha1.App) error {
+	if app.Annotations == nil {
+		app.Annotations = map[string]string{}
+	}
+	delete(app.Annotations, AnnotationLastAppliedMetadata)
+
+	b, err := json.Marshal(appliedMetadata{
+		Annotations: sortedKeys(app.Annotations),
+		Labels:      sortedKeys(app.Labels),
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	app.Annotations[AnnotationLastAppliedMetadata] = string(b)
+
+	return nil
+}
+
+// lastAppliedMetadata returns the keys recorded on the App. Apps created
+// before the keys were recorded, or with a malformed record, have no keys to
+// remove.
+func lastAppliedMetadata(app *v1alpha1.App) appliedMetadata {
+	var applied appliedMetadata
+
+	err := json.Unmarshal([]byte(app.Annotations[AnnotationLastAppliedMetadata]), &applied)
+	if err != nil {
+		return appliedMetadata{}
+	}
+
+	return applied
+}
+
+// mergeOwned returns current with the desired keys set and the previously
+// applied keys which are no longer desired removed.
+func mergeOwned(current, desired map[string]string, previous []string) map[string]string {
+	merged := map[string]string{}
+	for k, v := range current {
+		merged[k] = v
+	}
+	for _, k := range previous {
+		if _, ok := desired[k]; !ok {
+			delete(merged, k)
+		}
+	}
+	for k, v := range desired {
+		merged[k] = v
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	return merged
+}
+
+// hasOwnedChanged checks whether any desired key differs from current or
+// any previously applied key which is no longer desired is still set.
+func hasOwnedChanged(current, desired map[string]string, previous []string) bool {
+	for k, v := range desired {
+		if cv, ok := current[k]; !ok || cv != v {
+			return true
+		}
+	}
+	for _, k := range previous {
+		if _, ok := desired[k]; ok {
+			continue
+		}
+		if _, ok := current[k]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_mergeOwned(t *testing.T) {
+	testCases := []struct {
+		name     string
+		current  map[string]string
+		desired  map[string]string
+		previous []string
+		expected map[string]string
+	}{
+		{
+			name:     "case 0: desired keys are set",
+			current:  map[string]string{"giantswarm.io/cluster": "eggs2"},
+			desired:  map[string]string{"giantswarm.io/cluster": "eggs2", "app-operator.giantswarm.io/version": "0.0.0"},
+			expected: map[string]string{"giantswarm.io/cluster": "eggs2", "app-operator.giantswarm.io/version": "0.0.0"},
+		},
+		{
+			name:     "case 1: keys of other controllers are kept",
+			current:  map[string]string{"giantswarm.io/cluster": "eggs2", "app-operator.giantswarm.io/paused": "true"},
+			desired:  map[string]string{"giantswarm.io/cluster": "eggs2"},
+			previous: []string{"giantswarm.io/cluster"},
+			expected: map[string]string{"giantswarm.io/cluster": "eggs2", "app-operator.giantswarm.io/paused": "true"},
+		},
+		{
+			name:     "case 2: previously applied keys no longer desired are removed",
+			current:  map[string]string{"giantswarm.io/cluster": "eggs2", "chart-operator.giantswarm.io/force-helm-upgrade": "true"},
+			desired:  map[string]string{"giantswarm.io/cluster": "eggs2"},
+			previous: []string{"chart-operator.giantswarm.io/force-helm-upgrade", "giantswarm.io/cluster"},
+			expected: map[string]string{"giantswarm.io/cluster": "eggs2"},
+		},
+		{
+			name:     "case 3: desired values override current values",
+			current:  map[string]string{"giantswarm.io/cluster": "eggs1"},
+			desired:  map[string]string{"giantswarm.io/cluster": "eggs2"},
+			previous: []string{"giantswarm.io/cluster"},
+			expected: map[string]string{"giantswarm.io/cluster": "eggs2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged := mergeOwned(tc.current, tc.desired, tc.previous)
+			if !reflect.DeepEqual(merged, tc.expected) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, merged)
+			}
+			if hasOwnedChanged(merged, tc.desired, tc.previous) {
+				t.Fatalf("expected merged keys to be unchanged")
+			}
+		})
+	}
+}
+
+func Test_setLastAppliedMetadata(t *testing.T) {
+	app := &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"chart-operator.giantswarm.io/force-helm-upgrade": "false",
+			},
+			Labels: map[string]string{
+				"giantswarm.io/cluster":    "eggs2",
+				"giantswarm.io/managed-by": "cluster-apps-operator",
+			},
+		},
+	}
+
+	err := setLastAppliedMetadata(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := appliedMetadata{
+		Annotations: []string{"chart-operator.giantswarm.io/force-helm-upgrade"},
+		Labels:      []string{"giantswarm.io/cluster", "giantswarm.io/managed-by"},
+	}
+	if applied := lastAppliedMetadata(app); !reflect.DeepEqual(applied, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, applied)
+	}
+}
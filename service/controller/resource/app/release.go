@@ -0,0 +1,208 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+const (
+	// ReleaseResolvedCondition is set on Clusters labelled with a release
+	// version. It is false when the referenced Release does not exist, in
+	// which case the operator versions are taken from the flags.
+	ReleaseResolvedCondition = "ReleaseResolved"
+
+	ReleaseFoundReason    = "ReleaseFound"
+	ReleaseNotFoundReason = "ReleaseNotFound"
+)
+
+// releaseProviders are the provider prefixes of the Release names by
+// infrastructure cluster kind.
+var releaseProviders = map[string]string{
+	infra.AWSClusterKind:             "aws",
+	infra.AWSManagedClusterKind:      "eks",
+	infra.AzureClusterKind:           "azure",
+	infra.AzureManagedClusterKind:    "aks",
+	infra.AzureASOManagedClusterKind: "aks",
+	infra.GCPClusterKind:             "gcp",
+	infra.GCPManagedClusterKind:      "gke",
+	infra.ProxmoxClusterKind:         "proxmox",
+	infra.VCDClusterKind:             "cloud-director",
+	infra.VSphereClusterKind:         "vsphere",
+}
+
+var releaseGVK = schema.GroupVersionKind{
+	Group:   "release.giantswarm.io",
+	Version: "v1alpha1",
+	Kind:    "Release",
+}
+
+// operatorVersions are the catalogs and versions of the operator apps
+// installed for a cluster.
+type operatorVersions struct {
+	AppOperatorCatalog   string
+	AppOperatorVersion   string
+	ChartOperatorCatalog string
+	ChartOperatorVersion string
+}
+
+// releaseComponent is an entry of the component list of a Release.
+type releaseComponent struct {
+	Catalog string
+	Version string
+}
+
+func (r *Resource) flagOperatorVersions() operatorVersions {
+	return operatorVersions{
+		AppOperatorCatalog:   r.appOperatorCatalog,
+		AppOperatorVersion:   r.appOperatorVersion,
+		ChartOperatorCatalog: r.chartOperatorCatalog,
+		ChartOperatorVersion: r.chartOperatorVersion,
+	}
+}
+
+// operatorVersions returns the operator versions for the cluster. They are
+// taken from the components of the Release named by the release version
+// label of the cluster. Components the Release does not list default to the
+// flags, the chart-operator version is then selected from the compatibility
+// matrix if one matches the Kubernetes version of the cluster. The returned
+// ReleaseResolvedCondition is nil if the cluster has no release version
+// label. It is written by updateClusterCondition once the App CRs are
+// applied.
+func (r *Resource) operatorVersions(ctx context.Context, cr key.Cluster) (operatorVersions, *metav1.Condition, error) {
+	versions := r.flagOperatorVersions()
+
+	components, condition, err := r.clusterReleaseComponents(ctx, cr)
+	if err != nil {
+		return operatorVersions{}, nil, microerror.Mask(err)
+	}
+
+	if c, ok := components["app-operator"]; ok {
+		versions.AppOperatorVersion = c.Version
+		if c.Catalog != "" {
+			versions.AppOperatorCatalog = c.Catalog
+		}
+	}
+	if c, ok := components["chart-operator"]; ok {
+		versions.ChartOperatorVersion = c.Version
+		if c.Catalog != "" {
+			versions.ChartOperatorCatalog = c.Catalog
+		}
+	} else {
+		versions, err = r.compatibleChartOperator(ctx, cr, versions)
+		if err != nil {
+			return operatorVersions{}, nil, microerror.Mask(err)
+		}
+	}
+
+	return versions, condition, nil
+}
+
+// clusterReleaseComponents returns the components of the Release named by the
+// release version label of the cluster, or nil if the cluster has no such
+// label or the Release does not exist. The returned ReleaseResolvedCondition
+// reports whether the Release exists.
+func (r *Resource) clusterReleaseComponents(ctx context.Context, cr key.Cluster) (map[string]releaseComponent, *metav1.Condition, error) {
+	names := releaseNames(cr)
+	if len(names) == 0 {
+		return nil, nil, nil
+	}
+
+	for _, name := range names {
+		components, err := r.releaseComponents(ctx, name)
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return nil, nil, microerror.Mask(err)
+		}
+
+		condition := &metav1.Condition{
+			Type:    ReleaseResolvedCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  ReleaseFoundReason,
+			Message: fmt.Sprintf("Operator versions are taken from Release %#q", name),
+		}
+
+		return components, condition, nil
+	}
+
+	r.logger.Debugf(ctx, "release %#q not found, using operator versions from flags", names[0])
+
+	condition := &metav1.Condition{
+		Type:    ReleaseResolvedCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReleaseNotFoundReason,
+		Message: fmt.Sprintf("Release %#q does not exist, operator versions are taken from the cluster-apps-operator configuration", names[0]),
+	}
+
+	return nil, condition, nil
+}
+
+// releaseNames returns the names the Release of the cluster may have, in the
+// order they are looked up. Releases are named <provider>-<version> after the
+// release version label, the bare version is looked up as well for Releases
+// of providers without such a prefix.
+func releaseNames(cr key.Cluster) []string {
+	version := key.ReleaseVersion(cr)
+	if version == "" {
+		return nil
+	}
+
+	var names []string
+	if ref := key.InfrastructureRef(cr); ref != nil {
+		if provider, ok := releaseProviders[ref.Kind]; ok {
+			names = append(names, fmt.Sprintf("%s-%s", provider, version))
+		}
+	}
+
+	return append(names, version)
+}
+
+// releaseComponents returns the components of the named Release with a
+// version by name.
+func (r *Resource) releaseComponents(ctx context.Context, name string) (map[string]releaseComponent, error) {
+	release := &unstructured.Unstructured{}
+	release.SetGroupVersionKind(releaseGVK)
+
+	err := r.ctrlClient.Get(ctx, client.ObjectKey{Name: name}, release)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	list, _, err := unstructured.NestedSlice(release.Object, "spec", "components")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	components := map[string]releaseComponent{}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		componentName, _, _ := unstructured.NestedString(m, "name")
+		version, _, _ := unstructured.NestedString(m, "version")
+		catalog, _, _ := unstructured.NestedString(m, "catalog")
+		if componentName == "" || version == "" {
+			continue
+		}
+
+		components[componentName] = releaseComponent{
+			Catalog: catalog,
+			Version: version,
+		}
+	}
+
+	return components, nil
+}
@@ -0,0 +1,377 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+func Test_operatorVersions(t *testing.T) {
+	flagVersions := operatorVersions{
+		AppOperatorCatalog:   "control-plane-catalog",
+		AppOperatorVersion:   "1.0.0",
+		ChartOperatorCatalog: "default",
+		ChartOperatorVersion: "2.0.0",
+	}
+
+	testCases := []struct {
+		name              string
+		cluster           key.Cluster
+		release           *unstructured.Unstructured
+		expectedVersions  operatorVersions
+		expectedCondition *metav1.Condition
+	}{
+		{
+			name:             "case 0: cluster without release version uses flags",
+			cluster:          newReleaseTestCluster(""),
+			expectedVersions: flagVersions,
+		},
+		{
+			name:    "case 1: versions and catalogs taken from release",
+			cluster: newReleaseTestCluster("25.0.0"),
+			release: newRelease("25.0.0",
+				map[string]interface{}{"name": "app-operator", "version": "7.1.0", "catalog": "control-plane-test-catalog"},
+				map[string]interface{}{"name": "chart-operator", "version": "4.2.0", "catalog": "default-test"},
+			),
+			expectedVersions: operatorVersions{
+				AppOperatorCatalog:   "control-plane-test-catalog",
+				AppOperatorVersion:   "7.1.0",
+				ChartOperatorCatalog: "default-test",
+				ChartOperatorVersion: "4.2.0",
+			},
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: ReleaseFoundReason,
+			},
+		},
+		{
+			name:    "case 2: components missing from release use flags",
+			cluster: newReleaseTestCluster("25.0.0"),
+			release: newRelease("25.0.0",
+				map[string]interface{}{"name": "app-operator", "version": "7.1.0"},
+				map[string]interface{}{"name": "cluster-operator", "version": "5.0.0"},
+			),
+			expectedVersions: operatorVersions{
+				AppOperatorCatalog:   "control-plane-catalog",
+				AppOperatorVersion:   "7.1.0",
+				ChartOperatorCatalog: "default",
+				ChartOperatorVersion: "2.0.0",
+			},
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: ReleaseFoundReason,
+			},
+		},
+		{
+			name:             "case 3: missing release uses flags and reports condition",
+			cluster:          newReleaseTestCluster("25.0.0"),
+			release:          newRelease("24.0.0"),
+			expectedVersions: flagVersions,
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionFalse,
+				Reason: ReleaseNotFoundReason,
+			},
+		},
+		{
+			name: "case 4: missing release reported on v1beta1 cluster",
+			cluster: &capiv1beta1.Cluster{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: capiv1beta1.GroupVersion.String(),
+					Kind:       "Cluster",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "demo0",
+					Namespace: "org-acme",
+					Labels: map[string]string{
+						label.Cluster:        "demo0",
+						label.ReleaseVersion: "25.0.0",
+					},
+				},
+			},
+			release:          newRelease("24.0.0"),
+			expectedVersions: flagVersions,
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionFalse,
+				Reason: ReleaseNotFoundReason,
+			},
+		},
+		{
+			name:    "case 5: release named after the provider of the cluster",
+			cluster: newProviderReleaseTestCluster("AWSCluster", "25.0.0"),
+			release: newRelease("aws-25.0.0",
+				map[string]interface{}{"name": "app-operator", "version": "7.1.0"},
+			),
+			expectedVersions: operatorVersions{
+				AppOperatorCatalog:   "control-plane-catalog",
+				AppOperatorVersion:   "7.1.0",
+				ChartOperatorCatalog: "default",
+				ChartOperatorVersion: "2.0.0",
+			},
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: ReleaseFoundReason,
+			},
+		},
+		{
+			name:             "case 6: release of another provider is not used",
+			cluster:          newProviderReleaseTestCluster("AWSCluster", "25.0.0"),
+			release:          newRelease("azure-25.0.0", map[string]interface{}{"name": "app-operator", "version": "7.1.0"}),
+			expectedVersions: flagVersions,
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionFalse,
+				Reason: ReleaseNotFoundReason,
+			},
+		},
+		{
+			name:    "case 7: release named after the EKS provider of the cluster",
+			cluster: newProviderReleaseTestCluster("AWSManagedCluster", "25.0.0"),
+			release: newRelease("eks-25.0.0",
+				map[string]interface{}{"name": "app-operator", "version": "7.1.0"},
+			),
+			expectedVersions: operatorVersions{
+				AppOperatorCatalog:   "control-plane-catalog",
+				AppOperatorVersion:   "7.1.0",
+				ChartOperatorCatalog: "default",
+				ChartOperatorVersion: "2.0.0",
+			},
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: ReleaseFoundReason,
+			},
+		},
+		{
+			name:    "case 8: release named after the AKS provider of the cluster",
+			cluster: newProviderReleaseTestCluster("AzureManagedCluster", "25.0.0"),
+			release: newRelease("aks-25.0.0",
+				map[string]interface{}{"name": "app-operator", "version": "7.1.0"},
+			),
+			expectedVersions: operatorVersions{
+				AppOperatorCatalog:   "control-plane-catalog",
+				AppOperatorVersion:   "7.1.0",
+				ChartOperatorCatalog: "default",
+				ChartOperatorVersion: "2.0.0",
+			},
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: ReleaseFoundReason,
+			},
+		},
+		{
+			name:    "case 9: release named after the GCP provider of the cluster",
+			cluster: newProviderReleaseTestCluster("GCPCluster", "25.0.0"),
+			release: newRelease("gcp-25.0.0",
+				map[string]interface{}{"name": "app-operator", "version": "7.1.0"},
+			),
+			expectedVersions: operatorVersions{
+				AppOperatorCatalog:   "control-plane-catalog",
+				AppOperatorVersion:   "7.1.0",
+				ChartOperatorCatalog: "default",
+				ChartOperatorVersion: "2.0.0",
+			},
+			expectedCondition: &metav1.Condition{
+				Type:   ReleaseResolvedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: ReleaseFoundReason,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			for _, addToScheme := range []func(*runtime.Scheme) error{capiv1beta1.AddToScheme, capi.AddToScheme} {
+				err := addToScheme(scheme)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			objects := []runtime.Object{tc.cluster}
+			if tc.release != nil {
+				objects = append(objects, tc.release)
+			}
+
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(infrastructuretest.NewRESTMapper(objects...)).
+				WithRuntimeObjects(objects...).
+				WithStatusSubresource(&capiv1beta1.Cluster{}, &capi.Cluster{}).
+				Build()
+
+			r, err := New(Config{
+				CtrlClient:    ctrlClient,
+				EventRecorder: record.NewFakeRecorder(10),
+				Logger:        microloggertest.New(),
+				Resolver:      infrastructuretest.NewResolver(t, ctrlClient),
+
+				AppOperatorCatalog:   flagVersions.AppOperatorCatalog,
+				AppOperatorVersion:   flagVersions.AppOperatorVersion,
+				ChartOperatorCatalog: flagVersions.ChartOperatorCatalog,
+				ChartOperatorVersion: flagVersions.ChartOperatorVersion,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			versions, condition, err := r.operatorVersions(context.Background(), tc.cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if versions != tc.expectedVersions {
+				t.Fatalf("expected versions %#v, got %#v", tc.expectedVersions, versions)
+			}
+
+			r.updateClusterCondition(context.Background(), tc.cluster, ReleaseResolvedCondition, condition)
+
+			cluster := tc.cluster.DeepCopyObject().(key.Cluster)
+			err = ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			condition = key.ClusterCondition(cluster, ReleaseResolvedCondition)
+			if tc.expectedCondition == nil {
+				if condition != nil {
+					t.Fatalf("expected no condition, got %#v", condition)
+				}
+				return
+			}
+			if condition == nil {
+				t.Fatalf("expected condition %#v, got none", tc.expectedCondition)
+			}
+			if condition.Status != tc.expectedCondition.Status || condition.Reason != tc.expectedCondition.Reason {
+				t.Fatalf("expected condition %s/%s, got %s/%s", tc.expectedCondition.Status, tc.expectedCondition.Reason, condition.Status, condition.Reason)
+			}
+		})
+	}
+}
+
+func newReleaseTestCluster(releaseVersion string) *capi.Cluster {
+	cluster := &capi.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: capi.GroupVersion.String(),
+			Kind:       "Cluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo0",
+			Namespace: "org-acme",
+			Labels: map[string]string{
+				label.Cluster: "demo0",
+			},
+		},
+	}
+	if releaseVersion != "" {
+		cluster.Labels[label.ReleaseVersion] = releaseVersion
+	}
+
+	return cluster
+}
+
+func newProviderReleaseTestCluster(infrastructureKind, releaseVersion string) *capi.Cluster {
+	cluster := newReleaseTestCluster(releaseVersion)
+	cluster.Spec.InfrastructureRef = capi.ContractVersionedObjectReference{
+		APIGroup: "infrastructure.cluster.x-k8s.io",
+		Kind:     infrastructureKind,
+		Name:     cluster.Name,
+	}
+
+	return cluster
+}
+
+func Test_updateClusterCondition_StaleCluster(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	err := capi.AddToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctrlClient := clientfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRuntimeObjects(newReleaseTestCluster("25.0.0")).
+		WithStatusSubresource(&capi.Cluster{}).
+		Build()
+
+	r, err := New(Config{
+		CtrlClient:    ctrlClient,
+		EventRecorder: record.NewFakeRecorder(10),
+		Logger:        microloggertest.New(),
+		Resolver:      infrastructuretest.NewResolver(t, ctrlClient),
+
+		AppOperatorCatalog:   "control-plane-catalog",
+		AppOperatorVersion:   "1.0.0",
+		ChartOperatorCatalog: "default",
+		ChartOperatorVersion: "2.0.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale := &capi.Cluster{}
+	err = ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "demo0"}, stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another controller writes the cluster after it was read for the
+	// reconciliation.
+	updated := stale.DeepCopy()
+	updated.Labels["other"] = "controller"
+	err = ctrlClient.Update(ctx, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.updateClusterCondition(ctx, stale, ReleaseResolvedCondition, &metav1.Condition{
+		Type:   ReleaseResolvedCondition,
+		Status: metav1.ConditionTrue,
+		Reason: ReleaseFoundReason,
+	})
+
+	cluster := &capi.Cluster{}
+	err = ctrlClient.Get(ctx, client.ObjectKeyFromObject(stale), cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	condition := key.ClusterCondition(cluster, ReleaseResolvedCondition)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected condition %s to be true, got %#v", ReleaseResolvedCondition, condition)
+	}
+}
+
+func newRelease(name string, components ...interface{}) *unstructured.Unstructured {
+	release := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"components": components,
+			},
+		},
+	}
+	release.SetGroupVersionKind(releaseGVK)
+	release.SetName(name)
+
+	return release
+}
@@ -4,7 +4,12 @@ import (
 	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/compatibility"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
 )
 
 const (
@@ -16,8 +21,15 @@ const (
 
 // Config represents the configuration used to create a new app resource.
 type Config struct {
-	CtrlClient client.Client
-	Logger     micrologger.Logger
+	CtrlClient    client.Client
+	EventRecorder record.EventRecorder
+	Logger        micrologger.Logger
+	Recorder      *clusterstatus.Recorder
+	Resolver      *infra.Resolver
+	// ChartOperatorCompatibility is optional. When set, the chart-operator
+	// version of clusters without Release is selected by their Kubernetes
+	// version.
+	ChartOperatorCompatibility *compatibility.Matrix
 
 	AppOperatorCatalog   string
 	AppOperatorVersion   string
@@ -27,8 +39,13 @@ type Config struct {
 
 // Resource implements the app resource.
 type Resource struct {
-	ctrlClient client.Client
-	logger     micrologger.Logger
+	ctrlClient    client.Client
+	eventRecorder record.EventRecorder
+	logger        micrologger.Logger
+	recorder      *clusterstatus.Recorder
+	resolver      *infra.Resolver
+
+	chartOperatorCompatibility *compatibility.Matrix
 
 	appOperatorCatalog   string
 	appOperatorVersion   string
@@ -44,9 +61,15 @@ func New(config Config) (*Resource, error) {
 	if config.CtrlClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
 	}
+	if config.EventRecorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.EventRecorder must not be empty", config)
+	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.Resolver == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Resolver must not be empty", config)
+	}
 
 	if config.AppOperatorCatalog == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.AppOperatorCatalog must not be empty", config)
@@ -62,8 +85,13 @@ func New(config Config) (*Resource, error) {
 	}
 
 	r := &Resource{
-		ctrlClient: config.CtrlClient,
-		logger:     config.Logger,
+		ctrlClient:    config.CtrlClient,
+		eventRecorder: config.EventRecorder,
+		logger:        config.Logger,
+		recorder:      config.Recorder,
+		resolver:      config.Resolver,
+
+		chartOperatorCompatibility: config.ChartOperatorCompatibility,
 
 		appOperatorCatalog:   config.AppOperatorCatalog,
 		appOperatorVersion:   config.AppOperatorVersion,
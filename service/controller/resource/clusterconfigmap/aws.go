@@ -0,0 +1,119 @@
+package clusterconfigmap
+
+import (
+	"context"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+const (
+	awsClusterControllerIdentityKind = "AWSClusterControllerIdentity"
+	awsClusterRoleIdentityKind       = "AWSClusterRoleIdentity"
+)
+
+// awsConfig extracts region, account, VPC and subnets of the given AWS
+// cluster from its infrastructure objects. EKS clusters keep them on the
+// AWSManagedControlPlane.
+func (r *Resource) awsConfig(ctx context.Context, cr key.Cluster) (*AWSConfig, error) {
+	infrastructureRef := key.InfrastructureRef(cr)
+
+	var ref *key.ObjectReference
+	switch infrastructureRef.Kind {
+	case infra.AWSClusterKind:
+		ref = infrastructureRef
+	case infra.AWSManagedClusterKind:
+		ref = key.ControlPlaneRef(cr)
+		if ref == nil {
+			return &AWSConfig{}, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	obj, err := r.resolver.Get(ctx, cr, ref.GroupVersionKind(), ref.Namespace, ref.Name)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	config := &AWSConfig{
+		Region: nestedString(obj, "spec", "region"),
+		VPC: AWSVPCConfig{
+			CIDR: nestedString(obj, "spec", "network", "vpc", "cidrBlock"),
+			ID:   nestedString(obj, "spec", "network", "vpc", "id"),
+		},
+		Subnets: []AWSSubnetConfig{},
+	}
+
+	subnets, _, _ := unstructured.NestedSlice(obj.Object, "spec", "network", "subnets")
+	for _, s := range subnets {
+		m, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: m}
+
+		// CAPA stores the AWS subnet ID in resourceID and only falls back
+		// to id for unmanaged subnets.
+		id := nestedString(u, "resourceID")
+		if id == "" {
+			id = nestedString(u, "id")
+		}
+		public, _, _ := unstructured.NestedBool(m, "isPublic")
+
+		config.Subnets = append(config.Subnets, AWSSubnetConfig{
+			AvailabilityZone: nestedString(u, "availabilityZone"),
+			CIDR:             nestedString(u, "cidrBlock"),
+			ID:               id,
+			Public:           public,
+		})
+	}
+
+	config.AccountID, err = r.awsAccountID(ctx, cr, obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return config, nil
+}
+
+// awsAccountID returns the account of the AWSClusterRoleIdentity referenced
+// by the given CAPA object, or an empty string when it cannot be derived.
+// Without identity CAPA uses the AWSClusterControllerIdentity, so the
+// cluster lives in the account of the management cluster.
+func (r *Resource) awsAccountID(ctx context.Context, cr key.Cluster, owner *unstructured.Unstructured) (string, error) {
+	kind := nestedString(owner, "spec", "identityRef", "kind")
+	name := nestedString(owner, "spec", "identityRef", "name")
+	if kind == "" || kind == awsClusterControllerIdentityKind {
+		return r.awsManagementAccountID, nil
+	}
+	if kind != awsClusterRoleIdentityKind || name == "" {
+		return "", nil
+	}
+
+	identity, err := r.resolver.Get(ctx, cr, schema.GroupVersionKind{Group: infra.Group, Kind: kind}, "", name)
+	if infra.IsNotFound(err) {
+		r.logger.Debugf(ctx, "%s %#q not found, cannot get account", kind, name)
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return accountFromARN(nestedString(identity, "spec", "roleARN")), nil
+}
+
+// accountFromARN returns the account of an ARN like
+// arn:aws:iam::123456789012:role/name.
+func accountFromARN(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+
+	return parts[4]
+}
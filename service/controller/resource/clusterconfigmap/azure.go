@@ -0,0 +1,195 @@
+package clusterconfigmap
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+const (
+	azureClusterIdentityKind = "AzureClusterIdentity"
+
+	// asoCredentialFromAnnotation references the secret holding the
+	// credentials Azure Service Operator uses for a resource.
+	asoCredentialFromAnnotation = "serviceoperator.azure.com/credential-from"
+	asoSubscriptionIDKey        = "AZURE_SUBSCRIPTION_ID"
+	asoTenantIDKey              = "AZURE_TENANT_ID"
+)
+
+// azureConfig extracts location, resource group, VNet, subscription and
+// tenant of the given Azure cluster from its infrastructure objects.
+func (r *Resource) azureConfig(ctx context.Context, cr key.Cluster) (*AzureConfig, error) {
+	infrastructureRef := key.InfrastructureRef(cr)
+
+	switch infrastructureRef.Kind {
+	case infra.AzureClusterKind:
+		azureCluster, err := r.resolver.Get(ctx, cr, infrastructureRef.GroupVersionKind(), infrastructureRef.Namespace, infrastructureRef.Name)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		subscriptionID, found, err := unstructured.NestedString(azureCluster.Object, "spec", "subscriptionID")
+		if err != nil || !found {
+			return nil, microerror.Mask(fieldNotFoundOnInfrastructureTypeError)
+		}
+
+		config := &AzureConfig{
+			Location:       nestedString(azureCluster, "spec", "location"),
+			ResourceGroup:  nestedString(azureCluster, "spec", "resourceGroup"),
+			SubscriptionID: subscriptionID,
+			VNet: AzureVNetConfig{
+				CIDR:          firstNestedString(azureCluster, "spec", "networkSpec", "vnet", "cidrBlocks"),
+				Name:          nestedString(azureCluster, "spec", "networkSpec", "vnet", "name"),
+				ResourceGroup: nestedString(azureCluster, "spec", "networkSpec", "vnet", "resourceGroup"),
+			},
+		}
+
+		config.TenantID, err = r.azureTenantID(ctx, cr, azureCluster)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return withVNetDefaults(config), nil
+	case infra.AzureManagedClusterKind:
+		// AKS clusters managed by CAPZ keep their configuration on the
+		// AzureManagedControlPlane.
+		controlPlaneRef := key.ControlPlaneRef(cr)
+		if controlPlaneRef == nil {
+			return &AzureConfig{}, nil
+		}
+
+		controlPlane, err := r.resolver.Get(ctx, cr, controlPlaneRef.GroupVersionKind(), controlPlaneRef.Namespace, controlPlaneRef.Name)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		config := &AzureConfig{
+			Location:       nestedString(controlPlane, "spec", "location"),
+			ResourceGroup:  nestedString(controlPlane, "spec", "resourceGroupName"),
+			SubscriptionID: nestedString(controlPlane, "spec", "subscriptionID"),
+			VNet: AzureVNetConfig{
+				CIDR:          nestedString(controlPlane, "spec", "virtualNetwork", "cidrBlock"),
+				Name:          nestedString(controlPlane, "spec", "virtualNetwork", "name"),
+				ResourceGroup: nestedString(controlPlane, "spec", "virtualNetwork", "resourceGroup"),
+			},
+		}
+
+		config.TenantID, err = r.azureTenantID(ctx, cr, controlPlane)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return withVNetDefaults(config), nil
+	case infra.AzureASOManagedClusterKind:
+		asoCluster, err := r.resolver.Get(ctx, cr, infrastructureRef.GroupVersionKind(), infrastructureRef.Namespace, infrastructureRef.Name)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		resources, _, _ := unstructured.NestedSlice(asoCluster.Object, "spec", "resources")
+
+		config := &AzureConfig{}
+		var credentialFrom string
+
+		for _, res := range resources {
+			m, ok := res.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			u := &unstructured.Unstructured{Object: m}
+
+			switch u.GetKind() {
+			case "ResourceGroup":
+				config.ResourceGroup = asoAzureName(u)
+				config.Location = nestedString(u, "spec", "location")
+				credentialFrom = u.GetAnnotations()[asoCredentialFromAnnotation]
+			case "VirtualNetwork":
+				config.VNet.Name = asoAzureName(u)
+				config.VNet.CIDR = firstNestedString(u, "spec", "addressSpace", "addressPrefixes")
+			}
+		}
+
+		if credentialFrom != "" {
+			var secret corev1.Secret
+			err = r.k8sClient.CtrlClient().Get(ctx, client.ObjectKey{Namespace: cr.GetNamespace(), Name: credentialFrom}, &secret)
+			if apierrors.IsNotFound(err) {
+				r.logger.Debugf(ctx, "ASO credential secret '%s/%s' not found, cannot get subscription and tenant", cr.GetNamespace(), credentialFrom)
+			} else if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			config.SubscriptionID = string(secret.Data[asoSubscriptionIDKey])
+			config.TenantID = string(secret.Data[asoTenantIDKey])
+		}
+
+		return withVNetDefaults(config), nil
+	}
+
+	return nil, nil
+}
+
+// azureTenantID returns the tenant of the AzureClusterIdentity referenced by
+// the given CAPZ object, or an empty string when there is none.
+func (r *Resource) azureTenantID(ctx context.Context, cr key.Cluster, owner *unstructured.Unstructured) (string, error) {
+	name := nestedString(owner, "spec", "identityRef", "name")
+	if name == "" {
+		return "", nil
+	}
+
+	namespace := nestedString(owner, "spec", "identityRef", "namespace")
+	if namespace == "" {
+		namespace = owner.GetNamespace()
+	}
+
+	identity, err := r.resolver.Get(ctx, cr, schema.GroupVersionKind{Group: infra.Group, Kind: azureClusterIdentityKind}, namespace, name)
+	if infra.IsNotFound(err) {
+		r.logger.Debugf(ctx, "%s '%s/%s' not found, cannot get tenant", azureClusterIdentityKind, namespace, name)
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return nestedString(identity, "spec", "tenantID"), nil
+}
+
+// withVNetDefaults defaults the VNet resource group to the one of the
+// cluster, as CAPZ does.
+func withVNetDefaults(config *AzureConfig) *AzureConfig {
+	if config.VNet.ResourceGroup == "" && config.VNet.Name != "" {
+		config.VNet.ResourceGroup = config.ResourceGroup
+	}
+
+	return config
+}
+
+// asoAzureName returns the Azure name of an ASO resource, which defaults to
+// its Kubernetes name.
+func asoAzureName(u *unstructured.Unstructured) string {
+	if name := nestedString(u, "spec", "azureName"); name != "" {
+		return name
+	}
+
+	return u.GetName()
+}
+
+func nestedString(u *unstructured.Unstructured, fields ...string) string {
+	s, _, _ := unstructured.NestedString(u.Object, fields...)
+	return s
+}
+
+func firstNestedString(u *unstructured.Unstructured, fields ...string) string {
+	s, _, _ := unstructured.NestedStringSlice(u.Object, fields...)
+	if len(s) == 0 {
+		return ""
+	}
+
+	return s[0]
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
@@ -20,6 +20,10 @@ import (
 
 	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/cni"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/controlplane"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
 	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/podcidr"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/privatecluster"
@@ -33,16 +37,16 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 
 	var configMaps []*corev1.ConfigMap
 
-	if key.IsDeleted(&cr) {
-		r.logger.Debugf(ctx, "deleting cluster configmaps for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(&cr))
+	if key.IsDeleted(cr) {
+		r.logger.Debugf(ctx, "deleting cluster configmaps for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(cr))
 		return configMaps, nil
 	}
 
 	var podCIDR string
 	{
-		podCIDR, err = r.podCIDR.PodCIDR(ctx, &cr)
+		podCIDR, err = r.podCIDR.PodCIDR(ctx, cr)
 		if podcidr.IsNotFound(err) {
-			r.logger.Debugf(ctx, "pod cidr not available yet for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(&cr))
+			r.logger.Debugf(ctx, "pod cidr not available yet for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(cr))
 			r.logger.Debugf(ctx, "canceling resource")
 			resourcecanceledcontext.SetCanceled(ctx)
 			return nil, nil
@@ -55,13 +59,13 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 	{
 		var secret corev1.Secret
 		err = r.k8sClient.CtrlClient().Get(ctx, client.ObjectKey{
-			Namespace: cr.Namespace,
-			Name:      key.ClusterCAName(&cr),
+			Namespace: cr.GetNamespace(),
+			Name:      key.ClusterCAName(cr),
 		}, &secret)
 		if apierrors.IsNotFound(err) {
 			// During cluster creation there may be a delay until the
 			// ca is created.
-			r.logger.Debugf(ctx, "secret '%s/%s' not found, cannot get cluster CA", cr.Namespace, key.ClusterCAName(&cr))
+			r.logger.Debugf(ctx, "secret '%s/%s' not found, cannot get cluster CA", cr.GetNamespace(), key.ClusterCAName(cr))
 		} else if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -85,51 +89,48 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 		}
 	}
 
+	clusterDomain := key.ClusterDomain(cr, r.clusterDomain)
+
 	var (
 		provider = ""
+		// awsConfig is only used on aws.
+		awsConfig *AWSConfig
+		// azureConfig is only used on azure.
+		azureConfig *AzureConfig
 		// clusterCIDR is only used on azure.
 		clusterCIDR = ""
 		// gcpProject is only used on gcp.
 		gcpProject      = ""
 		gcpProjectFound bool
 
-		azureSubscriptionID      = ""
-		azureSubscriptionIDFound bool
+		azureSubscriptionID = ""
 	)
-	privateCluster, err := privatecluster.IsPrivateCluster(ctx, r.logger, r.k8sClient.CtrlClient(), cr)
+	privateCluster, err := privatecluster.IsPrivateCluster(ctx, r.logger, r.resolver, cr)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	{
-		infrastructureRef := cr.Spec.InfrastructureRef
+		infrastructureRef := key.InfrastructureRef(cr)
 		if infrastructureRef != nil {
 			switch infrastructureRef.Kind {
-			case infra.AzureManagedClusterKind, infra.AzureASOManagedClusterKind:
-				provider = infra.AzureClusterKindProvider
-			case infra.AzureClusterKind:
+			case infra.AzureClusterKind, infra.AzureManagedClusterKind, infra.AzureASOManagedClusterKind:
 				provider = infra.AzureClusterKindProvider
 
-				capzCluster := &unstructured.Unstructured{}
-				capzCluster.SetGroupVersionKind(schema.GroupVersionKind{
-					Group:   infrastructureRef.GroupVersionKind().Group,
-					Kind:    infrastructureRef.Kind,
-					Version: infrastructureRef.GroupVersionKind().Version,
-				})
-				err = r.k8sClient.CtrlClient().Get(ctx, client.ObjectKey{
-					Namespace: cr.Namespace,
-					Name:      infrastructureRef.Name,
-				}, capzCluster)
+				azureConfig, err = r.azureConfig(ctx, cr)
 				if err != nil {
 					return nil, microerror.Mask(err)
 				}
 
-				azureSubscriptionID, azureSubscriptionIDFound, err = unstructured.NestedString(capzCluster.Object, []string{"spec", "subscriptionID"}...)
-				if err != nil || !azureSubscriptionIDFound {
-					return nil, fieldNotFoundOnInfrastructureTypeError
-				}
+				azureSubscriptionID = azureConfig.SubscriptionID
+				clusterCIDR = azureConfig.VNet.CIDR
 			case infra.AWSClusterKind, infra.AWSManagedClusterKind:
 				provider = infra.AWSClusterKindProvider
+
+				awsConfig, err = r.awsConfig(ctx, cr)
+				if err != nil {
+					return nil, microerror.Mask(err)
+				}
 			case infra.VCDClusterKind:
 				provider = infra.VCDClusterKindProvider
 				privateCluster = !reflect.ValueOf(r.proxy).IsZero()
@@ -139,16 +140,7 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 			case infra.GCPClusterKind, infra.GCPManagedClusterKind:
 				provider = infra.GCPClusterKindProvider
 
-				gcpCluster := &unstructured.Unstructured{}
-				gcpCluster.SetGroupVersionKind(schema.GroupVersionKind{
-					Group:   infrastructureRef.GroupVersionKind().Group,
-					Kind:    infrastructureRef.Kind,
-					Version: infrastructureRef.GroupVersionKind().Version,
-				})
-				err = r.k8sClient.CtrlClient().Get(ctx, client.ObjectKey{
-					Namespace: cr.Namespace,
-					Name:      infrastructureRef.Name,
-				}, gcpCluster)
+				gcpCluster, err := r.resolver.Get(ctx, cr, infrastructureRef.GroupVersionKind(), infrastructureRef.Namespace, infrastructureRef.Name)
 				if err != nil {
 					return nil, microerror.Mask(err)
 				}
@@ -168,10 +160,28 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 		}
 	}
 
+	controlPlane, err := controlplane.GetSettings(ctx, r.resolver, cr)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// The CNI decides which network policy flavor is used. CNIs which are not
+	// installed with the cluster apps are never installed by chart-operator.
+	detectedCNI, err := r.cni.Detect(ctx, cr)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if detectedCNI.Kind != "" {
+		r.logger.Debugf(ctx, "detected cni %#q of cluster '%s/%s' from %s", detectedCNI.Kind, cr.GetNamespace(), key.ClusterID(cr), detectedCNI.Source)
+	}
+
 	appOperatorValues := map[string]interface{}{
 		"app": map[string]interface{}{
 			"watchNamespace":    cr.GetNamespace(),
-			"workloadClusterID": key.ClusterID(&cr),
+			"workloadClusterID": key.ClusterID(cr),
+		},
+		"kubernetes": map[string]interface{}{
+			"clusterDomain": clusterDomain,
 		},
 		"provider": map[string]interface{}{
 			"kind": provider,
@@ -180,11 +190,8 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 			"domain": r.registryDomain,
 		},
 	}
-	// disable kubernetes client cache for EKS and AKS clusters
-	if key.IsEKS(cr) || key.IsAKS(cr) {
-		appOperatorValues["kubernetes"] = map[string]interface{}{
-			"disableClientCache": true,
-		}
+	if controlPlane.DisableClientCache {
+		appOperatorValues["kubernetes"].(map[string]interface{})["disableClientCache"] = true
 	}
 
 	appValuesYaml, err := yaml.Marshal(appOperatorValues)
@@ -194,13 +201,14 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 
 	appValuesConfigMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      key.AppOperatorValuesResourceName(&cr),
-			Namespace: cr.GetNamespace(),
+			Name:            key.AppOperatorValuesResourceName(cr),
+			Namespace:       cr.GetNamespace(),
+			OwnerReferences: key.ClusterOwnerReferences(cr),
 			Annotations: map[string]string{
 				annotation.Notes: fmt.Sprintf("DO NOT EDIT. Values managed by %s.", project.Name()),
 			},
 			Labels: map[string]string{
-				label.Cluster:   key.ClusterID(&cr),
+				label.Cluster:   key.ClusterID(cr),
 				label.ManagedBy: project.Name(),
 			},
 		},
@@ -210,45 +218,47 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 	}
 
 	clusterValues := ClusterValuesConfig{
+		SchemaVersion: SchemaVersion,
+
+		AWS:                 awsConfig,
+		Azure:               azureConfig,
 		AzureSubscriptionID: azureSubscriptionID,
-		BaseDomain:          key.BaseDomain(&cr, r.baseDomain),
+		BaseDomain:          key.BaseDomain(cr, r.baseDomain),
 		BootstrapMode: ChartOperatorBootstrapMode{
-			Enabled:          true,
-			ApiServerPodPort: 6443,
+			Enabled:          controlPlane.BootstrapMode,
+			ApiServerPodPort: controlPlane.APIServerPort,
 		},
-		ChartOperator: ChartOperatorConfig{Cni: map[string]bool{"install": true}},
+		ChartOperator: ChartOperatorConfig{Cni: map[string]bool{"install": controlPlane.InstallCNI && detectedCNI.Managed()}},
 		Cluster: ClusterConfig{
 			Calico: map[string]string{"CIDR": podCIDR},
+			CNI:    CNIConfig{Kind: detectedCNI.Kind, PodCIDR: podCIDR},
 			Kubernetes: KubernetesConfig{
-				API: map[string]string{"clusterIPRange": r.clusterIPRange},
-				DNS: map[string]string{"IP": clusterDNSIP},
+				API:           map[string]string{"clusterIPRange": r.clusterIPRange},
+				ClusterDomain: clusterDomain,
+				DNS:           map[string]string{"IP": clusterDNSIP},
 			},
 		},
 		ClusterCA:    clusterCA,
 		ClusterDNSIP: clusterDNSIP,
-		ClusterID:    key.ClusterID(&cr),
+		ClusterID:    key.ClusterID(cr),
 		ClusterCIDR:  clusterCIDR,
 		GcpProject:   gcpProject,
-		Provider:     provider,
+		Metadata: MetadataConfig{
+			Annotations:    allowlisted(cr.GetAnnotations(), r.metadataAnnotations),
+			Description:    key.ClusterDescription(cr),
+			Labels:         allowlisted(cr.GetLabels(), r.metadataLabels),
+			Organization:   key.Organization(cr),
+			ReleaseVersion: key.ReleaseVersion(cr),
+		},
+		Provider: provider,
 		CiliumNetworkPolicy: CiliumNetworkPolicy{
-			Enabled: true,
+			Enabled: detectedCNI.Kind == "" || detectedCNI.Kind == cni.KindCilium,
 		},
 	}
 
-	// disable boostrap mode and do not install CNI for EKS and AKS clusters
-	if key.IsEKS(cr) || key.IsAKS(cr) {
-		clusterValues.BootstrapMode.Enabled = false
-		clusterValues.ChartOperator.Cni["install"] = false
-	}
-
-	// disable boostrap mode and do not install CNI for Kamaji clusters
-	if key.IsKamaji(cr) {
-		clusterValues.BootstrapMode.Enabled = false
-	}
-
 	// when the workload cluster considered is the management cluster itself,
 	// the Chart Operator is due to get a special configuration to avoid privilege escalation.
-	if r.managementClusterID == key.ClusterID(&cr) {
+	if r.managementClusterID == key.ClusterID(cr) {
 		clusterValues.Helm = &ChartOperatorHelmConfig{
 			NamespaceWhitelist: []string{
 				"org-giantswarm",
@@ -266,20 +276,57 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 		clusterValues.Cluster.Private = true
 	}
 
+	extraValues, err := r.extraValues.ConfigMapValues(ctx, cr)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r.recorder.Update(cr, func(s *clusterstatus.ClusterStatus) {
+		s.Provider = provider
+		s.Private = clusterValues.Cluster.Private
+	})
+
 	clusterValuesYaml, err := yaml.Marshal(clusterValues)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
+	// The values are consumed by chart-operator and every workload cluster
+	// chart, so they are never written when they break the published schema.
+	// Only the generated values are validated. Extra values may add keys the
+	// schema does not declare, but can never change generated ones. Topology
+	// variables at paths the schema declares were validated against it.
+	err = validateClusterValues(clusterValuesYaml)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Extra values only add keys, the generated values take precedence so
+	// users cannot override them. Allowlisted topology variables take
+	// precedence over the generated values.
+	if len(extraValues.Sources) > 0 {
+		var generated map[string]interface{}
+		err = yaml.Unmarshal(clusterValuesYaml, &generated)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		clusterValuesYaml, err = yaml.Marshal(extraValues.Render(generated))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	clusterValuesConfigMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      key.ClusterValuesResourceName(&cr),
-			Namespace: cr.GetNamespace(),
+			Name:            key.ClusterValuesResourceName(cr),
+			Namespace:       cr.GetNamespace(),
+			OwnerReferences: key.ClusterOwnerReferences(cr),
 			Annotations: map[string]string{
 				annotation.Notes: fmt.Sprintf("DO NOT EDIT. Values managed by %s.", project.Name()),
 			},
 			Labels: map[string]string{
-				label.Cluster:   key.ClusterID(&cr),
+				label.Cluster:   key.ClusterID(cr),
 				label.ManagedBy: project.Name(),
 			},
 		},
@@ -287,7 +334,31 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 			"values": string(clusterValuesYaml),
 		},
 	}
+	if len(extraValues.Sources) > 0 {
+		clusterValuesConfigMap.Annotations[extravalues.AnnotationSources] = extravalues.SourcesAnnotation(extraValues.Sources)
+	}
 	configMaps = append(configMaps, appValuesConfigMap, clusterValuesConfigMap)
 
+	for _, cm := range configMaps {
+		r.recorder.RecordValues(cr, "configmap", cm.Name, []byte(cm.Data["values"]))
+	}
+
 	return configMaps, nil
 }
+
+// allowlisted returns the entries of m whose keys are in the allowlist.
+// Allowlist entries ending with * match all keys with that prefix.
+func allowlisted(m map[string]string, allowlist []string) map[string]string {
+	selected := map[string]string{}
+
+	for k, v := range m {
+		for _, a := range allowlist {
+			if k == a || (strings.HasSuffix(a, "*") && strings.HasPrefix(k, strings.TrimSuffix(a, "*"))) {
+				selected[k] = v
+				break
+			}
+		}
+	}
+
+	return selected
+}
@@ -2,6 +2,7 @@ package clusterconfigmap
 
 import (
 	"context"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -12,13 +13,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capiv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/yaml"
 
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/micrologger/microloggertest"
 
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/podcidr"
 )
 
@@ -81,6 +90,7 @@ func Test_ClusterValuesGCP(t *testing.T) {
 	var fakeClient *k8sclienttest.Clients
 	{
 		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
 			capi.AddToScheme,
 		}
 
@@ -91,6 +101,7 @@ func Test_ClusterValuesGCP(t *testing.T) {
 
 		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
 			CtrlClient: clientfake.NewClientBuilder().
+				WithRESTMapper(infrastructuretest.NewRESTMapper(gcpCluster, cluster)).
 				WithRuntimeObjects(gcpCluster, cluster).
 				Build(),
 		})
@@ -98,9 +109,11 @@ func Test_ClusterValuesGCP(t *testing.T) {
 
 	config := Config{
 		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
 		Logger:         microloggertest.New(),
 		PodCIDR:        podCidr,
 		BaseDomain:     "fadi.gigantic.io",
+		ClusterDomain:  "cluster.local",
 		ClusterIPRange: "10.0.0.0/16",
 		DNSIP:          "192.168.0.10",
 		RegistryDomain: "gsoci.azurecr.io/giantswarm",
@@ -212,6 +225,7 @@ func Test_ClusterValuesDNSIP(t *testing.T) {
 	var fakeClient *k8sclienttest.Clients
 	{
 		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
 			capi.AddToScheme,
 		}
 
@@ -222,6 +236,7 @@ func Test_ClusterValuesDNSIP(t *testing.T) {
 
 		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
 			CtrlClient: clientfake.NewClientBuilder().
+				WithRESTMapper(infrastructuretest.NewRESTMapper(kubeadmControlPlane, gcpCluster, cluster)).
 				WithRuntimeObjects(kubeadmControlPlane, gcpCluster, cluster).
 				Build(),
 		})
@@ -229,9 +244,11 @@ func Test_ClusterValuesDNSIP(t *testing.T) {
 
 	config := Config{
 		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
 		Logger:         microloggertest.New(),
 		PodCIDR:        podCidr,
 		BaseDomain:     "fadi.gigantic.io",
+		ClusterDomain:  "cluster.local",
 		ClusterIPRange: "10.0.0.0/16",
 		DNSIP:          "192.168.0.10",
 		RegistryDomain: "gsoci.azurecr.io/giantswarm",
@@ -329,6 +346,7 @@ func Test_ClusterValuesDNSIPWhenServiceCidrIsNotSet(t *testing.T) {
 	var fakeClient *k8sclienttest.Clients
 	{
 		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
 			capi.AddToScheme,
 		}
 
@@ -339,6 +357,7 @@ func Test_ClusterValuesDNSIPWhenServiceCidrIsNotSet(t *testing.T) {
 
 		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
 			CtrlClient: clientfake.NewClientBuilder().
+				WithRESTMapper(infrastructuretest.NewRESTMapper(kubeadmControlPlane, gcpCluster, cluster)).
 				WithRuntimeObjects(kubeadmControlPlane, gcpCluster, cluster).
 				Build(),
 		})
@@ -346,9 +365,11 @@ func Test_ClusterValuesDNSIPWhenServiceCidrIsNotSet(t *testing.T) {
 
 	config := Config{
 		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
 		Logger:         microloggertest.New(),
 		PodCIDR:        podCidr,
 		BaseDomain:     "fadi.gigantic.io",
+		ClusterDomain:  "cluster.local",
 		ClusterIPRange: "10.96.0.0/12",
 		DNSIP:          "10.96.0.10",
 		RegistryDomain: "gsoci.azurecr.io/giantswarm",
@@ -448,6 +469,7 @@ func Test_ClusterValuesGCPProjectOnlyAddedOnGCP(t *testing.T) {
 	var fakeClient *k8sclienttest.Clients
 	{
 		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
 			capi.AddToScheme,
 		}
 
@@ -458,6 +480,7 @@ func Test_ClusterValuesGCPProjectOnlyAddedOnGCP(t *testing.T) {
 
 		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
 			CtrlClient: clientfake.NewClientBuilder().
+				WithRESTMapper(infrastructuretest.NewRESTMapper(capzCluster, cluster)).
 				WithRuntimeObjects(capzCluster, cluster).
 				Build(),
 		})
@@ -465,9 +488,11 @@ func Test_ClusterValuesGCPProjectOnlyAddedOnGCP(t *testing.T) {
 
 	config := Config{
 		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
 		Logger:         microloggertest.New(),
 		PodCIDR:        podCidr,
 		BaseDomain:     "fadi.gigantic.io",
+		ClusterDomain:  "cluster.local",
 		ClusterIPRange: "10.96.0.0/12",
 		DNSIP:          "10.96.0.10",
 		RegistryDomain: "gsoci.azurecr.io/giantswarm",
@@ -519,12 +544,21 @@ func Test_ClusterValuesCAPZ(t *testing.T) {
 			"namespace": "default",
 		},
 		"spec": map[string]interface{}{
+			"location":       "westeurope",
 			"resourceGroup":  "group1",
 			"subscriptionID": "143d9c06-6015-4a4a-a4f9-74a664207db7",
+			"identityRef": map[string]interface{}{
+				"kind": "AzureClusterIdentity",
+				"name": "cluster-identity",
+			},
 			"networkSpec": map[string]interface{}{
 				"apiServerLB": map[string]interface{}{
 					"type": "Public",
 				},
+				"vnet": map[string]interface{}{
+					"name":       "test-cluster-vnet",
+					"cidrBlocks": []interface{}{"10.0.0.0/16"},
+				},
 			},
 		},
 	}
@@ -534,6 +568,23 @@ func Test_ClusterValuesCAPZ(t *testing.T) {
 		Version: "v1beta1",
 	})
 
+	clusterIdentity := &unstructured.Unstructured{}
+	clusterIdentity.Object = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      "cluster-identity",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"clientID": "5a8c4a6c-4f6e-4c3a-9d6b-7e0c3c6b1a2d",
+			"tenantID": "b1a2c3d4-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+		},
+	}
+	clusterIdentity.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Kind:    "AzureClusterIdentity",
+		Version: "v1beta1",
+	})
+
 	cluster := &capi.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-cluster",
@@ -568,6 +619,7 @@ func Test_ClusterValuesCAPZ(t *testing.T) {
 	var fakeClient *k8sclienttest.Clients
 	{
 		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
 			capi.AddToScheme,
 		}
 
@@ -578,16 +630,19 @@ func Test_ClusterValuesCAPZ(t *testing.T) {
 
 		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
 			CtrlClient: clientfake.NewClientBuilder().
-				WithRuntimeObjects(capzCluster, cluster).
+				WithRESTMapper(infrastructuretest.NewRESTMapper(capzCluster, clusterIdentity, cluster)).
+				WithRuntimeObjects(capzCluster, clusterIdentity, cluster).
 				Build(),
 		})
 	}
 
 	config := Config{
 		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
 		Logger:         microloggertest.New(),
 		PodCIDR:        podCidr,
 		BaseDomain:     "azuretest.gigantic.io",
+		ClusterDomain:  "cluster.local",
 		ClusterIPRange: "10.200.0.0/24",
 		DNSIP:          "172.31.0.10",
 		RegistryDomain: "gsoci.azurecr.io/giantswarm",
@@ -612,6 +667,22 @@ func Test_ClusterValuesCAPZ(t *testing.T) {
 			assertEquals(t, "test-cluster.azuretest.gigantic.io", cmData.BaseDomain, "Wrong baseDomain set in cluster-values configmap")
 			assertEquals(t, "capz", cmData.Provider, "Wrong provider set in cluster-values configmap")
 			assertEquals(t, "143d9c06-6015-4a4a-a4f9-74a664207db7", cmData.AzureSubscriptionID, "Wrong AzureSubscriptionID set in cluster-values configmap")
+			assertEquals(t, "10.0.0.0/16", cmData.ClusterCIDR, "Wrong clusterCIDR set in cluster-values configmap")
+
+			expectedAzure := &AzureConfig{
+				Location:       "westeurope",
+				ResourceGroup:  "group1",
+				SubscriptionID: "143d9c06-6015-4a4a-a4f9-74a664207db7",
+				TenantID:       "b1a2c3d4-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+				VNet: AzureVNetConfig{
+					CIDR:          "10.0.0.0/16",
+					Name:          "test-cluster-vnet",
+					ResourceGroup: "group1",
+				},
+			}
+			if !reflect.DeepEqual(expectedAzure, cmData.Azure) {
+				t.Fatalf("expected azure values %#v got %#v", expectedAzure, cmData.Azure)
+			}
 
 			if !cmData.BootstrapMode.Enabled {
 				t.Fatal("bootstrap mode should be enabled")
@@ -695,6 +766,7 @@ func Test_ClusterValuesPrivateCAPZ(t *testing.T) {
 	var fakeClient *k8sclienttest.Clients
 	{
 		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
 			capi.AddToScheme,
 		}
 
@@ -705,6 +777,7 @@ func Test_ClusterValuesPrivateCAPZ(t *testing.T) {
 
 		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
 			CtrlClient: clientfake.NewClientBuilder().
+				WithRESTMapper(infrastructuretest.NewRESTMapper(capzCluster, cluster)).
 				WithRuntimeObjects(capzCluster, cluster).
 				Build(),
 		})
@@ -712,9 +785,11 @@ func Test_ClusterValuesPrivateCAPZ(t *testing.T) {
 
 	config := Config{
 		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
 		Logger:         microloggertest.New(),
 		PodCIDR:        podCidr,
 		BaseDomain:     "azuretest.gigantic.io",
+		ClusterDomain:  "cluster.local",
 		ClusterIPRange: "10.200.0.0/24",
 		DNSIP:          "172.31.0.10",
 		RegistryDomain: "gsoci.azurecr.io/giantswarm",
@@ -760,6 +835,171 @@ func Test_ClusterValuesPrivateCAPZ(t *testing.T) {
 	}
 }
 
+func Test_ClusterValuesAzureManagedCluster(t *testing.T) {
+	podCidrConfig := podcidr.Config{InstallationCIDR: "10.200.0.0/24"}
+	podCidr, err := podcidr.New(podCidrConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	managedCluster := &unstructured.Unstructured{}
+	managedCluster.Object = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      "test-cluster",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"networkSpec": map[string]interface{}{
+				"apiServerLB": map[string]interface{}{
+					"type": "Public",
+				},
+			},
+		},
+	}
+	managedCluster.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Kind:    "AzureManagedCluster",
+		Version: "v1beta1",
+	})
+
+	managedControlPlane := &unstructured.Unstructured{}
+	managedControlPlane.Object = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      "test-cluster",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"location":          "westeurope",
+			"resourceGroupName": "test-cluster-rg",
+			"subscriptionID":    "143d9c06-6015-4a4a-a4f9-74a664207db7",
+			"identityRef": map[string]interface{}{
+				"kind":      "AzureClusterIdentity",
+				"name":      "cluster-identity",
+				"namespace": "giantswarm",
+			},
+			"virtualNetwork": map[string]interface{}{
+				"name":          "test-cluster-vnet",
+				"cidrBlock":     "10.1.0.0/16",
+				"resourceGroup": "network-rg",
+			},
+		},
+	}
+	managedControlPlane.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Kind:    "AzureManagedControlPlane",
+		Version: "v1beta1",
+	})
+
+	clusterIdentity := &unstructured.Unstructured{}
+	clusterIdentity.Object = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      "cluster-identity",
+			"namespace": "giantswarm",
+		},
+		"spec": map[string]interface{}{
+			"tenantID": "b1a2c3d4-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+		},
+	}
+	clusterIdentity.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Kind:    "AzureClusterIdentity",
+		Version: "v1beta1",
+	})
+
+	cluster := &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+			Labels: map[string]string{
+				capi.ClusterNameLabel: "test-cluster",
+			},
+		},
+		Spec: capi.ClusterSpec{
+			ControlPlaneRef: &corev1.ObjectReference{
+				Kind:       "AzureManagedControlPlane",
+				Namespace:  "default",
+				Name:       "test-cluster",
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+			},
+			InfrastructureRef: &corev1.ObjectReference{
+				Kind:       "AzureManagedCluster",
+				Namespace:  "default",
+				Name:       "test-cluster",
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+			},
+		},
+	}
+
+	var fakeClient *k8sclienttest.Clients
+	{
+		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
+			capi.AddToScheme,
+		}
+
+		err = schemeBuilder.AddToScheme(scheme.Scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			CtrlClient: clientfake.NewClientBuilder().
+				WithRESTMapper(infrastructuretest.NewRESTMapper(managedCluster, managedControlPlane, clusterIdentity, cluster)).
+				WithRuntimeObjects(managedCluster, managedControlPlane, clusterIdentity, cluster).
+				Build(),
+		})
+	}
+
+	config := Config{
+		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
+		Logger:         microloggertest.New(),
+		PodCIDR:        podCidr,
+		BaseDomain:     "azuretest.gigantic.io",
+		ClusterDomain:  "cluster.local",
+		ClusterIPRange: "10.200.0.0/24",
+		DNSIP:          "172.31.0.10",
+		RegistryDomain: "gsoci.azurecr.io/giantswarm",
+	}
+	resource, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configmaps, err := resource.GetDesiredState(context.Background(), cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, configMap := range configmaps {
+		if strings.HasSuffix(configMap.Name, "-cluster-values") {
+			cmData := &ClusterValuesConfig{}
+			err := yaml.Unmarshal([]byte(configMap.Data["values"]), cmData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEquals(t, "capz", cmData.Provider, "Wrong provider set in cluster-values configmap")
+			assertEquals(t, "143d9c06-6015-4a4a-a4f9-74a664207db7", cmData.AzureSubscriptionID, "Wrong AzureSubscriptionID set in cluster-values configmap")
+			assertEquals(t, "10.1.0.0/16", cmData.ClusterCIDR, "Wrong clusterCIDR set in cluster-values configmap")
+
+			expectedAzure := &AzureConfig{
+				Location:       "westeurope",
+				ResourceGroup:  "test-cluster-rg",
+				SubscriptionID: "143d9c06-6015-4a4a-a4f9-74a664207db7",
+				TenantID:       "b1a2c3d4-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+				VNet: AzureVNetConfig{
+					CIDR:          "10.1.0.0/16",
+					Name:          "test-cluster-vnet",
+					ResourceGroup: "network-rg",
+				},
+			}
+			if !reflect.DeepEqual(expectedAzure, cmData.Azure) {
+				t.Fatalf("expected azure values %#v got %#v", expectedAzure, cmData.Azure)
+			}
+		}
+	}
+}
+
 func Test_ClusterValuesAzureASOManagedCluster(t *testing.T) {
 	podCidrConfig := podcidr.Config{InstallationCIDR: "10.200.0.0/24"}
 	podCidr, err := podcidr.New(podCidrConfig)
@@ -774,7 +1014,46 @@ func Test_ClusterValuesAzureASOManagedCluster(t *testing.T) {
 			"namespace": "default",
 		},
 		"spec": map[string]interface{}{
-			"resources": []interface{}{},
+			"resources": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "resources.azure.com/v1api20200601",
+					"kind":       "ResourceGroup",
+					"metadata": map[string]interface{}{
+						"name": "test-cluster",
+						"annotations": map[string]interface{}{
+							"serviceoperator.azure.com/credential-from": "test-cluster-aso-credentials",
+						},
+					},
+					"spec": map[string]interface{}{
+						"location": "northeurope",
+					},
+				},
+				map[string]interface{}{
+					"apiVersion": "network.azure.com/v1api20201101",
+					"kind":       "VirtualNetwork",
+					"metadata": map[string]interface{}{
+						"name": "test-cluster-vnet",
+					},
+					"spec": map[string]interface{}{
+						"azureName": "aks-vnet",
+						"location":  "northeurope",
+						"addressSpace": map[string]interface{}{
+							"addressPrefixes": []interface{}{"10.224.0.0/12"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	asoCredentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-aso-credentials",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"AZURE_SUBSCRIPTION_ID": []byte("143d9c06-6015-4a4a-a4f9-74a664207db7"),
+			"AZURE_TENANT_ID":       []byte("b1a2c3d4-5e6f-4a8b-9c0d-1e2f3a4b5c6d"),
 		},
 	}
 	asoCluster.SetGroupVersionKind(schema.GroupVersionKind{
@@ -813,6 +1092,7 @@ func Test_ClusterValuesAzureASOManagedCluster(t *testing.T) {
 	var fakeClient *k8sclienttest.Clients
 	{
 		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
 			capi.AddToScheme,
 		}
 
@@ -823,16 +1103,19 @@ func Test_ClusterValuesAzureASOManagedCluster(t *testing.T) {
 
 		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
 			CtrlClient: clientfake.NewClientBuilder().
-				WithRuntimeObjects(asoCluster, cluster).
+				WithRESTMapper(infrastructuretest.NewRESTMapper(asoCluster, asoCredentials, cluster)).
+				WithRuntimeObjects(asoCluster, asoCredentials, cluster).
 				Build(),
 		})
 	}
 
 	config := Config{
 		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
 		Logger:         microloggertest.New(),
 		PodCIDR:        podCidr,
 		BaseDomain:     "azuretest.gigantic.io",
+		ClusterDomain:  "cluster.local",
 		ClusterIPRange: "10.200.0.0/24",
 		DNSIP:          "172.31.0.10",
 		RegistryDomain: "gsoci.azurecr.io/giantswarm",
@@ -856,7 +1139,23 @@ func Test_ClusterValuesAzureASOManagedCluster(t *testing.T) {
 			}
 			assertEquals(t, "test-cluster.azuretest.gigantic.io", cmData.BaseDomain, "Wrong baseDomain set in cluster-values configmap")
 			assertEquals(t, "capz", cmData.Provider, "Wrong provider set in cluster-values configmap")
-			assertEquals(t, "", cmData.AzureSubscriptionID, "AzureSubscriptionID should be empty for ASO-managed clusters")
+			assertEquals(t, "143d9c06-6015-4a4a-a4f9-74a664207db7", cmData.AzureSubscriptionID, "Wrong AzureSubscriptionID set in cluster-values configmap")
+			assertEquals(t, "10.224.0.0/12", cmData.ClusterCIDR, "Wrong clusterCIDR set in cluster-values configmap")
+
+			expectedAzure := &AzureConfig{
+				Location:       "northeurope",
+				ResourceGroup:  "test-cluster",
+				SubscriptionID: "143d9c06-6015-4a4a-a4f9-74a664207db7",
+				TenantID:       "b1a2c3d4-5e6f-4a8b-9c0d-1e2f3a4b5c6d",
+				VNet: AzureVNetConfig{
+					CIDR:          "10.224.0.0/12",
+					Name:          "aks-vnet",
+					ResourceGroup: "test-cluster",
+				},
+			}
+			if !reflect.DeepEqual(expectedAzure, cmData.Azure) {
+				t.Fatalf("expected azure values %#v got %#v", expectedAzure, cmData.Azure)
+			}
 			assertEquals(t, "", cmData.GcpProject, "GcpProject should be empty for ASO-managed clusters")
 		} else if strings.HasSuffix(configMap.Name, "-app-operator-values") {
 			cmData := &AppOperatorValuesConfig{}
@@ -869,6 +1168,572 @@ func Test_ClusterValuesAzureASOManagedCluster(t *testing.T) {
 	}
 }
 
+func Test_ClusterValuesAWS(t *testing.T) {
+	newObject := func(kind, apiVersion string, annotations map[string]interface{}, spec map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.Object = map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":        "test-cluster",
+				"namespace":   "default",
+				"annotations": annotations,
+			},
+			"spec": spec,
+		}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		return obj
+	}
+
+	network := map[string]interface{}{
+		"vpc": map[string]interface{}{
+			"id":        "vpc-0123456789abcdef0",
+			"cidrBlock": "10.0.0.0/16",
+		},
+		"subnets": []interface{}{
+			map[string]interface{}{
+				"id":               "test-cluster-subnet-private-eu-west-1a",
+				"resourceID":       "subnet-0123456789abcdef0",
+				"availabilityZone": "eu-west-1a",
+				"cidrBlock":        "10.0.0.0/20",
+				"isPublic":         false,
+			},
+			map[string]interface{}{
+				"id":               "subnet-0123456789abcdef1",
+				"availabilityZone": "eu-west-1a",
+				"cidrBlock":        "10.0.16.0/20",
+				"isPublic":         true,
+			},
+		},
+	}
+
+	roleIdentity := &unstructured.Unstructured{}
+	roleIdentity.Object = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "test-identity",
+		},
+		"spec": map[string]interface{}{
+			"roleARN": "arn:aws:iam::123456789012:role/giantswarm-capa-controller",
+		},
+	}
+	roleIdentity.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta2")
+	roleIdentity.SetKind("AWSClusterRoleIdentity")
+
+	identityRef := map[string]interface{}{
+		"kind": "AWSClusterRoleIdentity",
+		"name": "test-identity",
+	}
+	vpcMode := map[string]interface{}{
+		annotation.AWSVPCMode: annotation.AWSVPCModePublic,
+	}
+
+	expectedAWS := &AWSConfig{
+		AccountID: "123456789012",
+		Region:    "eu-west-1",
+		Subnets: []AWSSubnetConfig{
+			{
+				AvailabilityZone: "eu-west-1a",
+				CIDR:             "10.0.0.0/20",
+				ID:               "subnet-0123456789abcdef0",
+				Public:           false,
+			},
+			{
+				AvailabilityZone: "eu-west-1a",
+				CIDR:             "10.0.16.0/20",
+				ID:               "subnet-0123456789abcdef1",
+				Public:           true,
+			},
+		},
+		VPC: AWSVPCConfig{
+			CIDR: "10.0.0.0/16",
+			ID:   "vpc-0123456789abcdef0",
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		infraKind       string
+		controlPlaneRef *corev1.ObjectReference
+		objects         []runtime.Object
+		// v1beta2 serves the Cluster as v1beta2, whose references do not
+		// carry a version.
+		v1beta2 bool
+		// expectedAccountID defaults to the account of roleIdentity.
+		expectedAccountID string
+	}{
+		{
+			name:      "case 0: AWSCluster",
+			infraKind: "AWSCluster",
+			objects: []runtime.Object{
+				newObject("AWSCluster", "infrastructure.cluster.x-k8s.io/v1beta2", vpcMode, map[string]interface{}{
+					"region":      "eu-west-1",
+					"identityRef": identityRef,
+					"network":     network,
+				}),
+				roleIdentity,
+			},
+		},
+		{
+			name:      "case 1: AWSManagedCluster reads the AWSManagedControlPlane",
+			infraKind: "AWSManagedCluster",
+			controlPlaneRef: &corev1.ObjectReference{
+				Kind:       "AWSManagedControlPlane",
+				Namespace:  "default",
+				Name:       "test-cluster",
+				APIVersion: "controlplane.cluster.x-k8s.io/v1beta2",
+			},
+			objects: []runtime.Object{
+				newObject("AWSManagedCluster", "infrastructure.cluster.x-k8s.io/v1beta2", vpcMode, map[string]interface{}{}),
+				newObject("AWSManagedControlPlane", "controlplane.cluster.x-k8s.io/v1beta2", nil, map[string]interface{}{
+					"region":      "eu-west-1",
+					"identityRef": identityRef,
+					"network":     network,
+				}),
+				roleIdentity,
+			},
+		},
+		{
+			name:      "case 2: v1beta2 Cluster resolves the infrastructure version",
+			infraKind: "AWSCluster",
+			objects: []runtime.Object{
+				newObject("AWSCluster", "infrastructure.cluster.x-k8s.io/v1beta2", vpcMode, map[string]interface{}{
+					"region":      "eu-west-1",
+					"identityRef": identityRef,
+					"network":     network,
+				}),
+				roleIdentity,
+			},
+			v1beta2: true,
+		},
+		{
+			name:      "case 3: AWSCluster without identity uses the management account",
+			infraKind: "AWSCluster",
+			objects: []runtime.Object{
+				newObject("AWSCluster", "infrastructure.cluster.x-k8s.io/v1beta2", vpcMode, map[string]interface{}{
+					"region":  "eu-west-1",
+					"network": network,
+				}),
+			},
+			expectedAccountID: "210987654321",
+		},
+		{
+			name:      "case 4: AWSCluster with the controller identity uses the management account",
+			infraKind: "AWSCluster",
+			objects: []runtime.Object{
+				newObject("AWSCluster", "infrastructure.cluster.x-k8s.io/v1beta2", vpcMode, map[string]interface{}{
+					"region": "eu-west-1",
+					"identityRef": map[string]interface{}{
+						"kind": "AWSClusterControllerIdentity",
+						"name": "default",
+					},
+					"network": network,
+				}),
+			},
+			expectedAccountID: "210987654321",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			podCidr, err := podcidr.New(podcidr.Config{InstallationCIDR: "10.0.0.0/16"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := *expectedAWS
+			if tc.expectedAccountID != "" {
+				expected.AccountID = tc.expectedAccountID
+			}
+
+			var cluster client.Object = &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "default",
+					Labels: map[string]string{
+						capi.ClusterNameLabel: "test-cluster",
+					},
+				},
+				Spec: capi.ClusterSpec{
+					ControlPlaneRef: tc.controlPlaneRef,
+					InfrastructureRef: &corev1.ObjectReference{
+						Kind:       tc.infraKind,
+						Namespace:  "default",
+						Name:       "test-cluster",
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2",
+					},
+				},
+			}
+			if tc.v1beta2 {
+				cluster = &capiv1beta2.Cluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-cluster",
+						Namespace: "default",
+						Labels: map[string]string{
+							capi.ClusterNameLabel: "test-cluster",
+						},
+					},
+					Spec: capiv1beta2.ClusterSpec{
+						InfrastructureRef: capiv1beta2.ContractVersionedObjectReference{
+							APIGroup: "infrastructure.cluster.x-k8s.io",
+							Kind:     tc.infraKind,
+							Name:     "test-cluster",
+						},
+					},
+				}
+			}
+
+			err = capi.AddToScheme(scheme.Scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = capiv1beta2.AddToScheme(scheme.Scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fakeClient := k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+				CtrlClient: clientfake.NewClientBuilder().
+					WithRESTMapper(infrastructuretest.NewRESTMapper(tc.objects...)).
+					WithRuntimeObjects(append(tc.objects, cluster)...).
+					Build(),
+			})
+
+			config := Config{
+				K8sClient:      fakeClient,
+				Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
+				Logger:         microloggertest.New(),
+				PodCIDR:        podCidr,
+				BaseDomain:     "awstest.gigantic.io",
+				ClusterDomain:  "cluster.local",
+				ClusterIPRange: "10.0.0.0/16",
+				DNSIP:          "172.31.0.10",
+				RegistryDomain: "gsoci.azurecr.io/giantswarm",
+
+				AWSManagementAccountID: "210987654321",
+			}
+			resource, err := New(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			configmaps, err := resource.GetDesiredState(context.Background(), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, configMap := range configmaps {
+				if strings.HasSuffix(configMap.Name, "-cluster-values") {
+					cmData := &ClusterValuesConfig{}
+					err := yaml.Unmarshal([]byte(configMap.Data["values"]), cmData)
+					if err != nil {
+						t.Fatal(err)
+					}
+					assertEquals(t, "capa", cmData.Provider, "Wrong provider set in cluster-values configmap")
+
+					if !reflect.DeepEqual(&expected, cmData.AWS) {
+						t.Fatalf("expected aws values %#v got %#v", &expected, cmData.AWS)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_ClusterValuesMetadata(t *testing.T) {
+	podCidrConfig := podcidr.Config{InstallationCIDR: "10.0.0.0/16"}
+	podCidr, err := podcidr.New(podCidrConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gcpCluster := &unstructured.Unstructured{}
+	gcpCluster.Object = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      "test-cluster",
+			"namespace": "org-acme",
+		},
+		"spec": map[string]interface{}{
+			"project": "12345",
+		},
+	}
+	gcpCluster.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Kind:    "GCPCluster",
+		Version: "v1beta1",
+	})
+
+	cluster := &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "org-acme",
+			Labels: map[string]string{
+				capi.ClusterNameLabel:            "test-cluster",
+				"release.giantswarm.io/version":  "25.0.0",
+				"giantswarm.io/service-priority": "highest",
+				"team.acme.io/name":              "platform",
+				"team.acme.io/cost-center":       "1234",
+				"not-allowlisted":                "true",
+			},
+			Annotations: map[string]string{
+				"cluster.giantswarm.io/description": "Production cluster",
+				"acme.io/contact":                   "platform@acme.io",
+				"not-allowlisted":                   "true",
+			},
+		},
+		Spec: capi.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{
+				Kind:       "GCPCluster",
+				Namespace:  "org-acme",
+				Name:       "test-cluster",
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+			},
+		},
+	}
+
+	var fakeClient *k8sclienttest.Clients
+	{
+		schemeBuilder := runtime.SchemeBuilder{
+			appv1alpha1.AddToScheme,
+			capi.AddToScheme,
+		}
+
+		err = schemeBuilder.AddToScheme(scheme.Scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fakeClient = k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			CtrlClient: clientfake.NewClientBuilder().
+				WithRESTMapper(infrastructuretest.NewRESTMapper(gcpCluster, cluster)).
+				WithRuntimeObjects(gcpCluster, cluster).
+				Build(),
+		})
+	}
+
+	config := Config{
+		K8sClient:      fakeClient,
+		Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
+		Logger:         microloggertest.New(),
+		PodCIDR:        podCidr,
+		BaseDomain:     "fadi.gigantic.io",
+		ClusterDomain:  "cluster.local",
+		ClusterIPRange: "10.0.0.0/16",
+		DNSIP:          "192.168.0.10",
+		RegistryDomain: "gsoci.azurecr.io/giantswarm",
+
+		MetadataAnnotations: []string{"acme.io/contact"},
+		MetadataLabels:      []string{"giantswarm.io/service-priority", "team.acme.io/*"},
+	}
+	resource, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configmaps, err := resource.GetDesiredState(context.Background(), cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := MetadataConfig{
+		Annotations: map[string]string{
+			"acme.io/contact": "platform@acme.io",
+		},
+		Description: "Production cluster",
+		Labels: map[string]string{
+			"giantswarm.io/service-priority": "highest",
+			"team.acme.io/name":              "platform",
+			"team.acme.io/cost-center":       "1234",
+		},
+		Organization:   "acme",
+		ReleaseVersion: "25.0.0",
+	}
+
+	for _, configMap := range configmaps {
+		if strings.HasSuffix(configMap.Name, "-cluster-values") {
+			cmData := &ClusterValuesConfig{}
+			err := yaml.Unmarshal([]byte(configMap.Data["values"]), cmData)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(expected, cmData.Metadata) {
+				t.Fatalf("expected metadata %#v got %#v", expected, cmData.Metadata)
+			}
+		}
+	}
+}
+
+func Test_ClusterValuesExtraValues(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		values                string
+		expectedExternalDNSIP string
+		expectedOrganization  string
+		expectedSkipped       bool
+	}{
+		{
+			name:                  "case 0: extra values set a key declared by the schema",
+			values:                "externalDNSIP: 1.1.1.1\n",
+			expectedExternalDNSIP: "1.1.1.1",
+		},
+		{
+			name:                 "case 1: extra values add a key unknown to the schema",
+			values:               "global:\n  organization: acme\n",
+			expectedOrganization: "acme",
+		},
+		{
+			name:            "case 2: invalid extra values are skipped",
+			values:          "- externalDNSIP\n",
+			expectedSkipped: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			podCidr, err := podcidr.New(podcidr.Config{InstallationCIDR: "10.0.0.0/16"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gcpCluster := &unstructured.Unstructured{}
+			gcpCluster.Object = map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":      "test-cluster",
+					"namespace": "org-acme",
+				},
+				"spec": map[string]interface{}{
+					"project": "12345",
+				},
+			}
+			gcpCluster.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "infrastructure.cluster.x-k8s.io",
+				Kind:    "GCPCluster",
+				Version: "v1beta1",
+			})
+
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "org-acme",
+					Labels: map[string]string{
+						capi.ClusterNameLabel: "test-cluster",
+					},
+					Annotations: map[string]string{
+						extravalues.AnnotationConfigMaps: "test-cluster-extra-values",
+					},
+				},
+				Spec: capi.ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{
+						Kind:       "GCPCluster",
+						Namespace:  "org-acme",
+						Name:       "test-cluster",
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					},
+				},
+			}
+
+			extraValuesConfigMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster-extra-values",
+					Namespace: "org-acme",
+				},
+				Data: map[string]string{
+					extravalues.ValuesKey: tc.values,
+				},
+			}
+
+			err = capi.AddToScheme(scheme.Scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fakeClient := k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+				CtrlClient: clientfake.NewClientBuilder().
+					WithRESTMapper(infrastructuretest.NewRESTMapper(gcpCluster, cluster)).
+					WithRuntimeObjects(gcpCluster, cluster, extraValuesConfigMap).
+					Build(),
+			})
+
+			eventRecorder := record.NewFakeRecorder(10)
+			recorder := clusterstatus.New()
+
+			extraValues, err := extravalues.New(extravalues.Config{
+				CtrlClient:    fakeClient.CtrlClient(),
+				EventRecorder: eventRecorder,
+				Logger:        microloggertest.New(),
+				Recorder:      recorder,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := Config{
+				ExtraValues:    extraValues,
+				K8sClient:      fakeClient,
+				Resolver:       infrastructuretest.NewResolver(t, fakeClient.CtrlClient()),
+				Logger:         microloggertest.New(),
+				PodCIDR:        podCidr,
+				BaseDomain:     "fadi.gigantic.io",
+				ClusterDomain:  "cluster.local",
+				ClusterIPRange: "10.0.0.0/16",
+				DNSIP:          "192.168.0.10",
+				RegistryDomain: "gsoci.azurecr.io/giantswarm",
+			}
+			resource, err := New(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			configmaps, err := resource.GetDesiredState(context.Background(), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, configMap := range configmaps {
+				if strings.HasSuffix(configMap.Name, "-cluster-values") {
+					cmData := &ClusterValuesConfig{}
+					err := yaml.Unmarshal([]byte(configMap.Data["values"]), cmData)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					var externalDNSIP string
+					if cmData.ExternalDNSIP != nil {
+						externalDNSIP = *cmData.ExternalDNSIP
+					}
+					if externalDNSIP != tc.expectedExternalDNSIP {
+						t.Fatalf("expected externalDNSIP %#q got %#q", tc.expectedExternalDNSIP, externalDNSIP)
+					}
+
+					var values struct {
+						Global struct {
+							Organization string `json:"organization"`
+						} `json:"global"`
+					}
+					err = yaml.Unmarshal([]byte(configMap.Data["values"]), &values)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if values.Global.Organization != tc.expectedOrganization {
+						t.Fatalf("expected global.organization %#q got %#q", tc.expectedOrganization, values.Global.Organization)
+					}
+				}
+			}
+
+			status, _ := recorder.Get(cluster.Namespace, cluster.Name)
+			if skipped := len(status.SkippedExtraValues["configmap"]) > 0; skipped != tc.expectedSkipped {
+				t.Fatalf("expected skipped extra values %t got %#v", tc.expectedSkipped, status.SkippedExtraValues)
+			}
+			if emitted := len(eventRecorder.Events) > 0; emitted != tc.expectedSkipped {
+				t.Fatalf("expected event %t got %d events", tc.expectedSkipped, len(eventRecorder.Events))
+			}
+		})
+	}
+}
+
 func assertEquals(t *testing.T, expected, actual, message string) {
 	if expected != actual {
 		t.Fatalf("%s, expected %q, actual %q", message, expected, actual)
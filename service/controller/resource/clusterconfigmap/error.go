@@ -37,3 +37,12 @@ var fieldNotFoundOnInfrastructureTypeError = &microerror.Error{
 func IsFieldNotFoundOnInfrastructureType(err error) bool {
 	return microerror.Cause(err) == fieldNotFoundOnInfrastructureTypeError
 }
+
+var invalidClusterValuesError = &microerror.Error{
+	Kind: "invalidClusterValuesError",
+}
+
+// IsInvalidClusterValues asserts invalidClusterValuesError.
+func IsInvalidClusterValues(err error) bool {
+	return microerror.Cause(err) == invalidClusterValuesError
+}
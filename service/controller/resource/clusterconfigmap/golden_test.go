@@ -0,0 +1,101 @@
+package clusterconfigmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/cni"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/golden"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/podcidr"
+)
+
+// Test_Golden renders the ConfigMaps of every case in the shared golden test
+// data and compares them against its configmaps.golden.yaml. Run it with
+// UPDATE_GOLDEN=1 to regenerate the golden files.
+func Test_Golden(t *testing.T) {
+	scheme := golden.NewScheme(t)
+
+	for _, tc := range golden.Cases(t, "../testdata/golden", scheme) {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(infrastructuretest.NewRESTMapper(tc.Objects...)).
+				WithRuntimeObjects(tc.Objects...).
+				Build()
+
+			podCIDR, err := podcidr.New(podcidr.Config{InstallationCIDR: "192.168.0.0/16"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cniDetector, err := cni.New(cni.Config{
+				AppReader:  ctrlClient,
+				CtrlClient: ctrlClient,
+				Logger:     microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			extraValues, err := extravalues.New(extravalues.Config{
+				CtrlClient: ctrlClient,
+				Logger:     microloggertest.New(),
+
+				ConfigMapSchema:            Schema(),
+				TopologyVariablesConfigMap: []string{"baseDomain", "team=global.team"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := Config{
+				CNI:         cniDetector,
+				ExtraValues: extraValues,
+				K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+					CtrlClient: ctrlClient,
+				}),
+				Logger:   microloggertest.New(),
+				PodCIDR:  podCIDR,
+				Resolver: infrastructuretest.NewResolver(t, ctrlClient),
+
+				BaseDomain:          "k8s.test.gigantic.io",
+				ClusterDomain:       "cluster.local",
+				ClusterIPRange:      "172.31.0.0/16",
+				DNSIP:               "172.31.0.10",
+				ManagementClusterID: "mc",
+				MetadataAnnotations: []string{"cluster.giantswarm.io/description"},
+				MetadataLabels:      []string{"giantswarm.io/service-priority"},
+				RegistryDomain:      "gsoci.azurecr.io",
+				Proxy: proxy.Proxy{
+					HttpProxy:  "http://proxy.example.com:3128",
+					HttpsProxy: "http://proxy.example.com:3128",
+					NoProxy:    "internal.example.com",
+				},
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			configMaps, err := r.GetDesiredState(context.Background(), tc.Cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var objects []interface{}
+			for _, cm := range configMaps {
+				objects = append(objects, cm)
+			}
+
+			golden.Assert(t, tc.Path("configmaps.golden.yaml"), objects...)
+		})
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"github.com/giantswarm/micrologger"
 
 	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/cni"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/podcidr"
 )
 
@@ -19,32 +23,58 @@ const (
 // Config represents the configuration used to create a new clusterConfigMap
 // resource.
 type Config struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
-	PodCIDR   podcidr.Interface
+	CNI         *cni.Detector
+	ExtraValues *extravalues.ExtraValues
+	K8sClient   k8sclient.Interface
+	Logger      micrologger.Logger
+	PodCIDR     podcidr.Interface
+	Recorder    *clusterstatus.Recorder
+	Resolver    *infra.Resolver
 
-	BaseDomain          string
-	ClusterIPRange      string
-	DNSIP               string
+	BaseDomain string
+	// ClusterDomain is the internal Kubernetes domain of clusters which do
+	// not configure spec.clusterNetwork.serviceDomain.
+	ClusterDomain  string
+	ClusterIPRange string
+	DNSIP          string
+	// MetadataAnnotations and MetadataLabels are the allowlists of Cluster
+	// annotations and labels rendered into the cluster values. Entries
+	// ending with * match all keys with that prefix.
+	MetadataAnnotations []string
+	MetadataLabels      []string
 	ManagementClusterID string
 	RegistryDomain      string
 	Proxy               proxy.Proxy
+
+	// AWSManagementAccountID is the account of CAPA clusters using the
+	// AWSClusterControllerIdentity, which runs with the credentials of the
+	// management cluster.
+	AWSManagementAccountID string
 }
 
 // Resource implements the clusterConfigMap resource.
 type Resource struct {
-	k8sClient k8sclient.Interface
-	logger    micrologger.Logger
-	podCIDR   podcidr.Interface
+	cni         *cni.Detector
+	extraValues *extravalues.ExtraValues
+	k8sClient   k8sclient.Interface
+	logger      micrologger.Logger
+	podCIDR     podcidr.Interface
+	recorder    *clusterstatus.Recorder
+	resolver    *infra.Resolver
 
-	baseDomain string
+	baseDomain    string
+	clusterDomain string
 	// clusterIPRange is the CIDR for the k8s `Services`.
 	clusterIPRange string
 	// dnsIP is the 10th IP within the `clusterIPRange` CIDR, that will be used for the coredns `Service`.
 	dnsIP               string
 	managementClusterID string
+	metadataAnnotations []string
+	metadataLabels      []string
 	registryDomain      string
 	proxy               proxy.Proxy
+
+	awsManagementAccountID string
 }
 
 // New creates a new configured config map state getter resource managing
@@ -61,9 +91,15 @@ func New(config Config) (*Resource, error) {
 	if config.PodCIDR == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.PodCIDR must not be empty", config)
 	}
+	if config.Resolver == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Resolver must not be empty", config)
+	}
 	if config.BaseDomain == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.BaseDomain must not be empty", config)
 	}
+	if config.ClusterDomain == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClusterDomain must not be empty", config)
+	}
 	if config.ClusterIPRange == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.ClusterIPRange must not be empty", config)
 	}
@@ -75,16 +111,25 @@ func New(config Config) (*Resource, error) {
 	}
 
 	r := &Resource{
-		k8sClient: config.K8sClient,
-		logger:    config.Logger,
-		podCIDR:   config.PodCIDR,
+		cni:         config.CNI,
+		extraValues: config.ExtraValues,
+		k8sClient:   config.K8sClient,
+		logger:      config.Logger,
+		podCIDR:     config.PodCIDR,
+		recorder:    config.Recorder,
+		resolver:    config.Resolver,
 
 		baseDomain:          strings.TrimPrefix(config.BaseDomain, "k8s."),
+		clusterDomain:       config.ClusterDomain,
 		clusterIPRange:      config.ClusterIPRange,
 		dnsIP:               config.DNSIP,
 		managementClusterID: config.ManagementClusterID,
+		metadataAnnotations: config.MetadataAnnotations,
+		metadataLabels:      config.MetadataLabels,
 		registryDomain:      config.RegistryDomain,
 		proxy:               config.Proxy,
+
+		awsManagementAccountID: config.AWSManagementAccountID,
 	}
 
 	return r, nil
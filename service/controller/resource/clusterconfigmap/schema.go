@@ -0,0 +1,57 @@
+package clusterconfigmap
+
+import (
+	"encoding/json"
+
+	"github.com/giantswarm/microerror"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/jsonschema"
+)
+
+const (
+	// SchemaVersion is the version of the cluster values contract rendered
+	// into ClusterValuesConfig.SchemaVersion. Bump the major version for
+	// every change which removes or renames a key or changes its type.
+	SchemaVersion = "1.5.0"
+
+	schemaID = "https://github.com/giantswarm/cluster-apps-operator/blob/main/docs/cluster-values.schema.json"
+)
+
+// Schema returns the JSON schema of the cluster values generated from
+// ClusterValuesConfig.
+func Schema() *jsonschema.Schema {
+	s := jsonschema.Generate(ClusterValuesConfig{})
+	s.ID = schemaID
+	s.Title = "Cluster values"
+	s.Properties["schemaVersion"].Const = SchemaVersion
+
+	return s
+}
+
+// SchemaJSON returns the indented JSON representation of Schema.
+func SchemaJSON() ([]byte, error) {
+	b, err := json.MarshalIndent(Schema(), "", "  ")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return append(b, '\n'), nil
+}
+
+func validateClusterValues(values []byte) error {
+	var doc interface{}
+	err := yaml.Unmarshal(values, &doc)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = Schema().Validate(doc)
+	if jsonschema.IsValidationFailed(err) {
+		return microerror.Maskf(invalidClusterValuesError, "%s", err.Error())
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
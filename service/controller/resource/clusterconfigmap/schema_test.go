@@ -0,0 +1,114 @@
+package clusterconfigmap
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/jsonschema"
+)
+
+// Test_Schema_Published ensures the published schema matches the Go types.
+// When changing ClusterValuesConfig regenerate it with `make
+// generate-values-schema` and bump SchemaVersion accordingly.
+func Test_Schema_Published(t *testing.T) {
+	published, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "docs", "cluster-values.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := SchemaJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(published, generated) {
+		t.Fatalf("docs/cluster-values.schema.json is out of date, run `make generate-values-schema`")
+	}
+
+	_, err = jsonschema.Parse(published)
+	if err != nil {
+		t.Fatalf("docs/cluster-values.schema.json uses keywords not supported by the validator: %#v", err)
+	}
+}
+
+func Test_validateClusterValues(t *testing.T) {
+	testCases := []struct {
+		name          string
+		values        string
+		expectedError bool
+	}{
+		{
+			name:          "case 0: rendered default values are valid",
+			values:        validValuesYAML,
+			expectedError: false,
+		},
+		{
+			name:          "case 1: unknown schema version is invalid",
+			values:        strings.Replace(validValuesYAML, "schemaVersion: 1.5.0", "schemaVersion: 0.1.0", 1),
+			expectedError: true,
+		},
+		{
+			name:          "case 2: missing key is invalid",
+			values:        strings.Replace(validValuesYAML, "gcpProject: \"\"\n", "", 1),
+			expectedError: true,
+		},
+		{
+			name:          "case 3: unknown key is invalid",
+			values:        validValuesYAML + "unknown: true\n",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateClusterValues([]byte(tc.values))
+			if tc.expectedError && !IsInvalidClusterValues(err) {
+				t.Fatalf("expected invalid cluster values error, got %#v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+		})
+	}
+}
+
+const validValuesYAML = `schemaVersion: 1.5.0
+baseDomain: eggs2.example.com
+bootstrapMode:
+  apiServerPodPort: 6443
+  enabled: true
+chartOperator:
+  cni:
+    install: true
+ciliumNetworkPolicy:
+  enabled: true
+cluster:
+  calico:
+    CIDR: 192.168.0.0/16
+  cni:
+    kind: ""
+    podCIDR: 192.168.0.0/16
+  kubernetes:
+    API:
+      clusterIPRange: 172.31.0.0/16
+    clusterDomain: cluster.local
+    DNS:
+      IP: 172.31.0.10
+  private: false
+clusterCA: ""
+clusterCIDR: ""
+clusterDNSIP: 172.31.0.10
+clusterID: eggs2
+gcpProject: ""
+metadata:
+  annotations: {}
+  description: ""
+  labels: {}
+  organization: test
+  releaseVersion: 25.0.0
+provider: aws
+subscriptionID: ""
+`
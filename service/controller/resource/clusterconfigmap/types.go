@@ -21,31 +21,90 @@ type ChartOperatorHelmConfig struct {
 }
 type KubernetesConfig struct {
 	API map[string]string `json:"API"`
-	DNS map[string]string `json:"DNS"`
+	// ClusterDomain is the internal Kubernetes domain of the cluster.
+	ClusterDomain string            `json:"clusterDomain"`
+	DNS           map[string]string `json:"DNS"`
+}
+type CNIConfig struct {
+	// Kind is the detected CNI of the cluster, e.g. cilium or calico. It is
+	// empty when the CNI is not known.
+	Kind string `json:"kind"`
+	// PodCIDR is the pod CIDR of the cluster for any CNI. It is also
+	// rendered as calico.CIDR for existing consumers.
+	PodCIDR string `json:"podCIDR"`
 }
 type ClusterConfig struct {
 	Calico     map[string]string `json:"calico"`
+	CNI        CNIConfig         `json:"cni"`
 	Kubernetes KubernetesConfig  `json:"kubernetes"`
 	Private    bool              `json:"private"`
 }
+type MetadataConfig struct {
+	Annotations map[string]string `json:"annotations"`
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels"`
+	// Organization is taken from the organization label of the Cluster or
+	// the org-* namespace it lives in.
+	Organization   string `json:"organization"`
+	ReleaseVersion string `json:"releaseVersion"`
+}
 type CiliumNetworkPolicy struct {
 	Enabled bool `json:"enabled"`
 }
+type AWSSubnetConfig struct {
+	AvailabilityZone string `json:"availabilityZone"`
+	CIDR             string `json:"cidr"`
+	ID               string `json:"id"`
+	Public           bool   `json:"public"`
+}
+type AWSVPCConfig struct {
+	CIDR string `json:"cidr"`
+	ID   string `json:"id"`
+}
+type AWSConfig struct {
+	AccountID string            `json:"accountID"`
+	Region    string            `json:"region"`
+	Subnets   []AWSSubnetConfig `json:"subnets"`
+	VPC       AWSVPCConfig      `json:"vpc"`
+}
+type AzureVNetConfig struct {
+	CIDR          string `json:"cidr"`
+	Name          string `json:"name"`
+	ResourceGroup string `json:"resourceGroup"`
+}
+type AzureConfig struct {
+	Location       string          `json:"location"`
+	ResourceGroup  string          `json:"resourceGroup"`
+	SubscriptionID string          `json:"subscriptionID"`
+	TenantID       string          `json:"tenantID"`
+	VNet           AzureVNetConfig `json:"vnet"`
+}
 type ClusterValuesConfig struct {
-	BaseDomain string `json:"baseDomain"`
+	// SchemaVersion is the version of the cluster values contract, see
+	// SchemaVersion and docs/cluster-values.schema.json.
+	SchemaVersion string `json:"schemaVersion"`
+
+	// AWS is only set on AWS clusters.
+	AWS *AWSConfig `json:"aws,omitempty"`
+	// Azure is only set on Azure clusters.
+	Azure      *AzureConfig `json:"azure,omitempty"`
+	BaseDomain string       `json:"baseDomain"`
 	// BootstrapMode allows to configure chart-operator in bootstrap mode so that it can install charts without cni or kube-proxy.
 	BootstrapMode ChartOperatorBootstrapMode `json:"bootstrapMode"`
 	Cluster       ClusterConfig              `json:"cluster"`
 	ClusterCA     string                     `json:"clusterCA"`
 	// ClusterDNSIP is used by chart-operator. It uses this IP as its dnsConfig nameserver, to use it as resolver.
-	ClusterDNSIP        string                   `json:"clusterDNSIP"`
-	ClusterID           string                   `json:"clusterID"`
-	ClusterCIDR         string                   `json:"clusterCIDR"`
-	ExternalDNSIP       *string                  `json:"externalDNSIP,omitempty"`
-	Helm                *ChartOperatorHelmConfig `json:"helm,omitempty"`
-	Provider            string                   `json:"provider"`
-	GcpProject          string                   `json:"gcpProject"`
-	ChartOperator       ChartOperatorConfig      `json:"chartOperator"`
-	CiliumNetworkPolicy CiliumNetworkPolicy      `json:"ciliumNetworkPolicy"`
-	AzureSubscriptionID string                   `json:"subscriptionID"`
+	ClusterDNSIP  string                   `json:"clusterDNSIP"`
+	ClusterID     string                   `json:"clusterID"`
+	ClusterCIDR   string                   `json:"clusterCIDR"`
+	ExternalDNSIP *string                  `json:"externalDNSIP,omitempty"`
+	Helm          *ChartOperatorHelmConfig `json:"helm,omitempty"`
+	Provider      string                   `json:"provider"`
+	GcpProject    string                   `json:"gcpProject"`
+	// Metadata exposes the owning organization, release and allowlisted
+	// labels and annotations of the Cluster.
+	Metadata            MetadataConfig      `json:"metadata"`
+	ChartOperator       ChartOperatorConfig `json:"chartOperator"`
+	CiliumNetworkPolicy CiliumNetworkPolicy `json:"ciliumNetworkPolicy"`
+	AzureSubscriptionID string              `json:"subscriptionID"`
 }
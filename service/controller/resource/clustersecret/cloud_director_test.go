@@ -0,0 +1,73 @@
+package clustersecret
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+// Test_Resource_VCDClusterVersion ensures the VCDCluster is fetched in the
+// v1beta1 version it is decoded into, even when the reference pins and the
+// API server prefers a newer version.
+func Test_Resource_VCDClusterVersion(t *testing.T) {
+	cluster := newTestCluster("VCDCluster", "infrastructure.cluster.x-k8s.io/v1beta3", nil)
+
+	objects := []runtime.Object{
+		// Only registers v1beta3 as the preferred version.
+		newTestObject("infrastructure.cluster.x-k8s.io/v1beta3", "VCDCluster", "org-test", "other", map[string]interface{}{}),
+		newTestObject("infrastructure.cluster.x-k8s.io/v1beta1", "VCDCluster", "org-test", "eggs2", map[string]interface{}{
+			"site": "https://vcd.example.com",
+			"userContext": map[string]interface{}{
+				"secretRef": map[string]interface{}{
+					"name":      "vcd-credentials",
+					"namespace": "org-test",
+				},
+			},
+		}),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vcd-credentials",
+				Namespace: "org-test",
+			},
+			Data: map[string][]byte{
+				"refreshToken": []byte("token"),
+			},
+		},
+	}
+
+	ctrlClient := clientfake.NewClientBuilder().
+		WithRESTMapper(infrastructuretest.NewRESTMapper(objects...)).
+		WithRuntimeObjects(objects...).
+		Build()
+
+	r, err := New(Config{
+		K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+			CtrlClient: ctrlClient,
+		}),
+		Logger:   microloggertest.New(),
+		Resolver: infrastructuretest.NewResolver(t, ctrlClient),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets, err := r.GetDesiredState(context.Background(), cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range secrets {
+		if !strings.Contains(string(s.Data["values"]), "https://vcd.example.com") {
+			t.Fatalf("expected secret %#q to contain the VCD site, got %s", s.Name, s.Data["values"])
+		}
+	}
+}
@@ -24,7 +24,7 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) ([]*cor
 		r.logger.Debugf(ctx, "finding cluster secrets in namespace %#q", cr.GetNamespace())
 
 		lo := metav1.ListOptions{
-			LabelSelector: fmt.Sprintf("%s=%s,%s=%s", label.Cluster, key.ClusterID(&cr), label.ManagedBy, project.Name()),
+			LabelSelector: fmt.Sprintf("%s=%s,%s=%s", label.Cluster, key.ClusterID(cr), label.ManagedBy, project.Name()),
 		}
 
 		list, err := r.k8sClient.K8sClient().CoreV1().Secrets(cr.GetNamespace()).List(ctx, lo)
@@ -10,18 +10,18 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/yaml"
 
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/k8smetadata/pkg/label"
 	"github.com/giantswarm/microerror"
 
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
-
 	capvcd "github.com/giantswarm/cluster-apps-operator/v3/api/capvcd/v1beta1"
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
 	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
 	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/privatecluster"
 )
@@ -48,24 +48,31 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 
 	var secrets []*corev1.Secret
 
-	if key.IsDeleted(&cr) {
-		r.logger.Debugf(ctx, "deleting cluster secrets for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(&cr))
+	if key.IsDeleted(cr) {
+		r.logger.Debugf(ctx, "deleting cluster secrets for cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(cr))
 		return secrets, nil
 	}
 
 	values := map[string]interface{}{}
-	privateCluster, err := privatecluster.IsPrivateCluster(ctx, r.logger, r.k8sClient.CtrlClient(), cr)
+	privateCluster, err := privatecluster.IsPrivateCluster(ctx, r.logger, r.resolver, cr)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	{
-		infrastructureRef := cr.Spec.InfrastructureRef
+		infrastructureRef := key.InfrastructureRef(cr)
 		if infrastructureRef != nil {
 			switch infrastructureRef.Kind {
 			case infra.VCDClusterKind:
+				// The object is converted into the typed v1beta1 VCDCluster, so
+				// it must be fetched in exactly that version.
+				obj, err := r.resolver.Get(ctx, cr, capvcd.SchemeGroupVersion.WithKind(infra.VCDClusterKind), infrastructureRef.Namespace, infrastructureRef.Name)
+				if err != nil {
+					return nil, microerror.Mask(err)
+				}
+
 				var infraCluster capvcd.VCDCluster
-				err = r.k8sClient.CtrlClient().Get(ctx, client.ObjectKey{Namespace: infrastructureRef.Namespace, Name: infrastructureRef.Name}, &infraCluster)
+				err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &infraCluster)
 				if err != nil {
 					return nil, microerror.Mask(err)
 				}
@@ -90,18 +97,42 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 		}
 	}
 
+	{
+		identity, err := r.generateProviderIdentity(ctx, cr)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// Provider identities are only ever rendered into the secret, never
+		// into the cluster values ConfigMap.
+		if identity != nil {
+			global, _ := values["global"].(map[string]interface{})
+			if global == nil {
+				global = map[string]interface{}{}
+			}
+			global["providerIdentity"] = identity
+			values["global"] = global
+		}
+	}
+
 	secretSpecs := []secretSpec{
 		{
-			Name:      key.ClusterValuesResourceName(&cr),
+			Name:      key.ClusterValuesResourceName(cr),
 			Namespace: cr.GetNamespace(),
 			Data:      map[string][]byte{},
 		},
 	}
 
+	var effectiveProxy *proxy.Proxy
 	if privateCluster && !reflect.ValueOf(r.proxy).IsZero() {
-		r.logger.Debugf(ctx, "proxy secrets for cluster '%s/%s' : %v", cr.GetNamespace(), key.ClusterID(&cr), r.proxy)
+		r.logger.Debugf(ctx, "proxy secrets for cluster '%s/%s' : %v", cr.GetNamespace(), key.ClusterID(cr), r.proxy)
 
 		noProxy := noProxy(cr, r.proxy.NoProxy)
+		effectiveProxy = &proxy.Proxy{
+			NoProxy:    noProxy,
+			HttpProxy:  r.proxy.HttpProxy,
+			HttpsProxy: r.proxy.HttpsProxy,
+		}
 
 		// The three values below are specific to cert-manager because
 		// we use upstream chart schema.
@@ -140,7 +171,7 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 		containerdProxy := tpl.String()
 
 		secretSpecs = append(secretSpecs, secretSpec{
-			Name:      fmt.Sprintf("%s-systemd-proxy", key.ClusterID(&cr)),
+			Name:      fmt.Sprintf("%s-systemd-proxy", key.ClusterID(cr)),
 			Namespace: cr.GetNamespace(),
 			Data: map[string][]byte{
 				containerdProxySection: []byte(containerdProxy),
@@ -148,6 +179,18 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 		})
 	}
 
+	extraValues, err := r.extraValues.SecretValues(ctx, cr)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Extra values only add keys, the generated values take precedence.
+	// Allowlisted topology variables take precedence over the generated
+	// values.
+	if len(extraValues.Sources) > 0 {
+		values = extraValues.Render(values)
+	}
+
 	yamlValues, err := yaml.Marshal(values)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -155,24 +198,34 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) ([]*cor
 
 	secretSpecs[0].Data[mainSecretSection] = []byte(yamlValues)
 
-	for _, spec := range secretSpecs {
+	r.recorder.RecordProxy(cr, effectiveProxy)
+
+	for i, spec := range secretSpecs {
 		secret := newSecret(cr, spec)
+		if i == 0 && len(extraValues.Sources) > 0 {
+			secret.Annotations[extravalues.AnnotationSources] = extravalues.SourcesAnnotation(extraValues.Sources)
+		}
 		secrets = append(secrets, secret)
+
+		for _, data := range spec.Data {
+			r.recorder.RecordValues(cr, "secret", spec.Name, data)
+		}
 	}
 
 	return secrets, nil
 }
 
-func newSecret(cr capi.Cluster, secretSpec secretSpec) *corev1.Secret {
+func newSecret(cr key.Cluster, secretSpec secretSpec) *corev1.Secret {
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretSpec.Name,
-			Namespace: secretSpec.Namespace,
+			Name:            secretSpec.Name,
+			Namespace:       secretSpec.Namespace,
+			OwnerReferences: key.ClusterOwnerReferences(cr),
 			Annotations: map[string]string{
 				annotation.Notes: fmt.Sprintf("DO NOT EDIT. Values managed by %s.", project.Name()),
 			},
 			Labels: map[string]string{
-				label.Cluster:   key.ClusterID(&cr),
+				label.Cluster:   key.ClusterID(cr),
 				label.ManagedBy: project.Name(),
 			},
 		},
@@ -180,28 +233,29 @@ func newSecret(cr capi.Cluster, secretSpec secretSpec) *corev1.Secret {
 	}
 }
 
-func noProxy(cluster capi.Cluster, globalNoProxy string) string {
+func noProxy(cluster key.Cluster, globalNoProxy string) string {
 
 	// generic list of noProxy
 	// will be joined with custom defined noProxy targets
 
 	var appendString []string
-	if !reflect.ValueOf(cluster.Spec.ClusterNetwork).IsZero() {
-		if !reflect.ValueOf(cluster.Spec.ClusterNetwork.ServiceDomain).IsZero() {
-			appendString = append(appendString, cluster.Spec.ClusterNetwork.ServiceDomain)
+	{
+		clusterNetwork := key.ClusterNetworkOf(cluster)
+		if clusterNetwork.ServiceDomain != "" {
+			appendString = append(appendString, clusterNetwork.ServiceDomain)
 		}
 
-		if !reflect.ValueOf(cluster.Spec.ClusterNetwork.Services).IsZero() && !reflect.ValueOf(cluster.Spec.ClusterNetwork.Services.CIDRBlocks).IsZero() {
-			appendString = append(appendString, strings.Join(cluster.Spec.ClusterNetwork.Services.CIDRBlocks, ","))
+		if len(clusterNetwork.Services) > 0 {
+			appendString = append(appendString, strings.Join(clusterNetwork.Services, ","))
 		}
 
-		if !reflect.ValueOf(cluster.Spec.ClusterNetwork.Pods).IsZero() && !reflect.ValueOf(cluster.Spec.ClusterNetwork.Pods.CIDRBlocks).IsZero() {
-			appendString = append(appendString, strings.Join(cluster.Spec.ClusterNetwork.Pods.CIDRBlocks, ","))
+		if len(clusterNetwork.Pods) > 0 {
+			appendString = append(appendString, strings.Join(clusterNetwork.Pods, ","))
 		}
 	}
 
-	if !reflect.ValueOf(cluster.Spec.ControlPlaneEndpoint.Host).IsZero() {
-		appendString = append(appendString, cluster.Spec.ControlPlaneEndpoint.Host)
+	if host := key.ControlPlaneEndpointHost(cluster); host != "" {
+		appendString = append(appendString, host)
 	}
 
 	if len(globalNoProxy) > 0 {
@@ -0,0 +1,82 @@
+package clustersecret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/golden"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+// Test_Golden renders the Secrets of every case in the shared golden test
+// data and compares them against its secrets.golden.yaml. Run it with
+// UPDATE_GOLDEN=1 to regenerate the golden files.
+func Test_Golden(t *testing.T) {
+	scheme := golden.NewScheme(t)
+
+	for _, tc := range golden.Cases(t, "../testdata/golden", scheme) {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(infrastructuretest.NewRESTMapper(tc.Objects...)).
+				WithRuntimeObjects(tc.Objects...).
+				Build()
+
+			extraValues, err := extravalues.New(extravalues.Config{
+				CtrlClient: ctrlClient,
+				Logger:     microloggertest.New(),
+
+				TopologyVariablesSecret: []string{"sshKey=ssh.authorizedKey"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := Config{
+				ExtraValues: extraValues,
+				K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+					CtrlClient: ctrlClient,
+				}),
+				Logger: microloggertest.New(),
+				Proxy: proxy.Proxy{
+					HttpProxy:  "http://proxy.example.com:3128",
+					HttpsProxy: "http://proxy.example.com:3128",
+					NoProxy:    "internal.example.com",
+				},
+				Resolver: infrastructuretest.NewResolver(t, ctrlClient),
+			}
+
+			r, err := New(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			secrets, err := r.GetDesiredState(context.Background(), tc.Cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Secret data is rendered as stringData so the golden files
+			// show the values instead of their base64 encoding.
+			var objects []interface{}
+			for _, s := range secrets {
+				s = s.DeepCopy()
+				s.StringData = map[string]string{}
+				for k, v := range s.Data {
+					s.StringData[k] = string(v)
+				}
+				s.Data = nil
+
+				objects = append(objects, s)
+			}
+
+			golden.Assert(t, tc.Path("secrets.golden.yaml"), objects...)
+		})
+	}
+}
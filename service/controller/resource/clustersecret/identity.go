@@ -0,0 +1,165 @@
+package clustersecret
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+const (
+	awsClusterControllerIdentityKind = "AWSClusterControllerIdentity"
+	awsClusterRoleIdentityKind       = "AWSClusterRoleIdentity"
+	azureClusterIdentityKind         = "AzureClusterIdentity"
+
+	// awsDefaultIdentityName is the name of the identity CAPA uses when the
+	// infrastructure cluster has no identity reference.
+	awsDefaultIdentityName = "default"
+)
+
+// generateProviderIdentity returns the identity the infrastructure provider
+// uses for the cluster, rendered as global.providerIdentity into the values
+// secret so charts like external-dns or cluster-autoscaler can use it. It
+// returns nil for providers without identity discovery.
+func (r *Resource) generateProviderIdentity(ctx context.Context, cr key.Cluster) (map[string]interface{}, error) {
+	infrastructureRef := key.InfrastructureRef(cr)
+	if infrastructureRef == nil {
+		return nil, nil
+	}
+
+	switch infrastructureRef.Kind {
+	case infra.AWSClusterKind:
+		return r.awsIdentity(ctx, cr, infrastructureRef)
+	case infra.AWSManagedClusterKind:
+		// EKS clusters keep the identity on the AWSManagedControlPlane.
+		controlPlaneRef := key.ControlPlaneRef(cr)
+		if controlPlaneRef == nil {
+			return nil, nil
+		}
+		return r.awsIdentity(ctx, cr, controlPlaneRef)
+	case infra.AzureClusterKind:
+		return r.azureIdentity(ctx, cr, infrastructureRef)
+	case infra.AzureManagedClusterKind:
+		// AKS clusters keep the identity on the AzureManagedControlPlane.
+		controlPlaneRef := key.ControlPlaneRef(cr)
+		if controlPlaneRef == nil {
+			return nil, nil
+		}
+		return r.azureIdentity(ctx, cr, controlPlaneRef)
+	case infra.GCPClusterKind, infra.GCPManagedClusterKind:
+		return r.gcpIdentity(ctx, cr, infrastructureRef)
+	}
+
+	return nil, nil
+}
+
+func (r *Resource) awsIdentity(ctx context.Context, cr key.Cluster, ownerRef *key.ObjectReference) (map[string]interface{}, error) {
+	owner, err := r.resolver.Get(ctx, cr, ownerRef.GroupVersionKind(), ownerRef.Namespace, ownerRef.Name)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	kind, _, _ := unstructured.NestedString(owner.Object, "spec", "identityRef", "kind")
+	name, _, _ := unstructured.NestedString(owner.Object, "spec", "identityRef", "name")
+	if kind == "" {
+		kind = awsClusterControllerIdentityKind
+		name = awsDefaultIdentityName
+	}
+
+	identity := map[string]interface{}{
+		"provider": infra.AWSClusterKindProvider,
+		"kind":     kind,
+		"name":     name,
+	}
+
+	if kind == awsClusterRoleIdentityKind {
+		// AWS identities are cluster scoped and served by the
+		// infrastructure provider group.
+		obj, err := r.resolver.Get(ctx, cr, schema.GroupVersionKind{Group: infra.Group, Kind: kind}, "", name)
+		if infra.IsNotFound(err) {
+			r.logger.Debugf(ctx, "%s %#q not found, rendering reference only", kind, name)
+			return identity, nil
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		roleARN, _, _ := unstructured.NestedString(obj.Object, "spec", "roleARN")
+		identity["roleARN"] = roleARN
+	}
+
+	return identity, nil
+}
+
+func (r *Resource) azureIdentity(ctx context.Context, cr key.Cluster, ownerRef *key.ObjectReference) (map[string]interface{}, error) {
+	owner, err := r.resolver.Get(ctx, cr, ownerRef.GroupVersionKind(), ownerRef.Namespace, ownerRef.Name)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	kind, _, _ := unstructured.NestedString(owner.Object, "spec", "identityRef", "kind")
+	name, _, _ := unstructured.NestedString(owner.Object, "spec", "identityRef", "name")
+	namespace, _, _ := unstructured.NestedString(owner.Object, "spec", "identityRef", "namespace")
+	if name == "" {
+		return nil, nil
+	}
+	if kind == "" {
+		kind = azureClusterIdentityKind
+	}
+	if namespace == "" {
+		namespace = ownerRef.Namespace
+	}
+
+	identity := map[string]interface{}{
+		"provider":  infra.AzureClusterKindProvider,
+		"kind":      kind,
+		"name":      name,
+		"namespace": namespace,
+	}
+
+	obj, err := r.resolver.Get(ctx, cr, schema.GroupVersionKind{Group: infra.Group, Kind: kind}, namespace, name)
+	if infra.IsNotFound(err) {
+		r.logger.Debugf(ctx, "%s '%s/%s' not found, rendering reference only", kind, namespace, name)
+		return identity, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, field := range []string{"clientID", "tenantID", "type"} {
+		value, _, _ := unstructured.NestedString(obj.Object, "spec", field)
+		identity[field] = value
+	}
+
+	return identity, nil
+}
+
+func (r *Resource) gcpIdentity(ctx context.Context, cr key.Cluster, infrastructureRef *key.ObjectReference) (map[string]interface{}, error) {
+	obj, err := r.resolver.Get(ctx, cr, infrastructureRef.GroupVersionKind(), infrastructureRef.Namespace, infrastructureRef.Name)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	name, _, _ := unstructured.NestedString(obj.Object, "spec", "credentialsRef", "name")
+	namespace, _, _ := unstructured.NestedString(obj.Object, "spec", "credentialsRef", "namespace")
+	if name == "" {
+		return nil, nil
+	}
+	if namespace == "" {
+		namespace = infrastructureRef.Namespace
+	}
+
+	// Only the reference to the service account secret is rendered, the
+	// key itself stays in the management cluster.
+	identity := map[string]interface{}{
+		"provider": infra.GCPClusterKindProvider,
+		"credentialsSecret": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,186 @@
+package clustersecret
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+func Test_generateProviderIdentity(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cluster          *capi.Cluster
+		objects          []runtime.Object
+		expectedIdentity map[string]interface{}
+	}{
+		{
+			name:    "case 0: AWSCluster with role identity",
+			cluster: newTestCluster("AWSCluster", "infrastructure.cluster.x-k8s.io/v1beta2", nil),
+			objects: []runtime.Object{
+				newTestObject("infrastructure.cluster.x-k8s.io/v1beta2", "AWSCluster", "org-test", "eggs2", map[string]interface{}{
+					"identityRef": map[string]interface{}{"kind": "AWSClusterRoleIdentity", "name": "eggs2"},
+				}),
+				newTestObject("infrastructure.cluster.x-k8s.io/v1beta2", "AWSClusterRoleIdentity", "", "eggs2", map[string]interface{}{
+					"roleARN": "arn:aws:iam::123456789012:role/capa",
+				}),
+			},
+			expectedIdentity: map[string]interface{}{
+				"provider": "capa",
+				"kind":     "AWSClusterRoleIdentity",
+				"name":     "eggs2",
+				"roleARN":  "arn:aws:iam::123456789012:role/capa",
+			},
+		},
+		{
+			name:    "case 1: AWSCluster without identity uses the default controller identity",
+			cluster: newTestCluster("AWSCluster", "infrastructure.cluster.x-k8s.io/v1beta2", nil),
+			objects: []runtime.Object{
+				newTestObject("infrastructure.cluster.x-k8s.io/v1beta2", "AWSCluster", "org-test", "eggs2", map[string]interface{}{}),
+			},
+			expectedIdentity: map[string]interface{}{
+				"provider": "capa",
+				"kind":     "AWSClusterControllerIdentity",
+				"name":     "default",
+			},
+		},
+		{
+			name: "case 2: AWSManagedCluster reads the identity from the control plane",
+			cluster: newTestCluster("AWSManagedCluster", "infrastructure.cluster.x-k8s.io/v1beta2", &corev1.ObjectReference{
+				APIVersion: "controlplane.cluster.x-k8s.io/v1beta2",
+				Kind:       "AWSManagedControlPlane",
+				Namespace:  "org-test",
+				Name:       "eggs2",
+			}),
+			objects: []runtime.Object{
+				newTestObject("controlplane.cluster.x-k8s.io/v1beta2", "AWSManagedControlPlane", "org-test", "eggs2", map[string]interface{}{
+					"identityRef": map[string]interface{}{"kind": "AWSClusterRoleIdentity", "name": "eks"},
+				}),
+				newTestObject("infrastructure.cluster.x-k8s.io/v1beta2", "AWSClusterRoleIdentity", "", "eks", map[string]interface{}{
+					"roleARN": "arn:aws:iam::123456789012:role/eks",
+				}),
+			},
+			expectedIdentity: map[string]interface{}{
+				"provider": "capa",
+				"kind":     "AWSClusterRoleIdentity",
+				"name":     "eks",
+				"roleARN":  "arn:aws:iam::123456789012:role/eks",
+			},
+		},
+		{
+			name:    "case 3: AzureCluster with cluster identity",
+			cluster: newTestCluster("AzureCluster", "infrastructure.cluster.x-k8s.io/v1beta1", nil),
+			objects: []runtime.Object{
+				newTestObject("infrastructure.cluster.x-k8s.io/v1beta1", "AzureCluster", "org-test", "eggs2", map[string]interface{}{
+					"identityRef": map[string]interface{}{"kind": "AzureClusterIdentity", "name": "capz", "namespace": "giantswarm"},
+				}),
+				newTestObject("infrastructure.cluster.x-k8s.io/v1beta1", "AzureClusterIdentity", "giantswarm", "capz", map[string]interface{}{
+					"clientID": "client-id",
+					"tenantID": "tenant-id",
+					"type":     "WorkloadIdentity",
+				}),
+			},
+			expectedIdentity: map[string]interface{}{
+				"provider":  "capz",
+				"kind":      "AzureClusterIdentity",
+				"name":      "capz",
+				"namespace": "giantswarm",
+				"clientID":  "client-id",
+				"tenantID":  "tenant-id",
+				"type":      "WorkloadIdentity",
+			},
+		},
+		{
+			name:    "case 4: GCPCluster with credentials reference",
+			cluster: newTestCluster("GCPCluster", "infrastructure.cluster.x-k8s.io/v1beta1", nil),
+			objects: []runtime.Object{
+				newTestObject("infrastructure.cluster.x-k8s.io/v1beta1", "GCPCluster", "org-test", "eggs2", map[string]interface{}{
+					"credentialsRef": map[string]interface{}{"name": "eggs2-gcp"},
+				}),
+			},
+			expectedIdentity: map[string]interface{}{
+				"provider": "gcp",
+				"credentialsSecret": map[string]interface{}{
+					"name":      "eggs2-gcp",
+					"namespace": "org-test",
+				},
+			},
+		},
+		{
+			name:             "case 5: providers without identity discovery",
+			cluster:          newTestCluster("VSphereCluster", "infrastructure.cluster.x-k8s.io/v1beta1", nil),
+			expectedIdentity: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			ctrlClient := clientfake.NewClientBuilder().
+				WithRESTMapper(infrastructuretest.NewRESTMapper(tc.objects...)).
+				WithRuntimeObjects(tc.objects...).
+				Build()
+
+			r := &Resource{
+				k8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+					CtrlClient: ctrlClient,
+				}),
+				logger:   microloggertest.New(),
+				resolver: infrastructuretest.NewResolver(t, ctrlClient),
+			}
+
+			identity, err := r.generateProviderIdentity(context.Background(), tc.cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(identity, tc.expectedIdentity) {
+				t.Fatalf("identity == %#v, want %#v", identity, tc.expectedIdentity)
+			}
+		})
+	}
+}
+
+func newTestCluster(kind, apiVersion string, controlPlaneRef *corev1.ObjectReference) *capi.Cluster {
+	return &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "eggs2",
+			Namespace: "org-test",
+		},
+		Spec: capi.ClusterSpec{
+			ControlPlaneRef: controlPlaneRef,
+			InfrastructureRef: &corev1.ObjectReference{
+				APIVersion: apiVersion,
+				Kind:       kind,
+				Namespace:  "org-test",
+				Name:       "eggs2",
+			},
+		},
+	}
+}
+
+func newTestObject(apiVersion, kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	return obj
+}
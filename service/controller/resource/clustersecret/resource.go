@@ -6,6 +6,9 @@ import (
 	"github.com/giantswarm/micrologger"
 
 	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
 )
 
 const (
@@ -16,16 +19,22 @@ const (
 // Config represents the configuration used to create a new clustersecret
 // resource.
 type Config struct {
-	K8sClient k8sclient.Interface
-	Logger    micrologger.Logger
-	Proxy     proxy.Proxy
+	ExtraValues *extravalues.ExtraValues
+	K8sClient   k8sclient.Interface
+	Logger      micrologger.Logger
+	Proxy       proxy.Proxy
+	Recorder    *clusterstatus.Recorder
+	Resolver    *infra.Resolver
 }
 
 // Resource implements the clustersecret resource.
 type Resource struct {
-	k8sClient k8sclient.Interface
-	logger    micrologger.Logger
-	proxy     proxy.Proxy
+	extraValues *extravalues.ExtraValues
+	k8sClient   k8sclient.Interface
+	logger      micrologger.Logger
+	proxy       proxy.Proxy
+	recorder    *clusterstatus.Recorder
+	resolver    *infra.Resolver
 }
 
 // New creates a new configured secret state getter resource managing
@@ -39,11 +48,17 @@ func New(config Config) (*Resource, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.Resolver == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Resolver must not be empty", config)
+	}
 
 	r := &Resource{
-		k8sClient: config.K8sClient,
-		logger:    config.Logger,
-		proxy:     config.Proxy,
+		extraValues: config.ExtraValues,
+		k8sClient:   config.K8sClient,
+		logger:      config.Logger,
+		proxy:       config.Proxy,
+		recorder:    config.Recorder,
+		resolver:    config.Resolver,
 	}
 
 	return r, nil
@@ -5,15 +5,16 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
 )
 
-func vsphereProxyEnabled(ctx context.Context, ctrlClient client.Client, cluster capi.Cluster) (bool, error) {
+func vsphereProxyEnabled(ctx context.Context, ctrlClient client.Client, cluster key.Cluster) (bool, error) {
 	var userConfig string
 	{
 		var clusterApp appv1alpha1.App
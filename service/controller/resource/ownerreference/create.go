@@ -0,0 +1,80 @@
+package ownerreference
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	cr, err := key.ToCluster(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	patch, err := key.ClusterOwnerReferencesPatch(cr)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	lo := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s=%s", label.Cluster, key.ClusterID(cr), label.ManagedBy, project.Name()),
+	}
+
+	{
+		configMaps := r.k8sClient.K8sClient().CoreV1().ConfigMaps(cr.GetNamespace())
+
+		list, err := configMaps.List(ctx, lo)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, item := range list.Items {
+			if key.HasClusterOwnerReferences(cr, &item) {
+				continue
+			}
+
+			r.logger.Debugf(ctx, "adding owner references to configmap %#q in namespace %#q", item.Name, item.Namespace)
+
+			_, err = configMaps.Patch(ctx, item.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			r.logger.Debugf(ctx, "added owner references to configmap %#q in namespace %#q", item.Name, item.Namespace)
+		}
+	}
+
+	{
+		secrets := r.k8sClient.K8sClient().CoreV1().Secrets(cr.GetNamespace())
+
+		list, err := secrets.List(ctx, lo)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, item := range list.Items {
+			if key.HasClusterOwnerReferences(cr, &item) {
+				continue
+			}
+
+			r.logger.Debugf(ctx, "adding owner references to secret %#q in namespace %#q", item.Name, item.Namespace)
+
+			_, err = secrets.Patch(ctx, item.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			r.logger.Debugf(ctx, "added owner references to secret %#q in namespace %#q", item.Name, item.Namespace)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package ownerreference
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclienttest"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+func Test_EnsureCreated(t *testing.T) {
+	cluster := &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "eggs2",
+			Namespace: "org-test",
+			UID:       "eggs2-uid",
+			Labels: map[string]string{
+				label.Cluster: "eggs2",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		ownerReferences []metav1.OwnerReference
+		expectedOwners  int
+	}{
+		{
+			name:           "case 0: missing owner references are added",
+			expectedOwners: 1,
+		},
+		{
+			name:            "case 1: existing owner references are kept",
+			ownerReferences: key.ClusterOwnerReferences(cluster),
+			expectedOwners:  1,
+		},
+		{
+			name: "case 2: owner references of other objects are kept",
+			ownerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Name:       "other",
+					UID:        "other-uid",
+				},
+			},
+			expectedOwners: 2,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			meta := metav1.ObjectMeta{
+				Name:      "eggs2-cluster-values",
+				Namespace: "org-test",
+				Labels: map[string]string{
+					label.Cluster:   "eggs2",
+					label.ManagedBy: project.Name(),
+				},
+				OwnerReferences: tc.ownerReferences,
+			}
+
+			k8sClient := fake.NewClientset(
+				&corev1.ConfigMap{ObjectMeta: meta},
+				&corev1.Secret{ObjectMeta: meta},
+			)
+
+			r, err := New(Config{
+				K8sClient: k8sclienttest.NewClients(k8sclienttest.ClientsConfig{
+					K8sClient: k8sClient,
+				}),
+				Logger: microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = r.EnsureCreated(context.Background(), cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			configMap, err := k8sClient.CoreV1().ConfigMaps("org-test").Get(context.Background(), "eggs2-cluster-values", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			secret, err := k8sClient.CoreV1().Secrets("org-test").Get(context.Background(), "eggs2-cluster-values", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, o := range []metav1.Object{configMap, secret} {
+				if !key.HasClusterOwnerReferences(cluster, o) {
+					t.Fatalf("expected owner references of the cluster, got %#v", o.GetOwnerReferences())
+				}
+				if len(o.GetOwnerReferences()) != tc.expectedOwners {
+					t.Fatalf("expected %d owner references, got %#v", tc.expectedOwners, o.GetOwnerReferences())
+				}
+			}
+		})
+	}
+}
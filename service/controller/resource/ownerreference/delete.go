@@ -0,0 +1,11 @@
+package ownerreference
+
+import (
+	"context"
+)
+
+// EnsureDeleted is a no-op. The generated objects are deleted by the
+// clusterconfigmap and clustersecret resources.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,51 @@
+// Package ownerreference adds the owner references of the Cluster to the
+// ConfigMaps and Secrets generated for it which do not carry them yet, e.g.
+// because they were created by an older release. Owner references are not
+// part of the comparison of desired and current state of the clusterconfigmap
+// and clustersecret resources, so these would never be updated otherwise.
+package ownerreference
+
+import (
+	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "ownerreference"
+)
+
+// Config represents the configuration used to create a new ownerreference
+// resource.
+type Config struct {
+	K8sClient k8sclient.Interface
+	Logger    micrologger.Logger
+}
+
+// Resource implements the ownerreference resource.
+type Resource struct {
+	k8sClient k8sclient.Interface
+	logger    micrologger.Logger
+}
+
+// New creates a new configured ownerreference resource.
+func New(config Config) (*Resource, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resource{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+	}
+
+	return r, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -0,0 +1,32 @@
+package ratelimitresource
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var backingOffError = &microerror.Error{
+	Kind: "backingOffError",
+}
+
+// IsBackingOff asserts backingOffError.
+func IsBackingOff(err error) bool {
+	return microerror.Cause(err) == backingOffError
+}
+
+var wrongTypeError = &microerror.Error{
+	Kind: "wrongTypeError",
+}
+
+// IsWrongTypeError asserts wrongTypeError.
+func IsWrongTypeError(err error) bool {
+	return microerror.Cause(err) == wrongTypeError
+}
@@ -0,0 +1,109 @@
+package ratelimitresource
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter is shared by all resources wrapped in a single call to Wrap. It
+// keeps track of the exponential backoff of failing resources per cluster.
+type limiter struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	now             func() time.Time
+
+	mutex    sync.Mutex
+	backoffs map[string]backoffState
+}
+
+type backoffState struct {
+	failures int
+	until    time.Time
+}
+
+func newLimiter(initialInterval, maxInterval time.Duration) *limiter {
+	l := &limiter{
+		initialInterval: initialInterval,
+		maxInterval:     maxInterval,
+		now:             time.Now,
+
+		backoffs: map[string]backoffState{},
+	}
+
+	return l
+}
+
+// backingOff returns the time until which the given key is backing off, and
+// whether that time is still in the future.
+func (l *limiter) backingOff(key string) (time.Time, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.evict()
+
+	s, ok := l.backoffs[key]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return s.until, l.now().Before(s.until)
+}
+
+// failure records a failed execution for the given key and doubles its
+// backoff interval up to the configured maximum.
+func (l *limiter) failure(key string) {
+	if l.initialInterval <= 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	s := l.backoffs[key]
+	s.failures++
+
+	interval := l.initialInterval
+	for i := 1; i < s.failures && interval < l.maxInterval; i++ {
+		interval *= 2
+	}
+	if l.maxInterval > 0 && interval > l.maxInterval {
+		interval = l.maxInterval
+	}
+
+	s.until = l.now().Add(interval)
+	l.backoffs[key] = s
+
+	l.evict()
+}
+
+// success resets the backoff of the given key. This also forgets keys of
+// deleted clusters once their resources were cleaned up.
+func (l *limiter) success(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.backoffs, key)
+
+	l.evict()
+}
+
+// evict forgets keys which were not retried within the max interval after
+// their backoff expired, e.g. because the cluster is gone or moved to another
+// shard, and updates clustersInBackoffGauge with the keys still backing off.
+// It must be called with the mutex held.
+func (l *limiter) evict() {
+	now := l.now()
+
+	var active int
+	for k, s := range l.backoffs {
+		if now.After(s.until.Add(l.maxInterval)) {
+			delete(l.backoffs, k)
+			continue
+		}
+		if now.Before(s.until) {
+			active++
+		}
+	}
+
+	clustersInBackoffGauge.Set(float64(active))
+}
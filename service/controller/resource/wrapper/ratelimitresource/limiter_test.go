@@ -0,0 +1,109 @@
+package ratelimitresource
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_limiter_backoff(t *testing.T) {
+	testCases := []struct {
+		name            string
+		failures        int
+		elapsed         time.Duration
+		expectedBackoff bool
+	}{
+		{
+			name:            "case 0: no failure does not back off",
+			failures:        0,
+			elapsed:         0,
+			expectedBackoff: false,
+		},
+		{
+			name:            "case 1: first failure backs off for the initial interval",
+			failures:        1,
+			elapsed:         9 * time.Second,
+			expectedBackoff: true,
+		},
+		{
+			name:            "case 2: first failure backoff expires after the initial interval",
+			failures:        1,
+			elapsed:         10 * time.Second,
+			expectedBackoff: false,
+		},
+		{
+			name:            "case 3: third failure backs off for four times the initial interval",
+			failures:        3,
+			elapsed:         39 * time.Second,
+			expectedBackoff: true,
+		},
+		{
+			name:            "case 4: backoff is capped at the max interval",
+			failures:        10,
+			elapsed:         time.Minute,
+			expectedBackoff: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			l := newLimiter(10*time.Second, time.Minute)
+			l.now = func() time.Time { return now }
+
+			for j := 0; j < tc.failures; j++ {
+				l.failure("org-test/eggs2/app")
+			}
+
+			now = now.Add(tc.elapsed)
+
+			_, backoff := l.backingOff("org-test/eggs2/app")
+			if backoff != tc.expectedBackoff {
+				t.Fatalf("expected backoff %t got %t", tc.expectedBackoff, backoff)
+			}
+		})
+	}
+}
+
+func Test_limiter_success(t *testing.T) {
+	l := newLimiter(10*time.Second, time.Minute)
+
+	l.failure("org-test/eggs2/app")
+	l.success("org-test/eggs2/app")
+
+	if _, backoff := l.backingOff("org-test/eggs2/app"); backoff {
+		t.Fatalf("expected backoff to be reset after success")
+	}
+}
+
+func Test_limiter_evict(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	l := newLimiter(10*time.Second, time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.failure("org-test/eggs2/app")
+	l.failure("org-test/eggs3/app")
+
+	now = now.Add(30 * time.Second)
+	l.failure("org-test/eggs3/app")
+
+	// The backoff of eggs2 expired, but it is kept for another max interval
+	// so consecutive failures keep doubling it.
+	if _, ok := l.backoffs["org-test/eggs2/app"]; !ok {
+		t.Fatalf("expected expired backoff to be kept within the max interval")
+	}
+
+	now = now.Add(time.Minute)
+	l.failure("org-test/eggs3/app")
+
+	if _, ok := l.backoffs["org-test/eggs2/app"]; ok {
+		t.Fatalf("expected backoff not retried within the max interval to be evicted")
+	}
+	if _, ok := l.backoffs["org-test/eggs3/app"]; !ok {
+		t.Fatalf("expected failing backoff to be kept")
+	}
+}
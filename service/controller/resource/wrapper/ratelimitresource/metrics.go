@@ -0,0 +1,55 @@
+package ratelimitresource
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	PrometheusNamespace = "cluster_apps_operator"
+	PrometheusSubsystem = "controller"
+)
+
+var (
+	inFlightGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "reconciles_in_flight",
+			Help:      "Number of resource reconciliations currently being executed.",
+		},
+	)
+	reconcileDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "reconcile_duration_seconds",
+			Help:      "Duration of resource reconciliations including retries.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		},
+		[]string{"resource", "result"},
+	)
+	backoffSkippedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "backoff_skipped_total",
+			Help:      "Number of resource reconciliations skipped because the cluster is backing off.",
+		},
+		[]string{"resource"},
+	)
+	clustersInBackoffGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "clusters_in_backoff",
+			Help:      "Number of cluster resources currently backing off after failures.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(inFlightGauge)
+	prometheus.MustRegister(reconcileDurationHistogram)
+	prometheus.MustRegister(backoffSkippedCounter)
+	prometheus.MustRegister(clustersInBackoffGauge)
+}
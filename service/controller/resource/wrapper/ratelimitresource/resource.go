@@ -0,0 +1,126 @@
+// Package ratelimitresource wraps operatorkit resources so that resources
+// failing for a cluster are skipped with an exponential backoff. A resource
+// backing off fails the reconciliation, so the resources following it do not
+// run on top of the state it failed to reconcile.
+package ratelimitresource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+type WrapConfig struct {
+	Logger micrologger.Logger
+
+	// BackoffInitialInterval is the duration a resource is skipped for a
+	// cluster after its first failure. Zero disables the backoff.
+	BackoffInitialInterval time.Duration
+	// BackoffMaxInterval caps the backoff interval.
+	BackoffMaxInterval time.Duration
+}
+
+type Resource struct {
+	limiter  *limiter
+	logger   micrologger.Logger
+	resource resource.Interface
+}
+
+// Wrap wraps each given resource with a rate limit resource. All returned
+// resources share the same backoff state.
+func Wrap(resources []resource.Interface, config WrapConfig) ([]resource.Interface, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.BackoffInitialInterval < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.BackoffInitialInterval must not be negative", config)
+	}
+	if config.BackoffMaxInterval < config.BackoffInitialInterval {
+		return nil, microerror.Maskf(invalidConfigError, "%T.BackoffMaxInterval must not be smaller than %T.BackoffInitialInterval", config, config)
+	}
+
+	l := newLimiter(config.BackoffInitialInterval, config.BackoffMaxInterval)
+
+	var wrapped []resource.Interface
+
+	for _, r := range resources {
+		s := &Resource{
+			limiter:  l,
+			logger:   config.Logger,
+			resource: r,
+		}
+
+		wrapped = append(wrapped, s)
+	}
+
+	return wrapped, nil
+}
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	err := r.execute(ctx, obj, r.resource.EnsureCreated)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	err := r.execute(ctx, obj, r.resource.EnsureDeleted)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) Name() string {
+	return r.resource.Name()
+}
+
+// Wrapped returns the underlying resource.
+func (r *Resource) Wrapped() resource.Interface {
+	return r.resource
+}
+
+func (r *Resource) execute(ctx context.Context, obj interface{}, f func(context.Context, interface{}) error) error {
+	getter, ok := obj.(key.ObjectGetter)
+	if !ok {
+		return microerror.Maskf(wrongTypeError, "expected object with name and namespace, got '%T'", obj)
+	}
+
+	k := fmt.Sprintf("%s/%s/%s", getter.GetNamespace(), getter.GetName(), r.Name())
+
+	if until, ok := r.limiter.backingOff(k); ok {
+		backoffSkippedCounter.WithLabelValues(r.Name()).Inc()
+		r.logger.Debugf(ctx, "resource %#q failed before, skipping until %s", r.Name(), until.Format(time.RFC3339))
+		return microerror.Mask(backingOffError)
+	}
+
+	inFlightGauge.Inc()
+	defer inFlightGauge.Dec()
+
+	start := time.Now()
+	err := f(ctx, obj)
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+		r.limiter.failure(k)
+	} else {
+		r.limiter.success(k)
+	}
+	reconcileDurationHistogram.WithLabelValues(r.Name(), result).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
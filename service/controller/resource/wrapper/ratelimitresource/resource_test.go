@@ -0,0 +1,73 @@
+package ratelimitresource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+)
+
+type failingResource struct {
+	calls int
+}
+
+func (r *failingResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	r.calls++
+	return errors.New("test error")
+}
+
+func (r *failingResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	r.calls++
+	return errors.New("test error")
+}
+
+func (r *failingResource) Name() string {
+	return "failing"
+}
+
+func Test_Resource_BackingOff(t *testing.T) {
+	ctx := context.Background()
+	cluster := &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "eggs2",
+			Namespace: "org-test",
+		},
+	}
+
+	failing := &failingResource{}
+
+	resources, err := Wrap([]resource.Interface{failing}, WrapConfig{
+		Logger: microloggertest.New(),
+
+		BackoffInitialInterval: time.Minute,
+		BackoffMaxInterval:     time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = resources[0].EnsureCreated(ctx, cluster)
+	if err == nil || IsBackingOff(err) {
+		t.Fatalf("expected resource error, got %v", err)
+	}
+
+	// While backing off the reconciliation must still fail so resources
+	// following this one are not executed.
+	err = resources[0].EnsureCreated(ctx, cluster)
+	if !IsBackingOff(err) {
+		t.Fatalf("expected backing off error, got %v", err)
+	}
+	err = resources[0].EnsureDeleted(ctx, cluster)
+	if !IsBackingOff(err) {
+		t.Fatalf("expected backing off error, got %v", err)
+	}
+
+	if failing.calls != 1 {
+		t.Fatalf("expected wrapped resource to be called once, got %d", failing.calls)
+	}
+}
@@ -0,0 +1,100 @@
+// Package shardresource wraps operatorkit resources so that they only act on
+// clusters owned by the shard of this replica.
+package shardresource
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/v7/pkg/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/sharding"
+)
+
+type WrapConfig struct {
+	Logger   micrologger.Logger
+	Sharding sharding.Interface
+}
+
+type Resource struct {
+	logger   micrologger.Logger
+	resource resource.Interface
+	sharding sharding.Interface
+}
+
+// Wrap wraps each given resource with a shard resource.
+func Wrap(resources []resource.Interface, config WrapConfig) ([]resource.Interface, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Sharding == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Sharding must not be empty", config)
+	}
+
+	var wrapped []resource.Interface
+
+	for _, r := range resources {
+		s := &Resource{
+			logger:   config.Logger,
+			resource: r,
+			sharding: config.Sharding,
+		}
+
+		wrapped = append(wrapped, s)
+	}
+
+	return wrapped, nil
+}
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	owns, err := r.sharding.Owns(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !owns {
+		r.logger.Debugf(ctx, "cluster is owned by another shard, skipping resource %#q", r.Name())
+		return nil
+	}
+
+	err = r.resource.EnsureCreated(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	owns, err := r.sharding.Owns(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !owns {
+		// The owning replica removes the finalizer once it cleaned up, so
+		// this replica must not remove it before.
+		r.logger.Debugf(ctx, "cluster is owned by another shard, skipping resource %#q", r.Name())
+		finalizerskeptcontext.SetKept(ctx)
+		r.logger.Debugf(ctx, "keeping finalizers")
+		return nil
+	}
+
+	err = r.resource.EnsureDeleted(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) Name() string {
+	return r.resource.Name()
+}
+
+// Wrapped returns the underlying resource.
+func (r *Resource) Wrapped() resource.Interface {
+	return r.resource
+}
@@ -0,0 +1,80 @@
+// Package statusresource wraps operatorkit resources so the outcome of every
+// reconciliation is recorded in the cluster status recorder.
+package statusresource
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/v7/pkg/resource"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+)
+
+type WrapConfig struct {
+	Recorder *clusterstatus.Recorder
+}
+
+type Resource struct {
+	recorder *clusterstatus.Recorder
+	resource resource.Interface
+}
+
+// Wrap wraps each given resource with a status resource.
+func Wrap(resources []resource.Interface, config WrapConfig) ([]resource.Interface, error) {
+	if config.Recorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Recorder must not be empty", config)
+	}
+
+	var wrapped []resource.Interface
+
+	for _, r := range resources {
+		s := &Resource{
+			recorder: config.Recorder,
+			resource: r,
+		}
+
+		wrapped = append(wrapped, s)
+	}
+
+	return wrapped, nil
+}
+
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	err := r.resource.EnsureCreated(ctx, obj)
+	r.record(obj, false, err)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	err := r.resource.EnsureDeleted(ctx, obj)
+	r.record(obj, true, err)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *Resource) Name() string {
+	return r.resource.Name()
+}
+
+// Wrapped returns the underlying resource.
+func (r *Resource) Wrapped() resource.Interface {
+	return r.resource
+}
+
+func (r *Resource) record(obj interface{}, deleted bool, err error) {
+	getter, ok := obj.(key.ObjectGetter)
+	if !ok {
+		return
+	}
+
+	r.recorder.RecordResource(getter, r.Name(), deleted, err)
+}
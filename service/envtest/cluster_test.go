@@ -0,0 +1,392 @@
+//go:build envtest
+// +build envtest
+
+package envtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+// providerCase describes a workload cluster of a single infrastructure
+// provider. newObjects returns the infrastructure object, the optional
+// control plane object and any other object the provider reads, all in the
+// given namespace.
+type providerCase struct {
+	name       string
+	newObjects func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object)
+
+	expectedProvider string
+	expectedPrivate  bool
+}
+
+var providerCases = []providerCase{
+	{
+		name: "aws",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			awsCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta2", infra.AWSClusterKind, namespace, "aws", map[string]interface{}{
+				"region": "eu-west-1",
+			})
+			awsCluster.SetAnnotations(map[string]string{annotation.AWSVPCMode: annotation.AWSVPCModePublic})
+
+			return awsCluster, nil, nil
+		},
+		expectedProvider: infra.AWSClusterKindProvider,
+	},
+	{
+		name: "aws-private",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			awsCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta2", infra.AWSClusterKind, namespace, "aws-private", map[string]interface{}{
+				"region": "eu-west-1",
+			})
+			awsCluster.SetAnnotations(map[string]string{annotation.AWSVPCMode: annotation.AWSVPCModePrivate})
+
+			return awsCluster, nil, nil
+		},
+		expectedProvider: infra.AWSClusterKindProvider,
+		expectedPrivate:  true,
+	},
+	{
+		name: "eks",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			awsManagedCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta2", infra.AWSManagedClusterKind, namespace, "eks", map[string]interface{}{})
+			awsManagedCluster.SetAnnotations(map[string]string{annotation.AWSVPCMode: annotation.AWSVPCModePublic})
+
+			controlPlane := newObject("controlplane.cluster.x-k8s.io/v1beta2", "AWSManagedControlPlane", namespace, "eks", map[string]interface{}{
+				"region": "eu-west-1",
+			})
+
+			return awsManagedCluster, controlPlane, nil
+		},
+		expectedProvider: infra.AWSClusterKindProvider,
+	},
+	{
+		name: "azure",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			azureCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta1", infra.AzureClusterKind, namespace, "azure", map[string]interface{}{
+				"location":       "westeurope",
+				"subscriptionID": "00000000-0000-0000-0000-000000000000",
+				"networkSpec": map[string]interface{}{
+					"apiServerLB": map[string]interface{}{"type": "Public"},
+				},
+			})
+
+			return azureCluster, nil, nil
+		},
+		expectedProvider: infra.AzureClusterKindProvider,
+	},
+	{
+		name: "azure-private",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			azureCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta1", infra.AzureClusterKind, namespace, "azure-private", map[string]interface{}{
+				"location":       "westeurope",
+				"subscriptionID": "00000000-0000-0000-0000-000000000000",
+				"networkSpec": map[string]interface{}{
+					"apiServerLB": map[string]interface{}{"type": "Internal"},
+				},
+			})
+
+			return azureCluster, nil, nil
+		},
+		expectedProvider: infra.AzureClusterKindProvider,
+		expectedPrivate:  true,
+	},
+	{
+		name: "aks",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			azureManagedCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta1", infra.AzureManagedClusterKind, namespace, "aks", map[string]interface{}{
+				"networkSpec": map[string]interface{}{
+					"apiServerLB": map[string]interface{}{"type": "Public"},
+				},
+			})
+
+			controlPlane := newObject("infrastructure.cluster.x-k8s.io/v1beta1", "AzureManagedControlPlane", namespace, "aks", map[string]interface{}{
+				"location":       "westeurope",
+				"subscriptionID": "00000000-0000-0000-0000-000000000000",
+			})
+
+			return azureManagedCluster, controlPlane, nil
+		},
+		expectedProvider: infra.AzureClusterKindProvider,
+	},
+	{
+		name: "aso",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			asoCluster := newObject("infrastructure.cluster.x-k8s.io/v1alpha1", infra.AzureASOManagedClusterKind, namespace, "aso", map[string]interface{}{
+				"resources": []interface{}{},
+			})
+
+			return asoCluster, nil, nil
+		},
+		expectedProvider: infra.AzureClusterKindProvider,
+	},
+	{
+		name: "vcd",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			vcdCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta2", infra.VCDClusterKind, namespace, "vcd", map[string]interface{}{
+				"site": "https://vcd.example.com",
+				"org":  "giantswarm",
+				"ovdc": "ovdc",
+				"userContext": map[string]interface{}{
+					"secretRef": map[string]interface{}{
+						"name":      "vcd-credentials",
+						"namespace": namespace,
+					},
+				},
+			})
+
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "vcd-credentials"},
+				Data:       map[string][]byte{"refreshToken": []byte("token")},
+			}
+
+			return vcdCluster, nil, []client.Object{credentials}
+		},
+		expectedProvider: infra.VCDClusterKindProvider,
+		// Cloud Director clusters are private whenever the installation
+		// uses a proxy.
+		expectedPrivate: true,
+	},
+	{
+		name: "vsphere",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			vsphereCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta1", infra.VSphereClusterKind, namespace, "vsphere", map[string]interface{}{})
+
+			// The proxy is enabled in the values of the cluster-vsphere app
+			// the cluster was created with.
+			userConfig := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "vsphere-user-values"},
+				Data:       map[string]string{"values": "global:\n  connectivity:\n    proxy:\n      enabled: true\n"},
+			}
+			clusterApp := &appv1alpha1.App{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "vsphere"},
+				Spec: appv1alpha1.AppSpec{
+					Catalog:    "cluster",
+					KubeConfig: appv1alpha1.AppSpecKubeConfig{InCluster: true},
+					Name:       "cluster-vsphere",
+					Namespace:  namespace,
+					UserConfig: appv1alpha1.AppSpecUserConfig{
+						ConfigMap: appv1alpha1.AppSpecUserConfigConfigMap{Name: userConfig.Name, Namespace: namespace},
+					},
+					Version: "1.0.0",
+				},
+			}
+
+			return vsphereCluster, nil, []client.Object{userConfig, clusterApp}
+		},
+		expectedProvider: infra.VSphereClusterKindProvider,
+		expectedPrivate:  true,
+	},
+	{
+		name: "gcp",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			gcpCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta1", infra.GCPClusterKind, namespace, "gcp", map[string]interface{}{
+				"project": "test-project",
+			})
+
+			return gcpCluster, nil, nil
+		},
+		expectedProvider: infra.GCPClusterKindProvider,
+	},
+	{
+		name: "gke",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			gcpManagedCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta1", infra.GCPManagedClusterKind, namespace, "gke", map[string]interface{}{
+				"project": "test-project",
+			})
+
+			return gcpManagedCluster, nil, nil
+		},
+		expectedProvider: infra.GCPClusterKindProvider,
+	},
+	{
+		name: "proxmox",
+		newObjects: func(namespace string) (*unstructured.Unstructured, *unstructured.Unstructured, []client.Object) {
+			proxmoxCluster := newObject("infrastructure.cluster.x-k8s.io/v1alpha1", infra.ProxmoxClusterKind, namespace, "proxmox", map[string]interface{}{})
+
+			return proxmoxCluster, nil, nil
+		},
+		expectedProvider: infra.ProxmoxClusterKindProvider,
+		// Proxmox clusters are private whenever the installation uses a
+		// proxy.
+		expectedPrivate: true,
+	},
+}
+
+// Test_ProviderCoverage ensures every infrastructure kind the operator knows
+// about is exercised by Test_Providers.
+func Test_ProviderCoverage(t *testing.T) {
+	covered := map[string]bool{}
+	for _, tc := range providerCases {
+		infrastructure, _, _ := tc.newObjects("default")
+		covered[infrastructure.GetKind()] = true
+	}
+
+	for _, kind := range infra.ClusterKinds {
+		if !covered[kind] {
+			t.Errorf("infrastructure kind %#q has no provider case", kind)
+		}
+	}
+}
+
+// Test_Providers creates a Cluster for every provider, checks the values and
+// App CRs rendered for it, including the proxy settings of private clusters,
+// and deletes it again.
+func Test_Providers(t *testing.T) {
+	for _, tc := range providerCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			namespace := fmt.Sprintf("org-%s", tc.name)
+			createNamespace(ctx, t, namespace)
+
+			infrastructure, controlPlane, objects := tc.newObjects(namespace)
+			objects = append(objects, infrastructure)
+			if controlPlane != nil {
+				objects = append(objects, controlPlane)
+			}
+			createObjects(ctx, t, objects...)
+
+			cluster := newCluster(infrastructure, controlPlane)
+			createObjects(ctx, t, cluster)
+
+			waitFor(t, "cluster values", func() error {
+				values, err := clusterValues(ctx, cluster)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+
+				provider, _, _ := unstructured.NestedString(values, "provider")
+				if provider != tc.expectedProvider {
+					return microerror.Maskf(executionFailedError, "expected provider %#q, got %#q", tc.expectedProvider, provider)
+				}
+
+				private, _, _ := unstructured.NestedBool(values, "cluster", "private")
+				if private != tc.expectedPrivate {
+					return microerror.Maskf(executionFailedError, "expected private %t, got %t", tc.expectedPrivate, private)
+				}
+
+				return nil
+			})
+
+			waitFor(t, "cluster values secret", func() error {
+				values, err := secretValues(ctx, cluster)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+
+				http, _, _ := unstructured.NestedString(values, "cluster", "proxy", "http")
+				noProxyValue, _, _ := unstructured.NestedString(values, "cluster", "proxy", "noProxy")
+				if tc.expectedPrivate {
+					if http != httpProxy {
+						return microerror.Maskf(executionFailedError, "expected http proxy %#q, got %#q", httpProxy, http)
+					}
+					if !strings.Contains(noProxyValue, noProxy) {
+						return microerror.Maskf(executionFailedError, "expected no proxy %#q to contain %#q", noProxyValue, noProxy)
+					}
+
+					var systemdProxy corev1.Secret
+					err = ctrlClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: fmt.Sprintf("%s-systemd-proxy", cluster.Name)}, &systemdProxy)
+					if err != nil {
+						return microerror.Mask(err)
+					}
+				} else if http != "" {
+					return microerror.Maskf(executionFailedError, "expected no proxy for public cluster, got %#q", http)
+				}
+
+				return nil
+			})
+
+			waitFor(t, "operator apps", func() error {
+				return operatorApps(ctx, cluster)
+			})
+
+			err := ctrlClient.Delete(ctx, cluster)
+			if err != nil {
+				t.Fatalf("deleting cluster: %v", err)
+			}
+
+			waitFor(t, "cluster deletion", func() error {
+				return deleted(ctx, cluster)
+			})
+		})
+	}
+}
+
+// Test_ClusterUpdate checks that changes to the Cluster are rendered into
+// its values.
+func Test_ClusterUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	namespace := "org-update"
+	createNamespace(ctx, t, namespace)
+
+	awsCluster := newObject("infrastructure.cluster.x-k8s.io/v1beta2", infra.AWSClusterKind, namespace, "update", map[string]interface{}{
+		"region": "eu-west-1",
+	})
+	awsCluster.SetAnnotations(map[string]string{annotation.AWSVPCMode: annotation.AWSVPCModePublic})
+	createObjects(ctx, t, awsCluster)
+
+	cluster := newCluster(awsCluster, nil)
+	createObjects(ctx, t, cluster)
+
+	expectDNSIP := func(expected string) func() error {
+		return func() error {
+			values, err := clusterValues(ctx, cluster)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			dnsIP, _, _ := unstructured.NestedString(values, "clusterDNSIP")
+			if dnsIP != expected {
+				return microerror.Maskf(executionFailedError, "expected cluster DNS IP %#q, got %#q", expected, dnsIP)
+			}
+
+			return nil
+		}
+	}
+
+	// Without services CIDR the DNS IP is taken from the installation
+	// cluster IP range.
+	waitFor(t, "initial cluster values", expectDNSIP("172.31.0.10"))
+
+	{
+		var current capi.Cluster
+		err := ctrlClient.Get(ctx, client.ObjectKeyFromObject(cluster), &current)
+		if err != nil {
+			t.Fatalf("getting cluster: %v", err)
+		}
+
+		modified := current.DeepCopy()
+		modified.Spec.ClusterNetwork.Services.CIDRBlocks = []string{"10.96.0.0/12"}
+
+		err = ctrlClient.Patch(ctx, modified, client.MergeFrom(&current))
+		if err != nil {
+			t.Fatalf("updating cluster: %v", err)
+		}
+	}
+
+	waitFor(t, "updated cluster values", expectDNSIP("10.96.0.10"))
+
+	err := ctrlClient.Delete(ctx, cluster)
+	if err != nil {
+		t.Fatalf("deleting cluster: %v", err)
+	}
+
+	waitFor(t, "cluster deletion", func() error {
+		return deleted(ctx, cluster)
+	})
+}
@@ -0,0 +1,14 @@
+//go:build envtest
+// +build envtest
+
+package envtest
+
+import "github.com/giantswarm/microerror"
+
+var executionFailedError = &microerror.Error{
+	Kind: "executionFailedError",
+}
+
+var notReadyError = &microerror.Error{
+	Kind: "notReadyError",
+}
@@ -0,0 +1,190 @@
+//go:build envtest
+// +build envtest
+
+package envtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+const (
+	// waitTimeout is how long the operator gets to converge after a change.
+	waitTimeout = 60 * time.Second
+)
+
+// newCluster returns a v1beta2 Cluster watched by the operator which
+// references the given infrastructure and optional control plane object.
+func newCluster(infrastructure, controlPlane *unstructured.Unstructured) *capi.Cluster {
+	cluster := &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      infrastructure.GetName(),
+			Namespace: infrastructure.GetNamespace(),
+			Labels: map[string]string{
+				label.Cluster:                     infrastructure.GetName(),
+				label.ClusterAppsOperatorWatching: "true",
+			},
+		},
+		Spec: capi.ClusterSpec{
+			InfrastructureRef: capi.ContractVersionedObjectReference{
+				APIGroup: infrastructure.GroupVersionKind().Group,
+				Kind:     infrastructure.GetKind(),
+				Name:     infrastructure.GetName(),
+			},
+		},
+	}
+
+	if controlPlane != nil {
+		cluster.Spec.ControlPlaneRef = capi.ContractVersionedObjectReference{
+			APIGroup: controlPlane.GroupVersionKind().Group,
+			Kind:     controlPlane.GetKind(),
+			Name:     controlPlane.GetName(),
+		}
+	}
+
+	return cluster
+}
+
+// newObject returns an unstructured object of the given kind with the given
+// spec.
+func newObject(apiVersion, kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(apiVersion, kind))
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	return obj
+}
+
+// createNamespace creates a namespace for a single test. envtest does not run
+// the namespace controller, so namespaces are never cleaned up and every test
+// must use its own.
+func createNamespace(ctx context.Context, t *testing.T, name string) {
+	t.Helper()
+
+	err := ctrlClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	if err != nil {
+		t.Fatalf("creating namespace %#q: %v", name, err)
+	}
+}
+
+func createObjects(ctx context.Context, t *testing.T, objects ...client.Object) {
+	t.Helper()
+
+	for _, obj := range objects {
+		err := ctrlClient.Create(ctx, obj)
+		if err != nil {
+			t.Fatalf("creating %T %#q: %v", obj, obj.GetName(), err)
+		}
+	}
+}
+
+// waitFor retries o until it succeeds or waitTimeout passed.
+func waitFor(t *testing.T, description string, o func() error) {
+	t.Helper()
+
+	n := func(err error, d time.Duration) {
+		t.Logf("waiting for %s: %v", description, err)
+	}
+
+	err := backoff.RetryNotify(o, backoff.NewConstant(waitTimeout, 500*time.Millisecond), n)
+	if err != nil {
+		t.Fatalf("%s: %v", description, err)
+	}
+}
+
+// clusterValues returns the values of the cluster values ConfigMap of the
+// given Cluster.
+func clusterValues(ctx context.Context, cluster *capi.Cluster) (map[string]interface{}, error) {
+	var cm corev1.ConfigMap
+	err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: key.ClusterValuesResourceName(cluster)}, &cm)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var values map[string]interface{}
+	err = yaml.Unmarshal([]byte(cm.Data["values"]), &values)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return values, nil
+}
+
+// secretValues returns the values of the cluster values Secret of the given
+// Cluster.
+func secretValues(ctx context.Context, cluster *capi.Cluster) (map[string]interface{}, error) {
+	var secret corev1.Secret
+	err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: key.ClusterValuesResourceName(cluster)}, &secret)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var values map[string]interface{}
+	err = yaml.Unmarshal(secret.Data["values"], &values)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return values, nil
+}
+
+// operatorApps returns an error unless the app-operator and chart-operator
+// App CRs of the given Cluster exist.
+func operatorApps(ctx context.Context, cluster *capi.Cluster) error {
+	for _, name := range []string{key.AppOperatorAppName(cluster), key.ChartOperatorAppName(cluster)} {
+		var app appv1alpha1.App
+		err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, &app)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// deleted returns an error unless the Cluster and everything the operator
+// created for it are gone.
+func deleted(ctx context.Context, cluster *capi.Cluster) error {
+	objects := []client.Object{
+		&capi.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name}},
+		&appv1alpha1.App{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: key.AppOperatorAppName(cluster)}},
+		&appv1alpha1.App{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: key.ChartOperatorAppName(cluster)}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: key.AppOperatorValuesResourceName(cluster)}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: key.ClusterValuesResourceName(cluster)}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: key.ClusterValuesResourceName(cluster)}},
+	}
+
+	for _, obj := range objects {
+		err := ctrlClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return microerror.Maskf(notReadyError, "%T %#q still exists", obj, obj.GetName())
+	}
+
+	return nil
+}
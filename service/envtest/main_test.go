@@ -0,0 +1,283 @@
+//go:build envtest
+// +build envtest
+
+package envtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlenvtest "sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/flag"
+	"github.com/giantswarm/cluster-apps-operator/v3/service"
+)
+
+const (
+	// httpProxy, httpsProxy and noProxy are the installation proxy settings
+	// the operator is booted with. They are rendered into the values of
+	// private clusters.
+	httpProxy  = "http://proxy.example.com:3128"
+	httpsProxy = "http://proxy.example.com:3128"
+	noProxy    = "internal.example.com"
+
+	// catalogNamespace is the namespace of the Catalogs and
+	// AppCatalogEntries of the operator apps.
+	catalogNamespace = "giantswarm"
+)
+
+var (
+	// ctrlClient is used by the tests to create Clusters and their
+	// infrastructure objects and to observe what the operator renders.
+	ctrlClient client.Client
+)
+
+// TestMain starts a local API server and etcd with the App, CAPI and provider
+// CRDs installed, and boots the full operator service against it. The
+// binaries are looked up with KUBEBUILDER_ASSETS, see `make test-envtest`.
+func TestMain(m *testing.M) {
+	code, err := run(m)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%#v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(code)
+}
+
+func run(m *testing.M) (int, error) {
+	var err error
+
+	var crdPaths []string
+	{
+		crdPaths, err = crdDirectoryPaths()
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+	}
+
+	testEnv := &ctrlenvtest.Environment{
+		CRDDirectoryPaths:     crdPaths,
+		ErrorIfCRDPathMissing: true,
+	}
+
+	restConfig, err := testEnv.Start()
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+	defer testEnv.Stop() // nolint:errcheck
+
+	{
+		scheme := runtime.NewScheme()
+
+		schemeBuilder := runtime.SchemeBuilder{
+			clientgoscheme.AddToScheme,
+			appv1alpha1.AddToScheme,
+			capi.AddToScheme,
+		}
+
+		err = schemeBuilder.AddToScheme(scheme)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+
+		ctrlClient, err = client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+	}
+
+	// App CRs are only created for versions indexed in their catalog.
+	err = createCatalogEntries(context.Background())
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	var newService *service.Service
+	{
+		logger, err := micrologger.New(micrologger.Config{})
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+
+		f := flag.New()
+
+		c := service.Config{
+			Logger: logger,
+
+			Flag:  f,
+			Viper: newViper(f, testEnv.KubeConfig),
+		}
+
+		newService, err = service.New(c)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newService.Boot(ctx)
+
+	return m.Run(), nil
+}
+
+// createCatalogEntries creates the Catalogs and AppCatalogEntries of the
+// operator app versions set in newViper.
+func createCatalogEntries(ctx context.Context) error {
+	err := ctrlClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: catalogNamespace}})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	entries := []struct {
+		app     string
+		catalog string
+		version string
+	}{
+		{app: "app-operator", catalog: "control-plane-catalog", version: "7.0.0"},
+		{app: "chart-operator", catalog: "default", version: "4.0.0"},
+	}
+
+	for _, name := range []string{"control-plane-catalog", "default"} {
+		catalog := &appv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: catalogNamespace,
+			},
+			Spec: appv1alpha1.CatalogSpec{
+				Title:       name,
+				Description: name,
+				LogoURL:     "https://example.com/logo.png",
+				Storage: appv1alpha1.CatalogSpecStorage{
+					Type: "helm",
+					URL:  "https://example.com/" + name + "/",
+				},
+				Repositories: []appv1alpha1.CatalogSpecRepository{
+					{
+						Type: "helm",
+						URL:  "https://example.com/" + name + "/",
+					},
+				},
+			},
+		}
+
+		err = ctrlClient.Create(ctx, catalog)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	now := metav1.Now()
+	for _, e := range entries {
+		entry := &appv1alpha1.AppCatalogEntry{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s-%s", e.catalog, e.app, e.version),
+				Namespace: catalogNamespace,
+				Labels: map[string]string{
+					label.AppKubernetesName: e.app,
+					label.CatalogName:       e.catalog,
+				},
+			},
+			Spec: appv1alpha1.AppCatalogEntrySpec{
+				AppName:    e.app,
+				AppVersion: e.version,
+				Catalog: appv1alpha1.AppCatalogEntrySpecCatalog{
+					Name:      e.catalog,
+					Namespace: catalogNamespace,
+				},
+				DateCreated: &now,
+				DateUpdated: &now,
+				Version:     e.version,
+			},
+		}
+
+		err = ctrlClient.Create(ctx, entry)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// newViper returns the settings the operator is booted with. They mirror the
+// defaults of the daemon command, with a short resync period so the tests
+// do not depend on watch events alone.
+func newViper(f *flag.Flag, kubeConfig []byte) *viper.Viper {
+	v := viper.New()
+
+	v.Set(f.Service.App.AppOperator.Catalog, "control-plane-catalog")
+	v.Set(f.Service.App.AppOperator.Version, "7.0.0")
+	v.Set(f.Service.App.ChartOperator.Catalog, "default")
+	v.Set(f.Service.App.ChartOperator.Version, "4.0.0")
+
+	v.Set(f.Service.Controller.Backoff.InitialInterval, "1s")
+	v.Set(f.Service.Controller.Backoff.MaxInterval, "5s")
+	v.Set(f.Service.Controller.ResyncPeriod, "10s")
+	v.Set(f.Service.Controller.Retry.Interval, "100ms")
+	v.Set(f.Service.Controller.Retry.MaxRetries, 3)
+
+	v.Set(f.Service.Image.Registry.Domain, "gsoci.azurecr.io")
+
+	v.Set(f.Service.Kubernetes.KubeConfig, string(kubeConfig))
+
+	v.Set(f.Service.Proxy.HttpProxy, httpProxy)
+	v.Set(f.Service.Proxy.HttpsProxy, httpsProxy)
+	v.Set(f.Service.Proxy.NoProxy, noProxy)
+
+	v.Set(f.Service.Workload.Cluster.BaseDomain, "k8s.test.gigantic.io")
+	v.Set(f.Service.Workload.Cluster.Calico.CIDR, "16")
+	v.Set(f.Service.Workload.Cluster.Calico.Subnet, "192.168.0.0")
+	v.Set(f.Service.Workload.Cluster.Kubernetes.API.ClusterIPRange, "172.31.0.0/16")
+	v.Set(f.Service.Workload.Cluster.Kubernetes.ClusterDomain, "cluster.local")
+	v.Set(f.Service.Workload.Cluster.Owner, "envtest")
+
+	return v
+}
+
+// crdDirectoryPaths returns the directories containing the App and CAPI CRDs
+// of the module versions in go.mod, and the minimal provider CRDs in
+// testdata.
+func crdDirectoryPaths() ([]string, error) {
+	modules := []struct {
+		path string
+		dir  string
+	}{
+		{path: "github.com/giantswarm/apiextensions-application", dir: "config/crd"},
+		{path: "sigs.k8s.io/cluster-api", dir: "config/crd/bases"},
+	}
+
+	paths := []string{
+		filepath.Join("testdata", "crd"),
+	}
+
+	for _, m := range modules {
+		// #nosec G204 -- the module paths are constants.
+		out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", m.path).Output()
+		if err != nil {
+			return nil, microerror.Maskf(executionFailedError, "finding module %#q: %s", m.path, err)
+		}
+
+		paths = append(paths, filepath.Join(strings.TrimSpace(string(out)), m.dir))
+	}
+
+	return paths, nil
+}
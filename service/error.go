@@ -12,3 +12,12 @@ var invalidConfigError = &microerror.Error{
 func IsInvalidConfig(err error) bool {
 	return microerror.Cause(err) == invalidConfigError
 }
+
+var leadershipLostError = &microerror.Error{
+	Kind: "leadershipLostError",
+}
+
+// IsLeadershipLost asserts leadershipLostError.
+func IsLeadershipLost(err error) bool {
+	return microerror.Cause(err) == leadershipLostError
+}
@@ -0,0 +1,64 @@
+// Package capiversion detects the CAPI version Clusters are reconciled in.
+package capiversion
+
+import (
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	clusterKind = "Cluster"
+)
+
+// Supported are the CAPI versions Clusters can be reconciled in, most
+// preferred first.
+var Supported = []schema.GroupVersion{
+	capi.GroupVersion,
+	capiv1beta1.GroupVersion,
+}
+
+// IsSupported returns true if Clusters can be reconciled in the given version.
+func IsSupported(gv schema.GroupVersion) bool {
+	for _, s := range Supported {
+		if s == gv {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewCluster returns an empty Cluster of the given supported version.
+func NewCluster(gv schema.GroupVersion) client.Object {
+	if gv == capiv1beta1.GroupVersion {
+		return new(capiv1beta1.Cluster)
+	}
+
+	return new(capi.Cluster)
+}
+
+// Preferred returns the most preferred supported version in which the API
+// server serves Clusters. v1beta2 is used as soon as it is served so the
+// operator keeps working once v1beta1 is no longer served.
+func Preferred(d discovery.DiscoveryInterface) (schema.GroupVersion, error) {
+	for _, gv := range Supported {
+		list, err := d.ServerResourcesForGroupVersion(gv.String())
+		if isNotServed(err) {
+			continue
+		} else if err != nil {
+			return schema.GroupVersion{}, microerror.Mask(err)
+		}
+
+		for _, r := range list.APIResources {
+			if r.Kind == clusterKind {
+				return gv, nil
+			}
+		}
+	}
+
+	return schema.GroupVersion{}, microerror.Maskf(notServedError, "%s is not served in any of the supported versions", clusterKind)
+}
@@ -0,0 +1,67 @@
+package capiversion
+
+import (
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func Test_Preferred(t *testing.T) {
+	clusters := []metav1.APIResource{{Name: "clusters", Kind: "Cluster"}}
+
+	testCases := []struct {
+		name          string
+		resources     []*metav1.APIResourceList
+		expected      schema.GroupVersion
+		expectedError error
+	}{
+		{
+			name: "case 0: v1beta2 preferred when both are served",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "cluster.x-k8s.io/v1beta1", APIResources: clusters},
+				{GroupVersion: "cluster.x-k8s.io/v1beta2", APIResources: clusters},
+			},
+			expected: schema.GroupVersion{Group: "cluster.x-k8s.io", Version: "v1beta2"},
+		},
+		{
+			name: "case 1: v1beta1 used when v1beta2 is not served",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "cluster.x-k8s.io/v1beta1", APIResources: clusters},
+			},
+			expected: schema.GroupVersion{Group: "cluster.x-k8s.io", Version: "v1beta1"},
+		},
+		{
+			name: "case 2: v1beta2 used when v1beta1 is no longer served",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "cluster.x-k8s.io/v1beta2", APIResources: clusters},
+			},
+			expected: schema.GroupVersion{Group: "cluster.x-k8s.io", Version: "v1beta2"},
+		},
+		{
+			name: "case 3: error when Clusters are not served",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "cluster.x-k8s.io/v1beta2", APIResources: []metav1.APIResource{{Name: "machines", Kind: "Machine"}}},
+			},
+			expectedError: notServedError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tc.resources}}
+
+			gv, err := Preferred(d)
+			if microerror.Cause(err) != tc.expectedError {
+				t.Fatalf("expected error %#v, got %#v", tc.expectedError, err)
+			}
+
+			if gv != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, gv)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package capiversion
+
+import (
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var notServedError = &microerror.Error{
+	Kind: "notServedError",
+}
+
+// IsNotServed asserts notServedError.
+func IsNotServed(err error) bool {
+	return microerror.Cause(err) == notServedError
+}
+
+// isNotServed asserts the discovery error returned for group versions the API
+// server does not serve.
+func isNotServed(err error) bool {
+	return apierrors.IsNotFound(microerror.Cause(err))
+}
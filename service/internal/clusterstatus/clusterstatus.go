@@ -0,0 +1,342 @@
+// Package clusterstatus keeps an in-memory record of the reconcile state of
+// every watched cluster so it can be exposed for debugging.
+package clusterstatus
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+const (
+	// ResultWindow is the number of most recent resource reconciliations of
+	// a cluster taken into account by FailingClusters.
+	ResultWindow = 10
+)
+
+// Recorder stores the status of watched clusters. All methods are safe for
+// concurrent use and the nil Recorder is valid and records nothing, so
+// resources can be used without one.
+type Recorder struct {
+	mutex    sync.RWMutex
+	clusters map[string]*ClusterStatus
+	// results holds per cluster whether its most recent resource
+	// reconciliations failed.
+	results map[string]*resultWindow
+}
+
+// resultWindow is a ring buffer of the last ResultWindow results, next is the
+// position of the next write.
+type resultWindow struct {
+	failed []bool
+	next   int
+}
+
+func New() *Recorder {
+	r := &Recorder{
+		clusters: map[string]*ClusterStatus{},
+		results:  map[string]*resultWindow{},
+	}
+
+	return r
+}
+
+// Update applies f to the status of the given cluster, creating the entry if
+// it does not exist yet.
+func (r *Recorder) Update(getter key.ObjectGetter, f func(s *ClusterStatus)) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s, ok := r.clusters[statusKey(getter.GetNamespace(), getter.GetName())]
+	if !ok {
+		s = &ClusterStatus{
+			Namespace: getter.GetNamespace(),
+			Name:      getter.GetName(),
+		}
+		r.clusters[statusKey(getter.GetNamespace(), getter.GetName())] = s
+	}
+	s.ClusterID = key.ClusterID(getter)
+
+	f(s)
+}
+
+// UpdateIfExists applies f to the status of the given cluster only if the
+// cluster is already known. It is used on the deletion path so that clusters
+// which are gone do not reappear.
+func (r *Recorder) UpdateIfExists(getter key.ObjectGetter, f func(s *ClusterStatus)) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s, ok := r.clusters[statusKey(getter.GetNamespace(), getter.GetName())]
+	if !ok {
+		return
+	}
+
+	f(s)
+}
+
+// RecordResource stores the outcome of the last reconciliation of the named
+// resource.
+func (r *Recorder) RecordResource(getter key.ObjectGetter, resourceName string, deleted bool, err error) {
+	f := func(s *ClusterStatus) {
+		if s.Resources == nil {
+			s.Resources = map[string]ResourceStatus{}
+		}
+
+		rs := ResourceStatus{
+			LastReconcileTime: time.Now().UTC(),
+		}
+		if err != nil {
+			rs.LastError = err.Error()
+		}
+
+		s.Resources[resourceName] = rs
+	}
+
+	if deleted {
+		r.UpdateIfExists(getter, f)
+	} else {
+		r.Update(getter, f)
+	}
+
+	r.recordResult(getter, err != nil)
+}
+
+// FailingClusters returns how many clusters have ResultWindow resource
+// reconciliations recorded and how many of them had all of those fail.
+// Clusters with fewer recorded reconciliations are not counted.
+func (r *Recorder) FailingClusters() (total int, failing int) {
+	if r == nil {
+		return 0, 0
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, w := range r.results {
+		if len(w.failed) < ResultWindow {
+			continue
+		}
+		total++
+
+		allFailed := true
+		for _, f := range w.failed {
+			allFailed = allFailed && f
+		}
+		if allFailed {
+			failing++
+		}
+	}
+
+	return total, failing
+}
+
+// RecordValues stores the hash of a rendered values document.
+func (r *Recorder) RecordValues(getter key.ObjectGetter, kind, name string, data []byte) {
+	r.Update(getter, func(s *ClusterStatus) {
+		if s.ValuesHashes == nil {
+			s.ValuesHashes = map[string]string{}
+		}
+
+		s.ValuesHashes[fmt.Sprintf("%s/%s", kind, name)] = fmt.Sprintf("%x", sha256.Sum256(data))
+	})
+}
+
+// RecordProxy stores the effective proxy of the cluster. Credentials in the
+// proxy URLs are redacted, as the status is served without authentication.
+func (r *Recorder) RecordProxy(getter key.ObjectGetter, p *proxy.Proxy) {
+	var redacted *proxy.Proxy
+	if p != nil {
+		redacted = &proxy.Proxy{
+			NoProxy:    p.NoProxy,
+			HttpProxy:  redactURL(p.HttpProxy),
+			HttpsProxy: redactURL(p.HttpsProxy),
+		}
+	}
+
+	r.Update(getter, func(s *ClusterStatus) {
+		s.Proxy = redacted
+	})
+}
+
+// Prune removes the status of all clusters for which keep returns false and
+// returns how many were removed.
+func (r *Recorder) Prune(keep func(namespace, name string) bool) int {
+	if r == nil {
+		return 0
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var pruned int
+	for k, s := range r.clusters {
+		if !keep(s.Namespace, s.Name) {
+			delete(r.clusters, k)
+			delete(r.results, k)
+			pruned++
+		}
+	}
+
+	return pruned
+}
+
+func (r *Recorder) Delete(namespace, name string) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.clusters, statusKey(namespace, name))
+	delete(r.results, statusKey(namespace, name))
+}
+
+// Get returns a copy of the status of the given cluster.
+func (r *Recorder) Get(namespace, name string) (ClusterStatus, bool) {
+	if r == nil {
+		return ClusterStatus{}, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	s, ok := r.clusters[statusKey(namespace, name)]
+	if !ok {
+		return ClusterStatus{}, false
+	}
+
+	return s.copy(), true
+}
+
+// List returns a copy of the status of all known clusters sorted by namespace
+// and name.
+func (r *Recorder) List() []ClusterStatus {
+	if r == nil {
+		return []ClusterStatus{}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	list := make([]ClusterStatus, 0, len(r.clusters))
+	for _, s := range r.clusters {
+		list = append(list, s.copy())
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Namespace != list[j].Namespace {
+			return list[i].Namespace < list[j].Namespace
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+func (s *ClusterStatus) copy() ClusterStatus {
+	c := *s
+
+	if s.Proxy != nil {
+		p := *s.Proxy
+		c.Proxy = &p
+	}
+	if s.ValuesHashes != nil {
+		c.ValuesHashes = map[string]string{}
+		for k, v := range s.ValuesHashes {
+			c.ValuesHashes[k] = v
+		}
+	}
+	if s.SkippedExtraValues != nil {
+		c.SkippedExtraValues = map[string][]string{}
+		for k, v := range s.SkippedExtraValues {
+			c.SkippedExtraValues[k] = append([]string{}, v...)
+		}
+	}
+	if s.Apps != nil {
+		c.Apps = append([]AppStatus{}, s.Apps...)
+	}
+	if s.Resources != nil {
+		c.Resources = map[string]ResourceStatus{}
+		for k, v := range s.Resources {
+			c.Resources[k] = v
+		}
+	}
+
+	return c
+}
+
+// recordResult records the result for known clusters only, so clusters which
+// are gone do not reappear through the deletion path.
+func (r *Recorder) recordResult(getter key.ObjectGetter, failed bool) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	k := statusKey(getter.GetNamespace(), getter.GetName())
+	if _, ok := r.clusters[k]; !ok {
+		return
+	}
+
+	w, ok := r.results[k]
+	if !ok {
+		w = &resultWindow{failed: make([]bool, 0, ResultWindow)}
+		r.results[k] = w
+	}
+
+	if len(w.failed) < ResultWindow {
+		w.failed = append(w.failed, failed)
+	} else {
+		w.failed[w.next] = failed
+	}
+	w.next = (w.next + 1) % ResultWindow
+}
+
+// redactURL strips the userinfo of a proxy URL, username included. Proxies
+// given without a scheme are not parsed into a host, so their userinfo is cut
+// from the authority, which ends at the first slash.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err == nil && u.Host != "" {
+		if u.User == nil {
+			return s
+		}
+		u.User = nil
+		return u.String()
+	}
+
+	authority := s
+	if i := strings.Index(s, "/"); i >= 0 {
+		authority = s[:i]
+	}
+
+	i := strings.LastIndex(authority, "@")
+	if i < 0 {
+		return s
+	}
+
+	return s[i+1:]
+}
+
+func statusKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
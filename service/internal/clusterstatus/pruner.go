@@ -0,0 +1,130 @@
+package clusterstatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/sharding"
+)
+
+type PrunerConfig struct {
+	CtrlClient client.Client
+	Logger     micrologger.Logger
+	Recorder   *Recorder
+	// Sharding is optional. When set the status of clusters owned by other
+	// replicas is pruned as well.
+	Sharding sharding.Interface
+
+	// ClusterGroupVersion is the CAPI version Clusters are listed in.
+	ClusterGroupVersion schema.GroupVersion
+	Interval            time.Duration
+}
+
+// Pruner removes the status of clusters which are no longer reconciled by
+// this replica, e.g. because they were deleted while the operator was down
+// or moved to another shard. The delete path only covers clusters deleted
+// while they are reconciled here.
+type Pruner struct {
+	ctrlClient client.Client
+	logger     micrologger.Logger
+	recorder   *Recorder
+	sharding   sharding.Interface
+
+	clusterGroupVersion schema.GroupVersion
+	interval            time.Duration
+}
+
+func NewPruner(config PrunerConfig) (*Pruner, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Recorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Recorder must not be empty", config)
+	}
+	if config.ClusterGroupVersion.Empty() {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClusterGroupVersion must not be empty", config)
+	}
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+
+	p := &Pruner{
+		ctrlClient: config.CtrlClient,
+		logger:     config.Logger,
+		recorder:   config.Recorder,
+		sharding:   config.Sharding,
+
+		clusterGroupVersion: config.ClusterGroupVersion,
+		interval:            config.Interval,
+	}
+
+	return p, nil
+}
+
+// Boot prunes the recorded status every interval until ctx is canceled.
+func (p *Pruner) Boot(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := p.Prune(ctx)
+		if err != nil {
+			p.logger.Errorf(ctx, err, "failed to prune cluster status")
+		}
+	}
+}
+
+// Prune removes the status of all clusters which do not exist or are not
+// owned by this replica.
+func (p *Pruner) Prune(ctx context.Context) error {
+	// Only the metadata of Clusters is needed, which is the same in all CAPI
+	// versions.
+	var list metav1.PartialObjectMetadataList
+	list.SetGroupVersionKind(p.clusterGroupVersion.WithKind("ClusterList"))
+
+	err := p.ctrlClient.List(ctx, &list)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	keep := map[string]bool{}
+	for i := range list.Items {
+		cl := &list.Items[i]
+
+		if p.sharding != nil {
+			owns, err := p.sharding.Owns(cl)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			if !owns {
+				continue
+			}
+		}
+
+		keep[statusKey(cl.GetNamespace(), cl.GetName())] = true
+	}
+
+	pruned := p.recorder.Prune(func(namespace, name string) bool {
+		return keep[statusKey(namespace, name)]
+	})
+	if pruned > 0 {
+		p.logger.Debugf(ctx, "pruned status of %d clusters", pruned)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package clusterstatus
+
+import (
+	"time"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/flag/service/proxy"
+)
+
+// ClusterStatus is the diagnostic view of a single watched cluster as last
+// seen by the controller.
+type ClusterStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	ClusterID string `json:"clusterID"`
+
+	Provider string       `json:"provider"`
+	Private  bool         `json:"private"`
+	Proxy    *proxy.Proxy `json:"proxy,omitempty"`
+
+	// ValuesHashes maps the kind and name of every rendered values document,
+	// e.g. configmap/abc12-cluster-values, to the sha256 of its content.
+	ValuesHashes map[string]string `json:"valuesHashes,omitempty"`
+	// SkippedExtraValues maps the kind of values, configmap or secret, to
+	// the reasons extra values sources were skipped for being invalid.
+	SkippedExtraValues map[string][]string `json:"skippedExtraValues,omitempty"`
+	Apps               []AppStatus         `json:"apps,omitempty"`
+	// Resources maps operatorkit resource names to the outcome of their last
+	// reconciliation.
+	Resources map[string]ResourceStatus `json:"resources,omitempty"`
+}
+
+type AppStatus struct {
+	Name    string `json:"name"`
+	Catalog string `json:"catalog"`
+	Version string `json:"version"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type ResourceStatus struct {
+	LastReconcileTime time.Time `json:"lastReconcileTime"`
+	LastError         string    `json:"lastError,omitempty"`
+}
@@ -0,0 +1,171 @@
+// Package cni detects the CNI of a workload cluster. The CNI is taken from
+// the following sources, highest precedence first:
+//
+//   - the LabelCNI label of the Cluster,
+//   - the `cluster.cni.kind` value in the user values ConfigMap of the
+//     cluster app, which is the App named after the cluster,
+//   - the presence of a cilium or calico App of the cluster, where cilium
+//     wins when both exist during a migration.
+//
+// Invalid cluster app values are logged and skipped, so they do not block
+// the reconciliation of the cluster.
+package cni
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+type Config struct {
+	// AppReader lists the App CRs of the cluster. It is used in every
+	// reconciliation, so it should be backed by an informer cache.
+	AppReader  client.Reader
+	CtrlClient client.Client
+	Logger     micrologger.Logger
+}
+
+type Detector struct {
+	appReader  client.Reader
+	ctrlClient client.Client
+	logger     micrologger.Logger
+}
+
+func New(config Config) (*Detector, error) {
+	if config.AppReader == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.AppReader must not be empty", config)
+	}
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	d := &Detector{
+		appReader:  config.AppReader,
+		ctrlClient: config.CtrlClient,
+		logger:     config.Logger,
+	}
+
+	return d, nil
+}
+
+// Detection is the detected CNI of a cluster.
+type Detection struct {
+	// Kind is the CNI, e.g. KindCilium or KindCalico. It is empty when the
+	// CNI could not be detected.
+	Kind string
+	// Source describes where the CNI was detected.
+	Source string
+}
+
+// Managed returns true when the CNI is installed with the cluster apps, or
+// is not known, in which case the provider defaults apply.
+func (d Detection) Managed() bool {
+	return d.Kind == "" || d.Kind == KindCilium || d.Kind == KindCalico
+}
+
+// Detect returns the CNI of the cluster. It returns an empty Detection on a
+// nil Detector.
+func (d *Detector) Detect(ctx context.Context, cr key.Cluster) (Detection, error) {
+	if d == nil {
+		return Detection{}, nil
+	}
+
+	if kind := cr.GetLabels()[LabelCNI]; kind != "" {
+		return Detection{Kind: kind, Source: "label " + LabelCNI}, nil
+	}
+
+	kind, err := d.clusterAppKind(ctx, cr)
+	if IsInvalidValues(err) {
+		d.logger.Errorf(ctx, err, "ignoring cni of cluster app values of cluster '%s/%s'", cr.GetNamespace(), key.ClusterID(cr))
+	} else if err != nil {
+		return Detection{}, microerror.Mask(err)
+	}
+	if kind != "" {
+		return Detection{Kind: kind, Source: "cluster app values"}, nil
+	}
+
+	kind, err = d.appKind(ctx, cr)
+	if err != nil {
+		return Detection{}, microerror.Mask(err)
+	}
+	if kind != "" {
+		return Detection{Kind: kind, Source: "app " + kind}, nil
+	}
+
+	return Detection{}, nil
+}
+
+// clusterAppKind returns the `cluster.cni.kind` value of the user values of
+// the cluster app.
+func (d *Detector) clusterAppKind(ctx context.Context, cr key.Cluster) (string, error) {
+	var app v1alpha1.App
+	err := d.ctrlClient.Get(ctx, client.ObjectKey{Namespace: cr.GetNamespace(), Name: key.ClusterID(cr)}, &app)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	ref := app.Spec.UserConfig.ConfigMap
+	if ref.Name == "" {
+		return "", nil
+	}
+
+	var cm corev1.ConfigMap
+	err = d.ctrlClient.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, &cm)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var values map[string]interface{}
+	err = yaml.Unmarshal([]byte(cm.Data[valuesKey]), &values)
+	if err != nil {
+		return "", microerror.Maskf(invalidValuesError, "configmap '%s/%s' key %#q must contain a YAML object: %s", cm.Namespace, cm.Name, valuesKey, err)
+	}
+
+	kind, _, err := unstructured.NestedString(values, "cluster", "cni", "kind")
+	if err != nil {
+		return "", microerror.Maskf(invalidValuesError, "configmap '%s/%s': %s", cm.Namespace, cm.Name, err)
+	}
+
+	return kind, nil
+}
+
+// appKind returns the CNI of the cilium or calico App of the cluster.
+func (d *Detector) appKind(ctx context.Context, cr key.Cluster) (string, error) {
+	var list v1alpha1.AppList
+	err := d.appReader.List(ctx, &list,
+		client.InNamespace(cr.GetNamespace()),
+		client.MatchingLabels{label.Cluster: key.ClusterID(cr)},
+	)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	var kind string
+	for _, app := range list.Items {
+		switch app.Spec.Name {
+		case KindCilium:
+			return KindCilium, nil
+		case KindCalico:
+			kind = KindCalico
+		}
+	}
+
+	return kind, nil
+}
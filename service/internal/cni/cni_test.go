@@ -0,0 +1,172 @@
+package cni
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_Detect(t *testing.T) {
+	testCases := []struct {
+		name              string
+		labels            map[string]string
+		objects           []runtime.Object
+		expectedDetection Detection
+		expectedManaged   bool
+	}{
+		{
+			name:            "case 0: nothing detected",
+			expectedManaged: true,
+		},
+		{
+			name:   "case 1: label takes precedence",
+			labels: map[string]string{LabelCNI: "aws-vpc-cni"},
+			objects: []runtime.Object{
+				newApp("demo0-cilium", "cilium", "demo0"),
+			},
+			expectedDetection: Detection{Kind: "aws-vpc-cni", Source: "label " + LabelCNI},
+		},
+		{
+			name: "case 2: cluster app values take precedence over apps",
+			objects: append(newClusterApp("cluster:\n  cni:\n    kind: calico\n"),
+				newApp("demo0-cilium", "cilium", "demo0"),
+			),
+			expectedDetection: Detection{Kind: KindCalico, Source: "cluster app values"},
+			expectedManaged:   true,
+		},
+		{
+			name: "case 3: cilium app of the cluster",
+			objects: append(newClusterApp("global:\n  foo: bar\n"),
+				newApp("demo0-cilium", "cilium", "demo0"),
+			),
+			expectedDetection: Detection{Kind: KindCilium, Source: "app cilium"},
+			expectedManaged:   true,
+		},
+		{
+			name: "case 4: cilium wins over calico",
+			objects: []runtime.Object{
+				newApp("demo0-calico", "calico", "demo0"),
+				newApp("demo0-cilium", "cilium", "demo0"),
+			},
+			expectedDetection: Detection{Kind: KindCilium, Source: "app cilium"},
+			expectedManaged:   true,
+		},
+		{
+			name: "case 5: apps of other clusters are ignored",
+			objects: []runtime.Object{
+				newApp("other-calico", "calico", "other"),
+			},
+			expectedManaged: true,
+		},
+		{
+			name: "case 6: invalid cluster app values fall through to apps",
+			objects: append(newClusterApp("cluster: ["),
+				newApp("demo0-cilium", "cilium", "demo0"),
+			),
+			expectedDetection: Detection{Kind: KindCilium, Source: "app cilium"},
+			expectedManaged:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			for _, addToScheme := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, v1alpha1.AddToScheme, capi.AddToScheme} {
+				err := addToScheme(scheme)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cluster := &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "demo0",
+					Namespace: "org-acme",
+					Labels:    map[string]string{label.Cluster: "demo0"},
+				},
+			}
+			for k, v := range tc.labels {
+				cluster.Labels[k] = v
+			}
+
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(tc.objects...).
+				Build()
+
+			d, err := New(Config{
+				AppReader:  ctrlClient,
+				CtrlClient: ctrlClient,
+				Logger:     microloggertest.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			detection, err := d.Detect(context.Background(), cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if detection != tc.expectedDetection {
+				t.Fatalf("expected detection %#v, got %#v", tc.expectedDetection, detection)
+			}
+			if detection.Managed() != tc.expectedManaged {
+				t.Fatalf("expected managed %t, got %t", tc.expectedManaged, detection.Managed())
+			}
+		})
+	}
+}
+
+func newApp(name, appName, clusterID string) *v1alpha1.App {
+	return &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "org-acme",
+			Labels:    map[string]string{label.Cluster: clusterID},
+		},
+		Spec: v1alpha1.AppSpec{
+			Name: appName,
+		},
+	}
+}
+
+// newClusterApp returns the cluster app of the test cluster and its user
+// values ConfigMap.
+func newClusterApp(values string) []runtime.Object {
+	app := &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo0",
+			Namespace: "org-acme",
+		},
+		Spec: v1alpha1.AppSpec{
+			Name: "cluster-aws",
+			UserConfig: v1alpha1.AppSpecUserConfig{
+				ConfigMap: v1alpha1.AppSpecUserConfigConfigMap{
+					Name:      "demo0-userconfig",
+					Namespace: "org-acme",
+				},
+			},
+		},
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo0-userconfig",
+			Namespace: "org-acme",
+		},
+		Data: map[string]string{
+			valuesKey: values,
+		},
+	}
+
+	return []runtime.Object{app, cm}
+}
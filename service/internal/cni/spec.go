@@ -0,0 +1,13 @@
+package cni
+
+const (
+	// LabelCNI is the Cluster label setting the CNI explicitly. Values other
+	// than KindCilium and KindCalico name a CNI not installed with the
+	// cluster apps.
+	LabelCNI = "cluster-apps-operator.giantswarm.io/cni"
+
+	KindCalico = "calico"
+	KindCilium = "cilium"
+
+	valuesKey = "values"
+)
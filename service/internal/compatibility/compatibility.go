@@ -0,0 +1,156 @@
+// Package compatibility selects operator versions compatible with the
+// Kubernetes version of a workload cluster. The matrix is read from a YAML
+// file holding a list of Entry objects. Both Kubernetes version bounds of an
+// entry are optional, the minimum is inclusive and the maximum exclusive.
+// Entries are evaluated in order and the first matching one wins.
+package compatibility
+
+import (
+	"os"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+// Entry maps a range of Kubernetes versions to an operator version.
+type Entry struct {
+	MinKubernetesVersion string `json:"minKubernetesVersion,omitempty"`
+	MaxKubernetesVersion string `json:"maxKubernetesVersion,omitempty"`
+	// Catalog is optional. When empty the configured catalog is kept.
+	Catalog string `json:"catalog,omitempty"`
+	Version string `json:"version"`
+}
+
+// Matrix is a parsed compatibility matrix. The nil Matrix is valid and
+// matches no Kubernetes version, so it can be used when no matrix is
+// configured.
+type Matrix struct {
+	entries []entry
+}
+
+type entry struct {
+	Entry
+
+	min *version.Version
+	max *version.Version
+}
+
+// Load reads the matrix from the YAML file at path. An empty path returns
+// the nil Matrix.
+func Load(path string) (*Matrix, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	// #nosec G304 -- the path is configured by the operator flags.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var entries []Entry
+	err = yaml.UnmarshalStrict(data, &entries)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "parsing compatibility matrix %#q: %s", path, err)
+	}
+
+	m, err := New(entries)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return m, nil
+}
+
+// New returns the Matrix of the given entries.
+func New(entries []Entry) (*Matrix, error) {
+	m := &Matrix{}
+
+	for i, e := range entries {
+		if e.Version == "" {
+			return nil, microerror.Maskf(invalidConfigError, "compatibility matrix entry %d must have a version", i)
+		}
+
+		parsed := entry{Entry: e}
+
+		if e.MinKubernetesVersion != "" {
+			v, err := version.ParseGeneric(e.MinKubernetesVersion)
+			if err != nil {
+				return nil, microerror.Maskf(invalidConfigError, "compatibility matrix entry %d: %s", i, err)
+			}
+			parsed.min = v
+		}
+		if e.MaxKubernetesVersion != "" {
+			v, err := version.ParseGeneric(e.MaxKubernetesVersion)
+			if err != nil {
+				return nil, microerror.Maskf(invalidConfigError, "compatibility matrix entry %d: %s", i, err)
+			}
+			parsed.max = v
+		}
+		if parsed.min != nil && parsed.max != nil && !parsed.min.LessThan(parsed.max) {
+			return nil, microerror.Maskf(invalidConfigError, "compatibility matrix entry %d: minimum %#q must be lower than maximum %#q", i, e.MinKubernetesVersion, e.MaxKubernetesVersion)
+		}
+
+		m.entries = append(m.entries, parsed)
+	}
+
+	return m, nil
+}
+
+// Lookup returns the first entry whose range contains the given Kubernetes
+// version. It returns false when no entry matches or the version cannot be
+// parsed.
+func (m *Matrix) Lookup(kubernetesVersion string) (Entry, bool) {
+	if m == nil {
+		return Entry{}, false
+	}
+
+	v, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return Entry{}, false
+	}
+
+	for _, e := range m.entries {
+		if e.min != nil && v.LessThan(e.min) {
+			continue
+		}
+		if e.max != nil && !v.LessThan(e.max) {
+			continue
+		}
+
+		return e.Entry, true
+	}
+
+	return Entry{}, false
+}
+
+// Newest returns the entry with the highest operator version, which is the
+// version clusters are on unless the matrix holds them back. Entries whose
+// version cannot be parsed are skipped. It returns false when no entry is
+// left.
+func (m *Matrix) Newest() (Entry, bool) {
+	if m == nil {
+		return Entry{}, false
+	}
+
+	var newest *entry
+	var newestVersion *version.Version
+	for i, e := range m.entries {
+		v, err := version.ParseGeneric(e.Version)
+		if err != nil {
+			continue
+		}
+
+		if newestVersion == nil || newestVersion.LessThan(v) {
+			newest = &m.entries[i]
+			newestVersion = v
+		}
+	}
+
+	if newest == nil {
+		return Entry{}, false
+	}
+
+	return newest.Entry, true
+}
@@ -0,0 +1,184 @@
+package compatibility
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Matrix_Lookup(t *testing.T) {
+	matrix, err := New([]Entry{
+		{MaxKubernetesVersion: "1.24.0", Catalog: "default-old", Version: "2.0.0"},
+		{MinKubernetesVersion: "1.24.0", MaxKubernetesVersion: "1.27.0", Version: "3.0.0"},
+		{MinKubernetesVersion: "1.27.0", Version: "4.0.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name              string
+		matrix            *Matrix
+		kubernetesVersion string
+		expectedVersion   string
+		expectedFound     bool
+	}{
+		{
+			name:              "case 0: version below the first maximum",
+			matrix:            matrix,
+			kubernetesVersion: "v1.23.17",
+			expectedVersion:   "2.0.0",
+			expectedFound:     true,
+		},
+		{
+			name:              "case 1: minimum is inclusive",
+			matrix:            matrix,
+			kubernetesVersion: "1.24.0",
+			expectedVersion:   "3.0.0",
+			expectedFound:     true,
+		},
+		{
+			name:              "case 2: maximum is exclusive",
+			matrix:            matrix,
+			kubernetesVersion: "v1.27.0",
+			expectedVersion:   "4.0.0",
+			expectedFound:     true,
+		},
+		{
+			name:              "case 3: minor version without patch",
+			matrix:            matrix,
+			kubernetesVersion: "1.26",
+			expectedVersion:   "3.0.0",
+			expectedFound:     true,
+		},
+		{
+			name:              "case 4: unparsable version",
+			matrix:            matrix,
+			kubernetesVersion: "latest",
+		},
+		{
+			name:              "case 5: nil matrix",
+			kubernetesVersion: "1.30.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry, found := tc.matrix.Lookup(tc.kubernetesVersion)
+			if found != tc.expectedFound {
+				t.Fatalf("expected found %t, got %t", tc.expectedFound, found)
+			}
+			if entry.Version != tc.expectedVersion {
+				t.Fatalf("expected version %#q, got %#q", tc.expectedVersion, entry.Version)
+			}
+		})
+	}
+}
+
+func Test_Matrix_Newest(t *testing.T) {
+	testCases := []struct {
+		name            string
+		entries         []Entry
+		expectedVersion string
+		expectedFound   bool
+	}{
+		{
+			name: "case 0: highest version wins regardless of order",
+			entries: []Entry{
+				{MaxKubernetesVersion: "1.24.0", Version: "2.0.0"},
+				{MinKubernetesVersion: "1.27.0", Version: "v4.1.0"},
+				{MinKubernetesVersion: "1.24.0", MaxKubernetesVersion: "1.27.0", Version: "3.0.0"},
+			},
+			expectedVersion: "v4.1.0",
+			expectedFound:   true,
+		},
+		{
+			name: "case 1: unparsable versions are skipped",
+			entries: []Entry{
+				{Version: "latest"},
+				{Version: "3.0.0"},
+			},
+			expectedVersion: "3.0.0",
+			expectedFound:   true,
+		},
+		{
+			name: "case 2: nil matrix",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var matrix *Matrix
+			if tc.entries != nil {
+				var err error
+				matrix, err = New(tc.entries)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entry, found := matrix.Newest()
+			if found != tc.expectedFound {
+				t.Fatalf("expected found %t, got %t", tc.expectedFound, found)
+			}
+			if entry.Version != tc.expectedVersion {
+				t.Fatalf("expected version %#q, got %#q", tc.expectedVersion, entry.Version)
+			}
+		})
+	}
+}
+
+func Test_Load(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		expectedError bool
+	}{
+		{
+			name: "case 0: valid matrix",
+			content: `- maxKubernetesVersion: 1.27.0
+  catalog: default
+  version: 3.2.1
+`,
+		},
+		{
+			name: "case 1: entry without version",
+			content: `- maxKubernetesVersion: 1.27.0
+`,
+			expectedError: true,
+		},
+		{
+			name: "case 2: unknown field",
+			content: `- maxKubernetes: 1.27.0
+  version: 3.2.1
+`,
+			expectedError: true,
+		},
+		{
+			name: "case 3: empty range",
+			content: `- minKubernetesVersion: 1.27.0
+  maxKubernetesVersion: 1.27.0
+  version: 3.2.1
+`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "matrix.yaml")
+			err := os.WriteFile(path, []byte(tc.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Load(path)
+			if tc.expectedError && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error, got %v", err)
+			}
+			if !tc.expectedError && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
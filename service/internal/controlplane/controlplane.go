@@ -0,0 +1,140 @@
+// Package controlplane decides how chart-operator is installed in a workload
+// cluster based on the provider of its control plane. Providers are looked up
+// by the kind of the control plane object referenced by the Cluster, so
+// supporting another provider only needs an entry in the provider table.
+package controlplane
+
+import (
+	"context"
+	"slices"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+// DefaultAPIServerPort is used when the API server port of a cluster is not
+// configured anywhere.
+const DefaultAPIServerPort int32 = 6443
+
+// Provider is the entry of a control plane kind in the provider table.
+type Provider struct {
+	// Name identifies the provider in logs.
+	Name string
+	// InfrastructureKinds is optional. When set the entry only applies to
+	// clusters with an infrastructure object of one of these kinds.
+	InfrastructureKinds []string
+	// BootstrapMode runs chart-operator with host networking so it can
+	// install charts before the CNI is ready.
+	BootstrapMode bool
+	// InstallCNI installs the CNI with the cluster apps.
+	InstallCNI bool
+	// APIServerPortPath is optional. It is the path of the port the API
+	// server pods listen on in the control plane object.
+	APIServerPortPath []string
+	// ClusterNetworkAPIServerPort is set for control planes binding the API
+	// server pods to spec.clusterNetwork.apiServerPort of the Cluster when
+	// their object does not configure a port.
+	ClusterNetworkAPIServerPort bool
+	// EndpointAPIServerPort is set for control planes whose API server
+	// listens on the port of spec.controlPlaneEndpoint. For other providers
+	// it is the port of a load balancer in front of the API server pods and
+	// is not used.
+	EndpointAPIServerPort bool
+	// DisableClientCache disables the Kubernetes client cache of
+	// app-operator in the workload cluster.
+	DisableClientCache bool
+}
+
+// Settings are the chart-operator settings of a cluster.
+type Settings struct {
+	Provider           string
+	BootstrapMode      bool
+	InstallCNI         bool
+	DisableClientCache bool
+	APIServerPort      int32
+}
+
+// Lookup returns the provider of the control plane of the cluster.
+func Lookup(cr key.Cluster) Provider {
+	ref := key.ControlPlaneRef(cr)
+	if ref == nil {
+		return defaultProvider
+	}
+
+	p, ok := providers[ref.Kind]
+	if !ok {
+		return defaultProvider
+	}
+
+	if len(p.InfrastructureKinds) > 0 {
+		infrastructureRef := key.InfrastructureRef(cr)
+		if infrastructureRef == nil || !slices.Contains(p.InfrastructureKinds, infrastructureRef.Kind) {
+			return defaultProvider
+		}
+	}
+
+	return p
+}
+
+// GetSettings returns the chart-operator settings of the cluster. The API
+// server port is taken from the control plane object, the API server port of
+// the cluster network for providers with ClusterNetworkAPIServerPort or the
+// control plane endpoint for providers with EndpointAPIServerPort, whichever
+// is set first, and defaults to DefaultAPIServerPort.
+func GetSettings(ctx context.Context, resolver *infra.Resolver, cr key.Cluster) (Settings, error) {
+	p := Lookup(cr)
+
+	port, err := controlPlanePort(ctx, resolver, cr, p)
+	if err != nil {
+		return Settings{}, microerror.Mask(err)
+	}
+	if port == 0 && p.ClusterNetworkAPIServerPort {
+		port = key.ClusterNetworkOf(cr).APIServerPort
+	}
+	if port == 0 && p.EndpointAPIServerPort {
+		port = key.ControlPlaneEndpointPort(cr)
+	}
+	if port == 0 {
+		port = DefaultAPIServerPort
+	}
+
+	s := Settings{
+		Provider:           p.Name,
+		BootstrapMode:      p.BootstrapMode,
+		InstallCNI:         p.InstallCNI,
+		DisableClientCache: p.DisableClientCache,
+		APIServerPort:      port,
+	}
+
+	return s, nil
+}
+
+func controlPlanePort(ctx context.Context, resolver *infra.Resolver, cr key.Cluster, p Provider) (int32, error) {
+	if len(p.APIServerPortPath) == 0 {
+		return 0, nil
+	}
+
+	ref := key.ControlPlaneRef(cr)
+
+	controlPlane, err := resolver.Get(ctx, cr, ref.GroupVersionKind(), ref.Namespace, ref.Name)
+	if infra.IsNotFound(err) || infra.IsKindNotServed(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	port, _, err := unstructured.NestedInt64(controlPlane.Object, p.APIServerPortPath...)
+	if err != nil {
+		return 0, microerror.Maskf(invalidControlPlaneError, "%s: %s", ref.Kind, err)
+	}
+
+	if port < 0 || port > 65535 {
+		return 0, microerror.Maskf(invalidControlPlaneError, "%s: port %d out of range", ref.Kind, port)
+	}
+
+	// #nosec G115 -- the port is in range.
+	return int32(port), nil
+}
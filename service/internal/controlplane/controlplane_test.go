@@ -0,0 +1,218 @@
+package controlplane
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
+)
+
+func Test_GetSettings(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cluster          *capi.Cluster
+		controlPlane     *unstructured.Unstructured
+		expectedSettings Settings
+		expectedErr      bool
+	}{
+		{
+			name:    "case 0: cluster without control plane ignores control plane endpoint port",
+			cluster: newCluster("", "AWSCluster", 443, 7443),
+			expectedSettings: Settings{
+				BootstrapMode: true,
+				InstallCNI:    true,
+				APIServerPort: DefaultAPIServerPort,
+			},
+		},
+		{
+			name:         "case 1: kubeadm port taken from control plane",
+			cluster:      newCluster(KubeadmControlPlaneKind, "AWSCluster", 8443, 443),
+			controlPlane: newControlPlane(KubeadmControlPlaneKind, int64(6444), "kubeadmConfigSpec", "initConfiguration", "localAPIEndpoint", "bindPort"),
+			expectedSettings: Settings{
+				Provider:      "kubeadm",
+				BootstrapMode: true,
+				InstallCNI:    true,
+				APIServerPort: 6444,
+			},
+		},
+		{
+			name:         "case 2: kubeadm port taken from cluster network",
+			cluster:      newCluster(KubeadmControlPlaneKind, "AWSCluster", 8443, 0),
+			controlPlane: newControlPlane(KubeadmControlPlaneKind, nil),
+			expectedSettings: Settings{
+				Provider:      "kubeadm",
+				BootstrapMode: true,
+				InstallCNI:    true,
+				APIServerPort: 8443,
+			},
+		},
+		{
+			name:    "case 3: rke2 ignores control plane endpoint port",
+			cluster: newCluster(RKE2ControlPlaneKind, "VSphereCluster", 0, 8443),
+			expectedSettings: Settings{
+				Provider:      "rke2",
+				BootstrapMode: true,
+				InstallCNI:    true,
+				APIServerPort: DefaultAPIServerPort,
+			},
+		},
+		{
+			name:    "case 4: kamaji disables bootstrap mode",
+			cluster: newCluster(KamajiControlPlaneKind, "ProxmoxCluster", 0, 0),
+			expectedSettings: Settings{
+				Provider:      "kamaji",
+				InstallCNI:    true,
+				APIServerPort: DefaultAPIServerPort,
+			},
+		},
+		{
+			name:         "case 5: k0smotron port taken from control plane",
+			cluster:      newCluster(K0smotronControlPlaneKind, "ProxmoxCluster", 0, 0),
+			controlPlane: newControlPlane(K0smotronControlPlaneKind, int64(30443), "service", "apiPort"),
+			expectedSettings: Settings{
+				Provider:      "k0smotron",
+				InstallCNI:    true,
+				APIServerPort: 30443,
+			},
+		},
+		{
+			name:    "case 6: eks disables bootstrap mode, CNI and client cache",
+			cluster: newCluster(AWSManagedControlPlaneKind, "AWSManagedCluster", 0, 443),
+			expectedSettings: Settings{
+				Provider:           "eks",
+				DisableClientCache: true,
+				APIServerPort:      443,
+			},
+		},
+		{
+			name:    "case 7: managed control plane with other infrastructure uses defaults",
+			cluster: newCluster(AWSManagedControlPlaneKind, "AWSCluster", 0, 0),
+			expectedSettings: Settings{
+				BootstrapMode: true,
+				InstallCNI:    true,
+				APIServerPort: DefaultAPIServerPort,
+			},
+		},
+		{
+			name:    "case 8: capz aks disables bootstrap mode, CNI and client cache",
+			cluster: newCluster(AzureManagedControlPlaneKind, "AzureManagedCluster", 0, 443),
+			expectedSettings: Settings{
+				Provider:           "aks",
+				DisableClientCache: true,
+				APIServerPort:      443,
+			},
+		},
+		{
+			name:         "case 9: kubeadm ignores control plane endpoint port",
+			cluster:      newCluster(KubeadmControlPlaneKind, "AWSCluster", 0, 443),
+			controlPlane: newControlPlane(KubeadmControlPlaneKind, nil),
+			expectedSettings: Settings{
+				Provider:      "kubeadm",
+				BootstrapMode: true,
+				InstallCNI:    true,
+				APIServerPort: DefaultAPIServerPort,
+			},
+		},
+		{
+			name:         "case 10: invalid port in control plane",
+			cluster:      newCluster(KubeadmControlPlaneKind, "AWSCluster", 0, 0),
+			controlPlane: newControlPlane(KubeadmControlPlaneKind, "6443", "kubeadmConfigSpec", "initConfiguration", "localAPIEndpoint", "bindPort"),
+			expectedErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			err := capi.AddToScheme(scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			objects := []runtime.Object{tc.cluster}
+			if tc.controlPlane != nil {
+				objects = append(objects, tc.controlPlane)
+			}
+
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(infrastructuretest.NewRESTMapper(objects...)).
+				WithRuntimeObjects(objects...).
+				Build()
+
+			settings, err := GetSettings(context.Background(), infrastructuretest.NewResolver(t, ctrlClient), tc.cluster)
+			if tc.expectedErr {
+				if !IsInvalidControlPlane(err) {
+					t.Fatalf("expected invalid control plane error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if settings != tc.expectedSettings {
+				t.Fatalf("expected settings %#v, got %#v", tc.expectedSettings, settings)
+			}
+		})
+	}
+}
+
+func newCluster(controlPlaneKind, infrastructureKind string, apiServerPort, endpointPort int32) *capi.Cluster {
+	cluster := &capi.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: capi.GroupVersion.String(),
+			Kind:       "Cluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo0",
+			Namespace: "org-acme",
+		},
+		Spec: capi.ClusterSpec{
+			ClusterNetwork:       capi.ClusterNetwork{APIServerPort: apiServerPort},
+			ControlPlaneEndpoint: capi.APIEndpoint{Host: "api.demo0.example.com", Port: endpointPort},
+			InfrastructureRef: capi.ContractVersionedObjectReference{
+				APIGroup: "infrastructure.cluster.x-k8s.io",
+				Kind:     infrastructureKind,
+				Name:     "demo0",
+			},
+		},
+	}
+	if controlPlaneKind != "" {
+		cluster.Spec.ControlPlaneRef = capi.ContractVersionedObjectReference{
+			APIGroup: "controlplane.cluster.x-k8s.io",
+			Kind:     controlPlaneKind,
+			Name:     "demo0",
+		}
+	}
+
+	return cluster
+}
+
+func newControlPlane(kind string, port interface{}, path ...string) *unstructured.Unstructured {
+	controlPlane := &unstructured.Unstructured{
+		Object: map[string]interface{}{},
+	}
+	controlPlane.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "controlplane.cluster.x-k8s.io",
+		Version: "v1beta2",
+		Kind:    kind,
+	})
+	controlPlane.SetName("demo0")
+	controlPlane.SetNamespace("org-acme")
+
+	if port != nil {
+		err := unstructured.SetNestedField(controlPlane.Object, port, append([]string{"spec"}, path...)...)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return controlPlane
+}
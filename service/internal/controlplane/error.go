@@ -0,0 +1,12 @@
+package controlplane
+
+import "github.com/giantswarm/microerror"
+
+var invalidControlPlaneError = &microerror.Error{
+	Kind: "invalidControlPlaneError",
+}
+
+// IsInvalidControlPlane asserts invalidControlPlaneError.
+func IsInvalidControlPlane(err error) bool {
+	return microerror.Cause(err) == invalidControlPlaneError
+}
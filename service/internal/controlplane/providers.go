@@ -0,0 +1,84 @@
+package controlplane
+
+import (
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+const (
+	KubeadmControlPlaneKind   = "KubeadmControlPlane"
+	KamajiControlPlaneKind    = "KamajiControlPlane"
+	K0sControlPlaneKind       = "K0sControlPlane"
+	K0smotronControlPlaneKind = "K0smotronControlPlane"
+	RKE2ControlPlaneKind      = "RKE2ControlPlane"
+	TalosControlPlaneKind     = "TalosControlPlane"
+
+	AWSManagedControlPlaneKind   = "AWSManagedControlPlane"
+	AzureManagedControlPlaneKind = "AzureManagedControlPlane"
+)
+
+// providers maps control plane kinds to the configuration of their
+// provider. Control planes of kinds not listed get defaultProvider.
+var providers = map[string]Provider{
+	KubeadmControlPlaneKind: {
+		Name:                        "kubeadm",
+		BootstrapMode:               true,
+		InstallCNI:                  true,
+		APIServerPortPath:           []string{"spec", "kubeadmConfigSpec", "initConfiguration", "localAPIEndpoint", "bindPort"},
+		ClusterNetworkAPIServerPort: true,
+	},
+	// Kamaji and k0smotron run the API server as pods in the management
+	// cluster, so chart-operator does not need host networking.
+	KamajiControlPlaneKind: {
+		Name:       "kamaji",
+		InstallCNI: true,
+	},
+	K0smotronControlPlaneKind: {
+		Name:              "k0smotron",
+		InstallCNI:        true,
+		APIServerPortPath: []string{"spec", "service", "apiPort"},
+	},
+	K0sControlPlaneKind: {
+		Name:              "k0s",
+		BootstrapMode:     true,
+		InstallCNI:        true,
+		APIServerPortPath: []string{"spec", "k0sConfigSpec", "k0s", "spec", "api", "port"},
+	},
+	RKE2ControlPlaneKind: {
+		Name:          "rke2",
+		BootstrapMode: true,
+		InstallCNI:    true,
+	},
+	TalosControlPlaneKind: {
+		Name:          "talos",
+		BootstrapMode: true,
+		InstallCNI:    true,
+	},
+	// Managed control planes come with a CNI installed by the cloud
+	// provider. Their API server is only reachable through the control plane
+	// endpoint.
+	AWSManagedControlPlaneKind: {
+		Name:                  "eks",
+		InfrastructureKinds:   []string{infra.AWSManagedClusterKind},
+		EndpointAPIServerPort: true,
+		DisableClientCache:    true,
+	},
+	AzureManagedControlPlaneKind: {
+		Name:                  "aks",
+		InfrastructureKinds:   []string{infra.AzureManagedClusterKind},
+		EndpointAPIServerPort: true,
+		DisableClientCache:    true,
+	},
+	infra.AzureASOManagedControlPlaneKind: {
+		Name:                  "aks",
+		InfrastructureKinds:   []string{infra.AzureASOManagedClusterKind},
+		EndpointAPIServerPort: true,
+		DisableClientCache:    true,
+	},
+}
+
+// defaultProvider is used for clusters without control plane reference and
+// control planes of unknown kinds.
+var defaultProvider = Provider{
+	BootstrapMode: true,
+	InstallCNI:    true,
+}
@@ -0,0 +1,21 @@
+package extravalues
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidValuesError = &microerror.Error{
+	Kind: "invalidValuesError",
+}
+
+// IsInvalidValues asserts invalidValuesError.
+func IsInvalidValues(err error) bool {
+	return microerror.Cause(err) == invalidValuesError
+}
@@ -0,0 +1,399 @@
+// Package extravalues loads user supplied values from ConfigMaps and Secrets
+// which are merged into the generated cluster values.
+//
+// Values are merged with the following precedence, lowest first:
+//
+//   - the installation wide ConfigMap or Secret configured via flag,
+//   - ConfigMaps or Secrets in the cluster namespace labelled with the
+//     cluster ID and LabelExtraValues, ordered by name,
+//   - ConfigMaps or Secrets listed in the AnnotationConfigMaps or
+//     AnnotationSecrets annotation of the Cluster, in the listed order,
+//   - the values generated by the operator,
+//   - the ClusterClass topology variables of the Cluster allowlisted via
+//     flag, set at their configured path.
+//
+// Sources which do not hold valid values are skipped, reported with a warning
+// event for the Cluster and recorded in the cluster status, so they do not
+// block the reconciliation of the cluster.
+package extravalues
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/jsonschema"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+)
+
+type Config struct {
+	CtrlClient client.Client
+	Logger     micrologger.Logger
+	// EventRecorder and Recorder are optional. When set, skipped sources are
+	// reported as events for the Cluster and recorded in its status.
+	EventRecorder record.EventRecorder
+	Recorder      *clusterstatus.Recorder
+
+	// InstallationConfigMap and InstallationSecret reference the
+	// installation wide extra values as namespace/name. Both are optional.
+	InstallationConfigMap string
+	InstallationSecret    string
+	// TopologyVariablesConfigMap and TopologyVariablesSecret allowlist the
+	// topology variables rendered into the ConfigMap and Secret values, as
+	// name=path. Both are optional.
+	TopologyVariablesConfigMap []string
+	TopologyVariablesSecret    []string
+	// ConfigMapSchema is optional. When set, the values of
+	// TopologyVariablesConfigMap at paths it declares are validated against
+	// it, so they cannot break the generated values they override.
+	ConfigMapSchema *jsonschema.Schema
+}
+
+type ExtraValues struct {
+	ctrlClient    client.Client
+	eventRecorder record.EventRecorder
+	logger        micrologger.Logger
+	recorder      *clusterstatus.Recorder
+
+	installationConfigMap *types.NamespacedName
+	installationSecret    *types.NamespacedName
+
+	configMapSchema            *jsonschema.Schema
+	topologyVariablesConfigMap []topologyVariable
+	topologyVariablesSecret    []topologyVariable
+}
+
+// Values are the extra values of a cluster.
+type Values struct {
+	// Extra are the merged values of all ConfigMaps or Secrets. The
+	// generated values take precedence over them.
+	Extra map[string]interface{}
+	// Topology are the values of the allowlisted topology variables. They
+	// take precedence over the generated values.
+	Topology map[string]interface{}
+	// Sources are the sources which contributed, lowest precedence first.
+	Sources []Source
+}
+
+// Render merges v and the given generated values in the order of their
+// precedence. Neither input is modified.
+func (v Values) Render(generated map[string]interface{}) map[string]interface{} {
+	return Merge(Merge(v.Extra, generated), v.Topology)
+}
+
+// Source is a ConfigMap or Secret which contributed extra values.
+type Source struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (s Source) String() string {
+	return fmt.Sprintf("%s/%s/%s", s.Kind, s.Namespace, s.Name)
+}
+
+func New(config Config) (*ExtraValues, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	installationConfigMap, err := parseNamespacedName(config.InstallationConfigMap)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstallationConfigMap %s", config, err)
+	}
+	installationSecret, err := parseNamespacedName(config.InstallationSecret)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstallationSecret %s", config, err)
+	}
+	topologyVariablesConfigMap, err := parseTopologyVariables(config.TopologyVariablesConfigMap)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TopologyVariablesConfigMap %s", config, err)
+	}
+	topologyVariablesSecret, err := parseTopologyVariables(config.TopologyVariablesSecret)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TopologyVariablesSecret %s", config, err)
+	}
+
+	e := &ExtraValues{
+		ctrlClient:    config.CtrlClient,
+		eventRecorder: config.EventRecorder,
+		logger:        config.Logger,
+		recorder:      config.Recorder,
+
+		installationConfigMap: installationConfigMap,
+		installationSecret:    installationSecret,
+
+		configMapSchema:            config.ConfigMapSchema,
+		topologyVariablesConfigMap: topologyVariablesConfigMap,
+		topologyVariablesSecret:    topologyVariablesSecret,
+	}
+
+	return e, nil
+}
+
+// ConfigMapValues returns the extra values of the ConfigMap of the given
+// cluster. It is safe to call on a nil ExtraValues.
+func (e *ExtraValues) ConfigMapValues(ctx context.Context, cr key.Cluster) (Values, error) {
+	if e == nil {
+		return Values{}, nil
+	}
+
+	values, err := e.values(ctx, cr, kindConfigMap, e.installationConfigMap, e.topologyVariablesConfigMap, e.configMapSchema, AnnotationConfigMaps)
+	if err != nil {
+		return Values{}, microerror.Mask(err)
+	}
+
+	return values, nil
+}
+
+// SecretValues returns the extra values of the Secret of the given cluster.
+// It is safe to call on a nil ExtraValues.
+func (e *ExtraValues) SecretValues(ctx context.Context, cr key.Cluster) (Values, error) {
+	if e == nil {
+		return Values{}, nil
+	}
+
+	values, err := e.values(ctx, cr, kindSecret, e.installationSecret, e.topologyVariablesSecret, nil, AnnotationSecrets)
+	if err != nil {
+		return Values{}, microerror.Mask(err)
+	}
+
+	return values, nil
+}
+
+func (e *ExtraValues) values(ctx context.Context, cr key.Cluster, kind string, installation *types.NamespacedName, variables []topologyVariable, schema *jsonschema.Schema, annotation string) (Values, error) {
+	values := map[string]interface{}{}
+	var sources []Source
+	var skipped []string
+
+	if installation != nil {
+		var err error
+		values, sources, skipped, err = e.merge(ctx, kind, []types.NamespacedName{*installation}, values, sources, skipped)
+		if err != nil {
+			return Values{}, microerror.Mask(err)
+		}
+	}
+
+	var refs []types.NamespacedName
+	{
+		selected, err := e.listSelected(ctx, cr, kind)
+		if err != nil {
+			return Values{}, microerror.Mask(err)
+		}
+		refs = append(refs, selected...)
+
+		for _, name := range strings.Split(cr.GetAnnotations()[annotation], ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			refs = append(refs, types.NamespacedName{Namespace: cr.GetNamespace(), Name: name})
+		}
+	}
+
+	values, sources, skipped, err := e.merge(ctx, kind, refs, values, sources, skipped)
+	if err != nil {
+		return Values{}, microerror.Mask(err)
+	}
+
+	topology, topologySources, invalid := topologyValues(cr, variables, schema)
+	for _, err := range invalid {
+		skipped = append(skipped, err.Error())
+	}
+
+	e.reportSkipped(ctx, cr, kind, skipped)
+
+	v := Values{
+		Extra:    values,
+		Topology: topology,
+		Sources:  append(sources, topologySources...),
+	}
+
+	return v, nil
+}
+
+// merge merges the values of the referenced ConfigMaps or Secrets into values
+// in order and appends them to sources. Missing objects are skipped, invalid
+// ones are skipped and appended to skipped with the reason.
+func (e *ExtraValues) merge(ctx context.Context, kind string, refs []types.NamespacedName, values map[string]interface{}, sources []Source, skipped []string) (map[string]interface{}, []Source, []string, error) {
+	for _, ref := range refs {
+		data, found, err := e.get(ctx, kind, ref)
+		if err != nil {
+			return nil, nil, nil, microerror.Mask(err)
+		}
+		if !found {
+			e.logger.Debugf(ctx, "extra values %s '%s/%s' not found, skipping", kind, ref.Namespace, ref.Name)
+			continue
+		}
+
+		source := Source{Kind: kind, Namespace: ref.Namespace, Name: ref.Name}
+
+		var v map[string]interface{}
+		err = yaml.Unmarshal(data, &v)
+		if err != nil {
+			err = microerror.Maskf(invalidValuesError, "%s key %#q must contain a YAML object: %s", source, ValuesKey, err)
+			skipped = append(skipped, err.Error())
+			continue
+		}
+
+		values = Merge(values, v)
+		sources = append(sources, source)
+	}
+
+	return values, sources, skipped, nil
+}
+
+// reportSkipped logs and reports the sources of the given kind of values
+// which were skipped because they are invalid. The recorded status is reset
+// once no source is skipped anymore.
+func (e *ExtraValues) reportSkipped(ctx context.Context, cr key.Cluster, kind string, skipped []string) {
+	for _, reason := range skipped {
+		e.logger.Debugf(ctx, "skipping extra values: %s", reason)
+		if e.eventRecorder != nil {
+			e.eventRecorder.Event(cr, corev1.EventTypeWarning, InvalidExtraValuesReason, fmt.Sprintf("Skipping extra values: %s", reason))
+		}
+	}
+
+	e.recorder.Update(cr, func(s *clusterstatus.ClusterStatus) {
+		if len(skipped) == 0 {
+			delete(s.SkippedExtraValues, kind)
+			return
+		}
+
+		if s.SkippedExtraValues == nil {
+			s.SkippedExtraValues = map[string][]string{}
+		}
+		s.SkippedExtraValues[kind] = skipped
+	})
+}
+
+func (e *ExtraValues) listSelected(ctx context.Context, cr key.Cluster, kind string) ([]types.NamespacedName, error) {
+	opts := []client.ListOption{
+		client.InNamespace(cr.GetNamespace()),
+		client.MatchingLabels{
+			label.Cluster:    key.ClusterID(cr),
+			LabelExtraValues: "true",
+		},
+	}
+
+	var names []string
+	switch kind {
+	case kindConfigMap:
+		var list corev1.ConfigMapList
+		err := e.ctrlClient.List(ctx, &list, opts...)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+	case kindSecret:
+		var list corev1.SecretList
+		err := e.ctrlClient.List(ctx, &list, opts...)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	var refs []types.NamespacedName
+	for _, name := range names {
+		refs = append(refs, types.NamespacedName{Namespace: cr.GetNamespace(), Name: name})
+	}
+
+	return refs, nil
+}
+
+func (e *ExtraValues) get(ctx context.Context, kind string, ref types.NamespacedName) ([]byte, bool, error) {
+	var data []byte
+
+	switch kind {
+	case kindConfigMap:
+		var cm corev1.ConfigMap
+		err := e.ctrlClient.Get(ctx, ref, &cm)
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		} else if err != nil {
+			return nil, false, microerror.Mask(err)
+		}
+		data = []byte(cm.Data[ValuesKey])
+	case kindSecret:
+		var secret corev1.Secret
+		err := e.ctrlClient.Get(ctx, ref, &secret)
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		} else if err != nil {
+			return nil, false, microerror.Mask(err)
+		}
+		data = secret.Data[ValuesKey]
+	}
+
+	return data, true, nil
+}
+
+// Merge deep merges src into dst and returns the result. Nested objects
+// are merged recursively, every other value of src replaces the one of dst.
+// Neither input is modified.
+func Merge(dst, src map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(dst))
+	for k, v := range dst {
+		merged[k] = v
+	}
+
+	for k, v := range src {
+		srcMap, srcOK := v.(map[string]interface{})
+		dstMap, dstOK := merged[k].(map[string]interface{})
+		if srcOK && dstOK {
+			merged[k] = Merge(dstMap, srcMap)
+			continue
+		}
+
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// SourcesAnnotation returns the value of AnnotationSources for the given
+// sources.
+func SourcesAnnotation(sources []Source) string {
+	var s []string
+	for _, source := range sources {
+		s = append(s, source.String())
+	}
+
+	return strings.Join(s, ",")
+}
+
+func parseNamespacedName(s string) (*types.NamespacedName, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("must be of the form namespace/name, got %#q", s)
+	}
+
+	return &types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
@@ -0,0 +1,389 @@
+package extravalues
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capiv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/jsonschema"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+)
+
+func Test_ExtraValues_ConfigMapValues(t *testing.T) {
+	cluster := &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "eggs2",
+			Namespace: "org-test",
+			Labels: map[string]string{
+				label.Cluster: "eggs2",
+			},
+			Annotations: map[string]string{
+				AnnotationConfigMaps: "annotated, missing",
+			},
+		},
+	}
+
+	objects := []runtime.Object{
+		newConfigMap("giantswarm", "installation", nil, "global:\n  organization: installation\n  monitoring:\n    endpoint: https://installation\n"),
+		newConfigMap("org-test", "selected", map[string]string{label.Cluster: "eggs2", LabelExtraValues: "true"}, "global:\n  organization: selected\n"),
+		newConfigMap("org-test", "other-cluster", map[string]string{label.Cluster: "other", LabelExtraValues: "true"}, "global:\n  organization: other\n"),
+		newConfigMap("org-test", "annotated", nil, "global:\n  monitoring:\n    endpoint: https://annotated\n"),
+		newConfigMap("org-test", "broken", map[string]string{label.Cluster: "eggs2", LabelExtraValues: "true"}, "- not an object\n"),
+	}
+
+	eventRecorder := record.NewFakeRecorder(10)
+	recorder := clusterstatus.New()
+
+	c := Config{
+		CtrlClient:    clientfake.NewClientBuilder().WithRuntimeObjects(objects...).Build(),
+		EventRecorder: eventRecorder,
+		Logger:        microloggertest.New(),
+		Recorder:      recorder,
+
+		InstallationConfigMap: "giantswarm/installation",
+	}
+
+	e, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := e.ConfigMapValues(context.Background(), cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedValues := map[string]interface{}{
+		"global": map[string]interface{}{
+			"organization": "selected",
+			"monitoring": map[string]interface{}{
+				"endpoint": "https://annotated",
+			},
+		},
+	}
+	if !reflect.DeepEqual(values.Extra, expectedValues) {
+		t.Fatalf("expected values %#v got %#v", expectedValues, values.Extra)
+	}
+
+	expectedSources := "configmap/giantswarm/installation,configmap/org-test/selected,configmap/org-test/annotated"
+	if SourcesAnnotation(values.Sources) != expectedSources {
+		t.Fatalf("expected sources %#q got %#q", expectedSources, SourcesAnnotation(values.Sources))
+	}
+
+	// The invalid source is skipped without failing, reported as event and
+	// recorded in the cluster status.
+	status, _ := recorder.Get(cluster.Namespace, cluster.Name)
+	if len(status.SkippedExtraValues[kindConfigMap]) != 1 {
+		t.Fatalf("expected 1 skipped configmap source got %#v", status.SkippedExtraValues)
+	}
+	if len(eventRecorder.Events) != 1 {
+		t.Fatalf("expected 1 event got %d", len(eventRecorder.Events))
+	}
+}
+
+func Test_ExtraValues_TopologyVariables(t *testing.T) {
+	variables := []capiv1beta2.ClusterVariable{
+		{Name: "baseDomain", Value: apiextensionsv1.JSON{Raw: []byte(`"example.com"`)}},
+		{Name: "network", Value: apiextensionsv1.JSON{Raw: []byte(`{"vpcMode":"private"}`)}},
+		{Name: "sshKey", Value: apiextensionsv1.JSON{Raw: []byte(`"secret"`)}},
+	}
+
+	v1beta1Variables := make([]capi.ClusterVariable, 0, len(variables))
+	for _, v := range variables {
+		v1beta1Variables = append(v1beta1Variables, capi.ClusterVariable{Name: v.Name, Value: v.Value})
+	}
+
+	generated := map[string]interface{}{
+		"baseDomain": "generated.example.com",
+	}
+
+	testCases := []struct {
+		name            string
+		cluster         key.Cluster
+		objects         []runtime.Object
+		expectedValues  map[string]interface{}
+		expectedSources string
+	}{
+		{
+			name: "case 0: allowlisted variables of v1beta2 cluster set at their path",
+			cluster: &capiv1beta2.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "eggs2", Namespace: "org-test", Labels: map[string]string{label.Cluster: "eggs2"}},
+				Spec:       capiv1beta2.ClusterSpec{Topology: capiv1beta2.Topology{Variables: variables}},
+			},
+			expectedValues: map[string]interface{}{
+				"baseDomain": "example.com",
+				"network": map[string]interface{}{
+					"vpcMode": "private",
+				},
+			},
+			expectedSources: "topologyvariable/org-test/baseDomain,topologyvariable/org-test/network",
+		},
+		{
+			name: "case 1: allowlisted variables of v1beta1 cluster set at their path",
+			cluster: &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "eggs2", Namespace: "org-test", Labels: map[string]string{label.Cluster: "eggs2"}},
+				Spec:       capi.ClusterSpec{Topology: &capi.Topology{Variables: v1beta1Variables}},
+			},
+			expectedValues: map[string]interface{}{
+				"baseDomain": "example.com",
+				"network": map[string]interface{}{
+					"vpcMode": "private",
+				},
+			},
+			expectedSources: "topologyvariable/org-test/baseDomain,topologyvariable/org-test/network",
+		},
+		{
+			name: "case 2: variables override selected ConfigMap",
+			cluster: &capiv1beta2.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "eggs2", Namespace: "org-test", Labels: map[string]string{label.Cluster: "eggs2"}},
+				Spec:       capiv1beta2.ClusterSpec{Topology: capiv1beta2.Topology{Variables: variables[:2]}},
+			},
+			objects: []runtime.Object{
+				newConfigMap("org-test", "selected", map[string]string{label.Cluster: "eggs2", LabelExtraValues: "true"}, "network:\n  vpcMode: public\n  region: eu-west-1\n"),
+			},
+			expectedValues: map[string]interface{}{
+				"baseDomain": "example.com",
+				"network": map[string]interface{}{
+					"region":  "eu-west-1",
+					"vpcMode": "private",
+				},
+			},
+			expectedSources: "configmap/org-test/selected,topologyvariable/org-test/baseDomain,topologyvariable/org-test/network",
+		},
+		{
+			name: "case 3: cluster without topology",
+			cluster: &capi.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "eggs2", Namespace: "org-test", Labels: map[string]string{label.Cluster: "eggs2"}},
+			},
+			expectedValues: generated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{
+				CtrlClient: clientfake.NewClientBuilder().WithRuntimeObjects(tc.objects...).Build(),
+				Logger:     microloggertest.New(),
+
+				TopologyVariablesConfigMap: []string{"baseDomain", "network", "missing"},
+				TopologyVariablesSecret:    []string{"sshKey"},
+			}
+
+			e, err := New(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			values, err := e.ConfigMapValues(context.Background(), tc.cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Topology variables take precedence over the generated values.
+			rendered := values.Render(generated)
+			if !reflect.DeepEqual(rendered, tc.expectedValues) {
+				t.Fatalf("expected values %#v got %#v", tc.expectedValues, rendered)
+			}
+			if SourcesAnnotation(values.Sources) != tc.expectedSources {
+				t.Fatalf("expected sources %#q got %#q", tc.expectedSources, SourcesAnnotation(values.Sources))
+			}
+		})
+	}
+}
+
+func Test_New_TopologyVariables(t *testing.T) {
+	testCases := []struct {
+		name        string
+		entries     []string
+		expectedErr bool
+	}{
+		{
+			name:    "case 0: name with path",
+			entries: []string{"baseDomain=global.connectivity.baseDomain"},
+		},
+		{
+			name:    "case 1: name only",
+			entries: []string{"baseDomain", ""},
+		},
+		{
+			name:        "case 2: missing name",
+			entries:     []string{"=global.baseDomain"},
+			expectedErr: true,
+		},
+		{
+			name:        "case 3: empty path segment",
+			entries:     []string{"baseDomain=global..baseDomain"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{
+				CtrlClient: clientfake.NewClientBuilder().Build(),
+				Logger:     microloggertest.New(),
+
+				TopologyVariablesSecret: tc.entries,
+			}
+
+			_, err := New(c)
+			if tc.expectedErr && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error, got %v", err)
+			}
+			if !tc.expectedErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func Test_ExtraValues_TopologyVariablesSchema(t *testing.T) {
+	type testValues struct {
+		BaseDomain string `json:"baseDomain"`
+		Cluster    struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"cluster"`
+	}
+
+	testCases := []struct {
+		name           string
+		entries        []string
+		value          string
+		expectedValues map[string]interface{}
+		expectedSkips  int
+	}{
+		{
+			name:    "case 0: declared key",
+			entries: []string{"domain=baseDomain"},
+			value:   `"example.com"`,
+			expectedValues: map[string]interface{}{
+				"baseDomain": "example.com",
+			},
+		},
+		{
+			name:    "case 1: map key",
+			entries: []string{"domain=cluster.labels.team"},
+			value:   `"example.com"`,
+			expectedValues: map[string]interface{}{
+				"cluster": map[string]interface{}{
+					"labels": map[string]interface{}{
+						"team": "example.com",
+					},
+				},
+			},
+		},
+		{
+			name:    "case 2: undeclared key is not validated",
+			entries: []string{"domain=global.connectivity.baseDomain"},
+			value:   `42`,
+			expectedValues: map[string]interface{}{
+				"global": map[string]interface{}{
+					"connectivity": map[string]interface{}{
+						"baseDomain": float64(42),
+					},
+				},
+			},
+		},
+		{
+			name:           "case 3: value not matching the schema is skipped",
+			entries:        []string{"domain=baseDomain"},
+			value:          `42`,
+			expectedValues: map[string]interface{}{},
+			expectedSkips:  1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &capiv1beta2.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "eggs2", Namespace: "org-test", Labels: map[string]string{label.Cluster: "eggs2"}},
+				Spec: capiv1beta2.ClusterSpec{Topology: capiv1beta2.Topology{Variables: []capiv1beta2.ClusterVariable{
+					{Name: "domain", Value: apiextensionsv1.JSON{Raw: []byte(tc.value)}},
+				}}},
+			}
+
+			recorder := clusterstatus.New()
+			recorder.Update(cluster, func(s *clusterstatus.ClusterStatus) {})
+
+			c := Config{
+				CtrlClient: clientfake.NewClientBuilder().Build(),
+				Logger:     microloggertest.New(),
+				Recorder:   recorder,
+
+				ConfigMapSchema:            jsonschema.Generate(testValues{}),
+				TopologyVariablesConfigMap: tc.entries,
+			}
+
+			e, err := New(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			values, err := e.ConfigMapValues(context.Background(), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(values.Topology, tc.expectedValues) {
+				t.Fatalf("expected values %#v got %#v", tc.expectedValues, values.Topology)
+			}
+
+			status, _ := recorder.Get(cluster.Namespace, cluster.Name)
+			if len(status.SkippedExtraValues[kindConfigMap]) != tc.expectedSkips {
+				t.Fatalf("expected %d skipped sources got %#v", tc.expectedSkips, status.SkippedExtraValues)
+			}
+		})
+	}
+}
+
+func Test_Merge(t *testing.T) {
+	dst := map[string]interface{}{
+		"a": "dst",
+		"b": map[string]interface{}{"c": "dst", "d": "dst"},
+		"e": []interface{}{"dst"},
+	}
+	src := map[string]interface{}{
+		"b": map[string]interface{}{"c": "src"},
+		"e": []interface{}{"src"},
+		"f": "src",
+	}
+
+	expected := map[string]interface{}{
+		"a": "dst",
+		"b": map[string]interface{}{"c": "src", "d": "dst"},
+		"e": []interface{}{"src"},
+		"f": "src",
+	}
+
+	merged := Merge(dst, src)
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("expected %#v got %#v", expected, merged)
+	}
+	if dst["b"].(map[string]interface{})["c"] != "dst" {
+		t.Fatalf("expected dst not to be modified")
+	}
+}
+
+func newConfigMap(namespace, name string, labels map[string]string, values string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Data: map[string]string{
+			ValuesKey: values,
+		},
+	}
+}
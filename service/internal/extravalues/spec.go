@@ -0,0 +1,31 @@
+package extravalues
+
+const (
+	// AnnotationConfigMaps is the Cluster annotation listing comma separated
+	// names of ConfigMaps in the cluster namespace whose values are merged
+	// into the cluster values ConfigMap.
+	AnnotationConfigMaps = "cluster-apps-operator.giantswarm.io/extra-values-configmaps"
+	// AnnotationSecrets is the Cluster annotation listing comma separated
+	// names of Secrets in the cluster namespace whose values are merged into
+	// the cluster values Secret.
+	AnnotationSecrets = "cluster-apps-operator.giantswarm.io/extra-values-secrets"
+	// AnnotationSources is set on the generated ConfigMap and Secret and
+	// lists the sources which contributed values, lowest precedence first.
+	AnnotationSources = "cluster-apps-operator.giantswarm.io/values-sources"
+	// LabelExtraValues selects ConfigMaps and Secrets in the cluster
+	// namespace, which are also labelled with the cluster ID, as extra values
+	// of that cluster when set to "true".
+	LabelExtraValues = "cluster-apps-operator.giantswarm.io/extra-values"
+
+	// InvalidExtraValuesReason is the reason of the warning event emitted for
+	// a Cluster when one of its extra values sources is skipped.
+	InvalidExtraValuesReason = "InvalidExtraValues"
+
+	// ValuesKey is the data key holding the YAML values of extra values
+	// ConfigMaps and Secrets.
+	ValuesKey = "values"
+
+	kindConfigMap        = "configmap"
+	kindSecret           = "secret"
+	kindTopologyVariable = "topologyvariable"
+)
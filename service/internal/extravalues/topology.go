@@ -0,0 +1,127 @@
+package extravalues
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/jsonschema"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+// topologyVariable selects a ClusterClass topology variable whose value is
+// set at path in the cluster values.
+type topologyVariable struct {
+	Name string
+	Path []string
+}
+
+// parseTopologyVariables parses allowlist entries of the form name=path,
+// where path is dotted and defaults to the name of the variable.
+func parseTopologyVariables(entries []string) ([]topologyVariable, error) {
+	var variables []topologyVariable
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, path, found := strings.Cut(entry, "=")
+		if !found {
+			path = name
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("entry %#q must have a variable name", entry)
+		}
+
+		segments := strings.Split(strings.TrimSpace(path), ".")
+		for _, s := range segments {
+			if s == "" {
+				return nil, fmt.Errorf("entry %#q must have a dotted path without empty segments", entry)
+			}
+		}
+
+		variables = append(variables, topologyVariable{Name: name, Path: segments})
+	}
+
+	return variables, nil
+}
+
+// topologyValues returns the values of the allowlisted topology variables of
+// the cluster and a source for every variable which is set. Variables which
+// do not hold JSON, or whose value does not match the given schema at a path
+// it declares, are skipped and returned as invalidValuesError. The schema is
+// optional.
+func topologyValues(cr key.Cluster, variables []topologyVariable, schema *jsonschema.Schema) (map[string]interface{}, []Source, []error) {
+	if len(variables) == 0 {
+		return nil, nil, nil
+	}
+
+	raw := key.TopologyVariables(cr)
+
+	values := map[string]interface{}{}
+	var sources []Source
+	var invalid []error
+
+	for _, variable := range variables {
+		data, ok := raw[variable.Name]
+		if !ok || len(data) == 0 {
+			continue
+		}
+
+		source := Source{Kind: kindTopologyVariable, Namespace: cr.GetNamespace(), Name: variable.Name}
+
+		var v interface{}
+		err := json.Unmarshal(data, &v)
+		if err != nil {
+			invalid = append(invalid, microerror.Maskf(invalidValuesError, "%s must contain JSON: %s", source, err))
+			continue
+		}
+
+		err = validateTopologyValue(schema, variable.Path, v)
+		if err != nil {
+			invalid = append(invalid, microerror.Maskf(invalidValuesError, "%s must match the cluster values schema at %#q: %s", source, strings.Join(variable.Path, "."), err))
+			continue
+		}
+
+		values = Merge(values, nest(variable.Path, v))
+		sources = append(sources, source)
+	}
+
+	return values, sources, invalid
+}
+
+// validateTopologyValue validates v against the schema of the given path.
+// Paths the schema does not declare are not validated, like the values of
+// other extra values sources.
+func validateTopologyValue(schema *jsonschema.Schema, path []string, v interface{}) error {
+	if schema == nil {
+		return nil
+	}
+
+	s := schema.Lookup(path)
+	if s == nil {
+		return nil
+	}
+
+	err := s.Validate(v)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// nest returns an object holding v at the given path.
+func nest(path []string, v interface{}) map[string]interface{} {
+	for i := len(path) - 1; i > 0; i-- {
+		v = map[string]interface{}{path[i]: v}
+	}
+
+	return map[string]interface{}{path[0]: v}
+}
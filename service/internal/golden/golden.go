@@ -0,0 +1,239 @@
+// Package golden provides a golden file harness for the values, Secrets and
+// App CRs rendered by the cluster resources. Every case is a directory with
+// an input.yaml holding the Cluster and the objects it references. The
+// rendered objects are compared against the golden YAML files next to it.
+// Run the tests with UPDATE_GOLDEN=1 to regenerate the golden files after an
+// intended change, so it shows up as a reviewable diff. It is an environment
+// variable rather than a flag so that go test ./... accepts it for packages
+// without golden tests too.
+package golden
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+const (
+	inputFile = "input.yaml"
+
+	// UpdateEnv is the environment variable which makes Assert write the
+	// golden files instead of comparing against them.
+	UpdateEnv = "UPDATE_GOLDEN"
+)
+
+// Case is a single golden file case.
+type Case struct {
+	// Name is the name of the case directory.
+	Name string
+	// Dir is the path of the case directory.
+	Dir string
+	// Cluster is the Cluster of the input objects.
+	Cluster key.Cluster
+	// Objects are all input objects, including the Cluster. Kinds known to
+	// the Scheme are decoded into their types, all others are unstructured.
+	Objects []runtime.Object
+}
+
+// Path returns the path of the given golden file of the case.
+func (c Case) Path(name string) string {
+	return filepath.Join(c.Dir, name)
+}
+
+// NewScheme returns a scheme with the types the input objects are decoded
+// into. Pass it to the fake client serving them.
+func NewScheme(t testing.TB) *runtime.Scheme {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+
+	schemeBuilder := runtime.SchemeBuilder{
+		clientgoscheme.AddToScheme,
+		appv1alpha1.AddToScheme,
+		capiv1beta1.AddToScheme,
+		capi.AddToScheme,
+	}
+
+	err := schemeBuilder.AddToScheme(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return scheme
+}
+
+// Cases returns the cases in the subdirectories of dir, ordered by name.
+func Cases(t testing.TB, dir string, scheme *runtime.Scheme) []Case {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cases []Case
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		c := Case{
+			Name: e.Name(),
+			Dir:  filepath.Join(dir, e.Name()),
+		}
+
+		c.Objects = decode(t, c.Path(inputFile), scheme)
+
+		for _, obj := range c.Objects {
+			cluster, err := key.ToCluster(obj)
+			if err == nil {
+				c.Cluster = cluster
+				break
+			}
+		}
+		if c.Cluster == nil {
+			t.Fatalf("case %#q has no Cluster in %s", c.Name, inputFile)
+		}
+
+		cases = append(cases, c)
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+
+	return cases
+}
+
+// Assert compares the YAML documents of the given objects against the golden
+// file at path, or writes them to it when UpdateEnv is set.
+func Assert(t testing.TB, path string, objects ...interface{}) {
+	t.Helper()
+
+	var docs [][]byte
+	for _, obj := range objects {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, b)
+	}
+	actual := bytes.Join(docs, []byte("---\n"))
+
+	if os.Getenv(UpdateEnv) != "" {
+		// #nosec G306 -- golden files are checked in test data.
+		err := os.WriteFile(path, actual, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	// #nosec G304 -- the path is built by the tests from their test data.
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist, run the tests with "+UpdateEnv+"=1 to create it", path)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("rendered objects differ from golden file %s, run the tests with "+UpdateEnv+"=1 to regenerate it\n\n%s", path, diff(string(expected), string(actual)))
+	}
+}
+
+func decode(t testing.TB, path string, scheme *runtime.Scheme) []runtime.Object {
+	t.Helper()
+
+	// #nosec G304 -- the path is built by the tests from their test data.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var objects []runtime.Object
+	for _, doc := range strings.Split(string(data), "\n---\n") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+
+		u := &unstructured.Unstructured{}
+		err = yaml.Unmarshal([]byte(doc), &u.Object)
+		if err != nil {
+			t.Fatalf("decoding %s: %v", path, err)
+		}
+
+		if !scheme.Recognizes(u.GroupVersionKind()) {
+			objects = append(objects, u)
+			continue
+		}
+
+		obj, err := scheme.New(u.GroupVersionKind())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+		if err != nil {
+			t.Fatalf("decoding %s %#q in %s: %v", u.GetKind(), u.GetName(), path, err)
+		}
+
+		// Fixtures use stringData for readability. Merge it into data as
+		// the API server does, since fake clients do not.
+		if secret, ok := obj.(*corev1.Secret); ok {
+			for k, v := range secret.StringData {
+				if secret.Data == nil {
+					secret.Data = map[string][]byte{}
+				}
+				secret.Data[k] = []byte(v)
+			}
+			secret.StringData = nil
+		}
+
+		objects = append(objects, obj)
+	}
+
+	return objects
+}
+
+// diff returns the lines of expected and actual which differ, prefixed with
+// - and + respectively.
+func diff(expected, actual string) string {
+	e := strings.Split(expected, "\n")
+	a := strings.Split(actual, "\n")
+
+	var lines []string
+	for i := 0; i < len(e) || i < len(a); i++ {
+		var el, al string
+		if i < len(e) {
+			el = e[i]
+		}
+		if i < len(a) {
+			al = a[i]
+		}
+
+		if el == al {
+			continue
+		}
+		if i < len(e) {
+			lines = append(lines, "- "+el)
+		}
+		if i < len(a) {
+			lines = append(lines, "+ "+al)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,30 @@
+package provider
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var kindNotServedError = &microerror.Error{
+	Kind: "kindNotServedError",
+}
+
+// IsKindNotServed asserts kindNotServedError.
+func IsKindNotServed(err error) bool {
+	return microerror.Cause(err) == kindNotServedError
+}
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
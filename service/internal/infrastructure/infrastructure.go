@@ -1,6 +1,9 @@
 package provider
 
 const (
+	// Group is the API group of all CAPI infrastructure providers.
+	Group = "infrastructure.cluster.x-k8s.io"
+
 	AWSClusterKind         = "AWSCluster"
 	AWSClusterKindProvider = "capa"
 
@@ -28,3 +31,17 @@ const (
 	ProxmoxClusterKind         = "ProxmoxCluster"
 	ProxmoxClusterKindProvider = "proxmox"
 )
+
+// ClusterKinds are the infrastructure cluster kinds the operator knows about.
+var ClusterKinds = []string{
+	AWSClusterKind,
+	AWSManagedClusterKind,
+	AzureClusterKind,
+	AzureManagedClusterKind,
+	AzureASOManagedClusterKind,
+	VCDClusterKind,
+	VSphereClusterKind,
+	GCPClusterKind,
+	GCPManagedClusterKind,
+	ProxmoxClusterKind,
+}
@@ -0,0 +1,60 @@
+// Package infrastructuretest provides helpers to test code using the
+// infrastructure Resolver with fake clients.
+package infrastructuretest
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+)
+
+// NewResolver returns a Resolver using the given client and fails the test
+// when it cannot be created.
+func NewResolver(t testing.TB, ctrlClient client.Client) *infra.Resolver {
+	t.Helper()
+
+	c := infra.ResolverConfig{
+		CtrlClient: ctrlClient,
+	}
+
+	resolver, err := infra.NewResolver(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resolver
+}
+
+// NewRESTMapper returns a RESTMapper serving the kinds of the given objects in
+// the version they are set to. Objects without namespace are mapped as
+// cluster scoped. Pass it to the fake client builder so the Resolver can find
+// the preferred version of referenced kinds.
+func NewRESTMapper(objects ...runtime.Object) meta.RESTMapper {
+	var groupVersions []schema.GroupVersion
+	for _, obj := range objects {
+		groupVersions = append(groupVersions, obj.GetObjectKind().GroupVersionKind().GroupVersion())
+	}
+
+	mapper := meta.NewDefaultRESTMapper(groupVersions)
+
+	for _, obj := range objects {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		if gvk.Empty() {
+			continue
+		}
+
+		scope := meta.RESTScopeNamespace
+		if o, err := meta.Accessor(obj); err == nil && o.GetNamespace() == "" {
+			scope = meta.RESTScopeRoot
+		}
+
+		mapper.Add(gvk, scope)
+	}
+
+	return mapper
+}
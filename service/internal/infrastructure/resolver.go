@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResolverConfig represents the configuration used to create a new Resolver.
+type ResolverConfig struct {
+	CtrlClient client.Client
+}
+
+// Resolver fetches the objects referenced by Clusters, like infrastructure
+// clusters, control planes and provider identities. Objects are fetched in
+// the preferred version the API server serves for their kind, so references
+// without a version work as well. Fetched objects are cached in the context
+// returned by NewContext, so every reconciliation fetches them at most once and
+// drops them together with its context, whether it succeeds or not.
+type Resolver struct {
+	ctrlClient client.Client
+}
+
+type cacheContextKey struct{}
+
+type cache struct {
+	mutex   sync.Mutex
+	objects map[string]*unstructured.Unstructured
+}
+
+func NewResolver(config ResolverConfig) (*Resolver, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+
+	r := &Resolver{
+		ctrlClient: config.CtrlClient,
+	}
+
+	return r, nil
+}
+
+// NewContext returns a copy of ctx carrying an empty cache for the objects
+// fetched by the Resolver. It is called once per reconciliation. Objects
+// fetched with a context without cache are not cached.
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheContextKey{}, &cache{objects: map[string]*unstructured.Unstructured{}})
+}
+
+// Get returns the object of the given kind referenced by the given Cluster.
+// It is fetched in the version of gvk, e.g. the one pinned by a v1beta1
+// reference, or in the preferred version when gvk has none. Cluster scoped
+// objects are fetched with an empty namespace. A notFoundError is returned
+// when the object does not exist and a kindNotServedError when its kind is
+// not served in the requested version.
+func (r *Resolver) Get(ctx context.Context, cluster client.Object, gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	key := fmt.Sprintf("%s/%s/%s", objectKey(cluster.GetNamespace(), cluster.GetName()), gvk.String(), objectKey(namespace, name))
+
+	c, _ := ctx.Value(cacheContextKey{}).(*cache)
+	if obj := c.get(key); obj != nil {
+		return obj, nil
+	}
+
+	var versions []string
+	if gvk.Version != "" {
+		versions = append(versions, gvk.Version)
+	}
+
+	mapping, err := r.ctrlClient.RESTMapper().RESTMapping(gvk.GroupKind(), versions...)
+	if meta.IsNoMatchError(err) {
+		return nil, microerror.Maskf(kindNotServedError, "%s", gvk.String())
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(mapping.GroupVersionKind)
+
+	err = r.ctrlClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj)
+	if apierrors.IsNotFound(err) {
+		return nil, microerror.Maskf(notFoundError, "%s %#q", gvk.GroupKind().String(), objectKey(namespace, name))
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c.set(key, obj)
+
+	return obj.DeepCopy(), nil
+}
+
+func (c *cache) get(key string) *unstructured.Unstructured {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	obj, ok := c.objects[key]
+	if !ok {
+		return nil
+	}
+
+	return obj.DeepCopy()
+}
+
+func (c *cache) set(key string, obj *unstructured.Unstructured) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.objects[key] = obj
+}
+
+func objectKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
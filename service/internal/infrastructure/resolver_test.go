@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func Test_Resolver_Get(t *testing.T) {
+	awsCluster := &unstructured.Unstructured{}
+	awsCluster.SetGroupVersionKind(schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: AWSClusterKind})
+	awsCluster.SetNamespace("org-test")
+	awsCluster.SetName("eggs2")
+
+	// The older version is still served, e.g. by a v1beta1 reference
+	// pinning it.
+	oldAWSCluster := &unstructured.Unstructured{}
+	oldAWSCluster.SetGroupVersionKind(schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: AWSClusterKind})
+	oldAWSCluster.SetNamespace("org-test")
+	oldAWSCluster.SetName("eggs3")
+
+	testCases := []struct {
+		name            string
+		gvk             schema.GroupVersionKind
+		objectName      string
+		expectedVersion string
+		expectedError   error
+	}{
+		{
+			name:            "case 0: object fetched in the preferred version",
+			gvk:             schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Kind: AWSClusterKind},
+			objectName:      "eggs2",
+			expectedVersion: "infrastructure.cluster.x-k8s.io/v1beta2",
+		},
+		{
+			name:          "case 1: missing object",
+			gvk:           schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Kind: AWSClusterKind},
+			objectName:    "missing",
+			expectedError: notFoundError,
+		},
+		{
+			name:          "case 2: kind not served",
+			gvk:           schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Kind: AzureClusterKind},
+			objectName:    "eggs2",
+			expectedError: kindNotServedError,
+		},
+		{
+			name:            "case 3: object fetched in the pinned version",
+			gvk:             schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: AWSClusterKind},
+			objectName:      "eggs3",
+			expectedVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+		},
+		{
+			name:          "case 4: pinned version not served",
+			gvk:           schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1alpha4", Kind: AWSClusterKind},
+			objectName:    "eggs2",
+			expectedError: kindNotServedError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := newTestResolver(t, nil, awsCluster, oldAWSCluster)
+
+			obj, err := resolver.Get(context.Background(), newTestCluster(), tc.gvk, "org-test", tc.objectName)
+			if microerror.Cause(err) != tc.expectedError {
+				t.Fatalf("expected error %#v, got %#v", tc.expectedError, err)
+			}
+
+			if tc.expectedError == nil && obj.GetAPIVersion() != tc.expectedVersion {
+				t.Fatalf("expected %s, got %s", tc.expectedVersion, obj.GetAPIVersion())
+			}
+		})
+	}
+}
+
+func Test_Resolver_Cache(t *testing.T) {
+	awsCluster := &unstructured.Unstructured{}
+	awsCluster.SetGroupVersionKind(schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: AWSClusterKind})
+	awsCluster.SetNamespace("org-test")
+	awsCluster.SetName("eggs2")
+
+	var gets int
+	resolver := newTestResolver(t, &gets, awsCluster)
+
+	cluster := newTestCluster()
+	gvk := schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Kind: AWSClusterKind}
+
+	ctx := NewContext(context.Background())
+
+	get := func(ctx context.Context) {
+		obj, err := resolver.Get(ctx, cluster, gvk, "org-test", "eggs2")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Callers must not be able to modify the cached object.
+		obj.SetLabels(map[string]string{"modified": "true"})
+	}
+
+	get(ctx)
+	get(ctx)
+	if gets != 1 {
+		t.Fatalf("expected 1 get within a reconciliation, got %d", gets)
+	}
+
+	obj, err := resolver.Get(ctx, cluster, gvk, "org-test", "eggs2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(obj.GetLabels()) != 0 {
+		t.Fatalf("expected cached object to be unmodified, got labels %v", obj.GetLabels())
+	}
+
+	// The next reconciliation starts with an empty cache.
+	get(NewContext(context.Background()))
+	if gets != 2 {
+		t.Fatalf("expected 2 gets after a new reconciliation, got %d", gets)
+	}
+
+	// Without cache every call fetches the object.
+	get(context.Background())
+	get(context.Background())
+	if gets != 4 {
+		t.Fatalf("expected 4 gets without cache, got %d", gets)
+	}
+}
+
+func newTestCluster() *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "eggs2",
+			Namespace: "org-test",
+		},
+	}
+}
+
+// newTestResolver returns a Resolver serving the kinds of the given objects.
+// When gets is not nil it is incremented for every object fetched from the
+// API server.
+func newTestResolver(t *testing.T, gets *int, objects ...*unstructured.Unstructured) *Resolver {
+	var groupVersions []schema.GroupVersion
+	for _, obj := range objects {
+		groupVersions = append(groupVersions, obj.GroupVersionKind().GroupVersion())
+	}
+
+	mapper := meta.NewDefaultRESTMapper(groupVersions)
+	builder := clientfake.NewClientBuilder().WithRESTMapper(mapper)
+	for _, obj := range objects {
+		mapper.Add(obj.GroupVersionKind(), meta.RESTScopeNamespace)
+		builder = builder.WithObjects(obj)
+	}
+
+	if gets != nil {
+		builder = builder.WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				*gets++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		})
+	}
+
+	resolver, err := NewResolver(ResolverConfig{CtrlClient: builder.Build()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resolver
+}
@@ -0,0 +1,144 @@
+// Package leaderelection implements Lease based leader election so that only
+// one of several operator replicas reconciles clusters at a time.
+package leaderelection
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+type Config struct {
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// Identity identifies this replica in the Lease. It defaults to the
+	// hostname, which is the pod name.
+	Identity       string
+	LeaseDuration  time.Duration
+	LeaseName      string
+	LeaseNamespace string
+	RenewDeadline  time.Duration
+	RetryPeriod    time.Duration
+
+	// OnStartedLeading is executed in its own goroutine once this replica
+	// acquired the Lease.
+	OnStartedLeading func(ctx context.Context)
+	// OnStoppedLeading is executed when this replica lost the Lease while ctx
+	// given to Run was not canceled.
+	OnStoppedLeading func()
+}
+
+type Elector struct {
+	logger  micrologger.Logger
+	elector *leaderelection.LeaderElector
+
+	// done is closed once the context given to Run is canceled, so that
+	// releasing the Lease on shutdown is not treated as losing it.
+	done <-chan struct{}
+}
+
+func New(config Config) (*Elector, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.LeaseName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LeaseName must not be empty", config)
+	}
+	if config.OnStartedLeading == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.OnStartedLeading must not be empty", config)
+	}
+	if config.OnStoppedLeading == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.OnStoppedLeading must not be empty", config)
+	}
+
+	if config.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		config.Identity = hostname
+	}
+
+	if config.LeaseNamespace == "" {
+		namespace, err := os.ReadFile(serviceAccountNamespaceFile)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.LeaseNamespace must not be empty when not running in a pod: %s", config, err.Error())
+		}
+		config.LeaseNamespace = strings.TrimSpace(string(namespace))
+	}
+
+	e := &Elector{
+		logger: config.Logger,
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      config.LeaseName,
+			Namespace: config.LeaseNamespace,
+		},
+		Client: config.K8sClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: config.Identity,
+		},
+	}
+
+	var err error
+	e.elector, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   config.LeaseDuration,
+		RenewDeadline:   config.RenewDeadline,
+		RetryPeriod:     config.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            config.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				e.logger.Debugf(ctx, "acquired lease '%s/%s' as %#q", config.LeaseNamespace, config.LeaseName, config.Identity)
+				config.OnStartedLeading(ctx)
+			},
+			OnStoppedLeading: func() {
+				select {
+				case <-e.done:
+					e.logger.Debugf(context.Background(), "released lease '%s/%s'", config.LeaseNamespace, config.LeaseName)
+				default:
+					config.OnStoppedLeading()
+				}
+			},
+			OnNewLeader: func(identity string) {
+				e.logger.Debugf(context.Background(), "lease '%s/%s' is held by %#q", config.LeaseNamespace, config.LeaseName, identity)
+			},
+		},
+	})
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%s", err.Error())
+	}
+
+	return e, nil
+}
+
+// IsLeader returns whether this replica currently holds the Lease.
+func (e *Elector) IsLeader() bool {
+	return e.elector.IsLeader()
+}
+
+// Run blocks trying to acquire and then renewing the Lease until ctx is
+// canceled or the Lease is lost.
+func (e *Elector) Run(ctx context.Context) {
+	e.done = ctx.Done()
+	e.elector.Run(ctx)
+}
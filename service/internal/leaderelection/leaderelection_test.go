@@ -0,0 +1,126 @@
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_Elector_Run(t *testing.T) {
+	testCases := []struct {
+		name string
+		// failRenew makes Lease updates fail once this replica leads, so the
+		// Lease is lost.
+		failRenew              bool
+		expectedStoppedLeading bool
+	}{
+		{
+			name:                   "case 0: releasing the lease on cancel is not losing it",
+			expectedStoppedLeading: false,
+		},
+		{
+			name:                   "case 1: failing to renew the lease loses it",
+			failRenew:              true,
+			expectedStoppedLeading: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			k8sClient := fake.NewClientset()
+
+			started := make(chan struct{})
+			stopped := make(chan struct{})
+
+			e, err := New(Config{
+				K8sClient: k8sClient,
+				Logger:    microloggertest.New(),
+
+				Identity:       "replica-a",
+				LeaseDuration:  2 * time.Second,
+				LeaseName:      "test",
+				LeaseNamespace: "giantswarm",
+				RenewDeadline:  time.Second,
+				RetryPeriod:    100 * time.Millisecond,
+
+				OnStartedLeading: func(ctx context.Context) {
+					close(started)
+				},
+				OnStoppedLeading: func() {
+					close(stopped)
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			returned := make(chan struct{})
+			go func() {
+				e.Run(ctx)
+				close(returned)
+			}()
+
+			select {
+			case <-started:
+			case <-time.After(10 * time.Second):
+				t.Fatalf("expected OnStartedLeading to be executed")
+			}
+			if !e.IsLeader() {
+				t.Fatalf("expected replica to be leader")
+			}
+
+			if tc.failRenew {
+				k8sClient.PrependReactor("update", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewServiceUnavailable("test")
+				})
+			} else {
+				cancel()
+			}
+
+			select {
+			case <-returned:
+			case <-time.After(10 * time.Second):
+				t.Fatalf("expected Run to return")
+			}
+
+			select {
+			case <-stopped:
+				if !tc.expectedStoppedLeading {
+					t.Fatalf("expected OnStoppedLeading not to be executed")
+				}
+			default:
+				if tc.expectedStoppedLeading {
+					t.Fatalf("expected OnStoppedLeading to be executed")
+				}
+			}
+
+			if tc.failRenew {
+				return
+			}
+
+			select {
+			case <-e.done:
+			default:
+				t.Fatalf("expected done to be closed")
+			}
+
+			lease, err := k8sClient.CoordinationV1().Leases("giantswarm").Get(context.Background(), "test", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != "" {
+				t.Fatalf("expected lease to be released, got holder %#q", *lease.Spec.HolderIdentity)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package orphansweeper
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	PrometheusNamespace = "cluster_apps_operator"
+	PrometheusSubsystem = "orphan_sweeper"
+)
+
+var (
+	orphansGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "orphans",
+			Help:      "Generated objects whose Cluster no longer exists, found by the last sweep.",
+		},
+		[]string{"kind"},
+	)
+	deletedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "deleted_total",
+			Help:      "Number of orphaned generated objects deleted.",
+		},
+		[]string{"kind"},
+	)
+	errorsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "errors_total",
+			Help:      "Number of sweeps which failed.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(orphansGauge)
+	prometheus.MustRegister(deletedCounter)
+	prometheus.MustRegister(errorsCounter)
+}
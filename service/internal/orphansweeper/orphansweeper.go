@@ -0,0 +1,228 @@
+// Package orphansweeper deletes the objects generated for a cluster once the
+// cluster no longer exists. They are normally deleted by the delete path of
+// the controller and garbage collected through their owner reference to the
+// Cluster. The sweeper covers objects which missed both, e.g. because the
+// finalizer of the Cluster was removed manually before their owner reference
+// was added.
+package orphansweeper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/sharding"
+)
+
+// kinds are the kinds of the objects generated for a cluster.
+var kinds = []schema.GroupVersionKind{
+	corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+	corev1.SchemeGroupVersion.WithKind("Secret"),
+	v1alpha1.SchemeGroupVersion.WithKind("App"),
+}
+
+type Config struct {
+	CtrlClient client.Client
+	Logger     micrologger.Logger
+	// Sharding is optional. When set only the primary replica sweeps, so
+	// orphans are not deleted by all replicas at the same time.
+	Sharding sharding.Interface
+
+	// ClusterGroupVersion is the CAPI version Clusters are listed in.
+	ClusterGroupVersion schema.GroupVersion
+	// DryRun only logs orphaned objects instead of deleting them.
+	DryRun   bool
+	Interval time.Duration
+}
+
+type Sweeper struct {
+	ctrlClient client.Client
+	logger     micrologger.Logger
+	sharding   sharding.Interface
+
+	clusterGroupVersion schema.GroupVersion
+	dryRun              bool
+	interval            time.Duration
+}
+
+func New(config Config) (*Sweeper, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ClusterGroupVersion.Empty() {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClusterGroupVersion must not be empty", config)
+	}
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+
+	s := &Sweeper{
+		ctrlClient: config.CtrlClient,
+		logger:     config.Logger,
+		sharding:   config.Sharding,
+
+		clusterGroupVersion: config.ClusterGroupVersion,
+		dryRun:              config.DryRun,
+		interval:            config.Interval,
+	}
+
+	return s, nil
+}
+
+// Boot sweeps orphaned objects every interval until ctx is canceled.
+func (s *Sweeper) Boot(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.Sweep(ctx)
+		if err != nil {
+			errorsCounter.Inc()
+			s.logger.Errorf(ctx, err, "failed to sweep orphaned objects")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes the generated objects whose Cluster no longer exists.
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	if s.sharding != nil && !s.sharding.IsPrimary() {
+		s.logger.Debugf(ctx, "skipping orphan sweep as this replica is not the primary shard")
+		return nil
+	}
+
+	// The generated objects are listed before the Clusters, so objects of
+	// Clusters created in between are never considered orphaned.
+	objects := map[schema.GroupVersionKind][]metav1.PartialObjectMetadata{}
+	for _, gvk := range kinds {
+		items, err := s.listGenerated(ctx, gvk)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		objects[gvk] = items
+	}
+
+	clusters, err := s.listClusters(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// A failed deletion must not keep the remaining orphans around, so
+	// failures are only counted and reported once all objects are processed.
+	var failed int
+	for _, gvk := range kinds {
+		var orphans int
+		for i := range objects[gvk] {
+			obj := &objects[gvk][i]
+
+			clusterID := obj.GetLabels()[label.Cluster]
+			if clusterID == "" || clusters[clusterKey(obj.GetNamespace(), clusterID)] {
+				continue
+			}
+			orphans++
+
+			if !obj.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+
+			if s.dryRun {
+				s.logger.Debugf(ctx, "would delete orphaned %s '%s/%s' of cluster %#q", gvk.Kind, obj.GetNamespace(), obj.GetName(), clusterID)
+				continue
+			}
+
+			err = s.delete(ctx, gvk, obj)
+			if err != nil {
+				failed++
+				s.logger.Errorf(ctx, err, "failed to delete orphaned %s '%s/%s' of cluster %#q", gvk.Kind, obj.GetNamespace(), obj.GetName(), clusterID)
+				continue
+			}
+			s.logger.Debugf(ctx, "deleted orphaned %s '%s/%s' of cluster %#q", gvk.Kind, obj.GetNamespace(), obj.GetName(), clusterID)
+		}
+
+		orphansGauge.WithLabelValues(gvk.Kind).Set(float64(orphans))
+	}
+
+	if failed > 0 {
+		return microerror.Maskf(executionFailedError, "failed to delete %d orphaned objects", failed)
+	}
+
+	return nil
+}
+
+func (s *Sweeper) listGenerated(ctx context.Context, gvk schema.GroupVersionKind) ([]metav1.PartialObjectMetadata, error) {
+	var list metav1.PartialObjectMetadataList
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	err := s.ctrlClient.List(ctx, &list, client.MatchingLabels{label.ManagedBy: project.Name()})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list.Items, nil
+}
+
+// listClusters returns the namespaced cluster IDs and names of all Clusters.
+// Only the metadata of Clusters is needed, which is the same in all CAPI
+// versions.
+func (s *Sweeper) listClusters(ctx context.Context) (map[string]bool, error) {
+	var list metav1.PartialObjectMetadataList
+	list.SetGroupVersionKind(s.clusterGroupVersion.WithKind("ClusterList"))
+
+	err := s.ctrlClient.List(ctx, &list)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	clusters := map[string]bool{}
+	for i := range list.Items {
+		cl := &list.Items[i]
+
+		clusters[clusterKey(cl.GetNamespace(), cl.GetName())] = true
+		if id := key.ClusterID(cl); id != "" {
+			clusters[clusterKey(cl.GetNamespace(), id)] = true
+		}
+	}
+
+	return clusters, nil
+}
+
+// delete deletes obj unless it has been replaced in the meantime.
+func (s *Sweeper) delete(ctx context.Context, gvk schema.GroupVersionKind, obj *metav1.PartialObjectMetadata) error {
+	obj.SetGroupVersionKind(gvk)
+	uid := obj.GetUID()
+
+	err := s.ctrlClient.Delete(ctx, obj, client.Preconditions{UID: &uid})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	deletedCounter.WithLabelValues(gvk.Kind).Inc()
+
+	return nil
+}
+
+func clusterKey(namespace, clusterID string) string {
+	return fmt.Sprintf("%s/%s", namespace, clusterID)
+}
@@ -0,0 +1,251 @@
+package orphansweeper
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/pkg/project"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/sharding"
+)
+
+func Test_Sweep(t *testing.T) {
+	testCases := []struct {
+		name              string
+		dryRun            bool
+		objects           []runtime.Object
+		failDelete        string
+		sharding          sharding.Interface
+		expectedRemaining []string
+		expectedErr       bool
+	}{
+		{
+			name: "case 0: objects of existing cluster are kept",
+			objects: []runtime.Object{
+				newCluster("demo0", "demo0"),
+				newConfigMap("demo0-cluster-values", "demo0", true),
+				newSecret("demo0-cluster-values", "demo0", true),
+				newApp("demo0-app-operator", "demo0", true),
+			},
+			expectedRemaining: []string{
+				"App/demo0-app-operator",
+				"ConfigMap/demo0-cluster-values",
+				"Secret/demo0-cluster-values",
+			},
+		},
+		{
+			name: "case 1: objects of deleted cluster are deleted",
+			objects: []runtime.Object{
+				newCluster("demo0", "demo0"),
+				newConfigMap("demo0-cluster-values", "demo0", true),
+				newConfigMap("demo1-cluster-values", "demo1", true),
+				newSecret("demo1-cluster-values", "demo1", true),
+				newApp("demo1-app-operator", "demo1", true),
+			},
+			expectedRemaining: []string{
+				"ConfigMap/demo0-cluster-values",
+			},
+		},
+		{
+			name:   "case 2: dry run keeps orphaned objects",
+			dryRun: true,
+			objects: []runtime.Object{
+				newConfigMap("demo1-cluster-values", "demo1", true),
+				newApp("demo1-app-operator", "demo1", true),
+			},
+			expectedRemaining: []string{
+				"App/demo1-app-operator",
+				"ConfigMap/demo1-cluster-values",
+			},
+		},
+		{
+			name: "case 3: objects not managed by the operator are kept",
+			objects: []runtime.Object{
+				newConfigMap("demo1-userconfig", "demo1", false),
+				newApp("demo1-cilium", "demo1", false),
+			},
+			expectedRemaining: []string{
+				"App/demo1-cilium",
+				"ConfigMap/demo1-userconfig",
+			},
+		},
+		{
+			name: "case 4: cluster is matched by its cluster ID label",
+			objects: []runtime.Object{
+				newCluster("demo0-cluster", "demo0"),
+				newSecret("demo0-cluster-values", "demo0", true),
+			},
+			expectedRemaining: []string{
+				"Secret/demo0-cluster-values",
+			},
+		},
+		{
+			name: "case 5: failed deletion does not stop the sweep",
+			objects: []runtime.Object{
+				newConfigMap("demo1-cluster-values", "demo1", true),
+				newSecret("demo1-cluster-values", "demo1", true),
+				newApp("demo1-app-operator", "demo1", true),
+			},
+			failDelete: "demo1-cluster-values",
+			expectedRemaining: []string{
+				"ConfigMap/demo1-cluster-values",
+				"Secret/demo1-cluster-values",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "case 6: replicas other than the primary shard do not sweep",
+			objects: []runtime.Object{
+				newConfigMap("demo1-cluster-values", "demo1", true),
+			},
+			sharding: primary(false),
+			expectedRemaining: []string{
+				"ConfigMap/demo1-cluster-values",
+			},
+		},
+		{
+			name: "case 7: primary shard sweeps",
+			objects: []runtime.Object{
+				newConfigMap("demo1-cluster-values", "demo1", true),
+			},
+			sharding: primary(true),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			for _, addToScheme := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, v1alpha1.AddToScheme, capi.AddToScheme} {
+				err := addToScheme(scheme)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctrlClient := clientfake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRuntimeObjects(tc.objects...).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+						if obj.GetName() == tc.failDelete {
+							return errors.New("delete failed")
+						}
+						return c.Delete(ctx, obj, opts...)
+					},
+				}).
+				Build()
+
+			s, err := New(Config{
+				CtrlClient: ctrlClient,
+				Logger:     microloggertest.New(),
+				Sharding:   tc.sharding,
+
+				ClusterGroupVersion: capi.GroupVersion,
+				DryRun:              tc.dryRun,
+				Interval:            time.Minute,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = s.Sweep(context.Background())
+			if tc.expectedErr && !IsExecutionFailed(err) {
+				t.Fatalf("expected execution failed error, got %v", err)
+			} else if !tc.expectedErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			remaining := remainingObjects(t, ctrlClient)
+			if len(remaining) != len(tc.expectedRemaining) {
+				t.Fatalf("expected remaining objects %v, got %v", tc.expectedRemaining, remaining)
+			}
+			for i := range remaining {
+				if remaining[i] != tc.expectedRemaining[i] {
+					t.Fatalf("expected remaining objects %v, got %v", tc.expectedRemaining, remaining)
+				}
+			}
+		})
+	}
+}
+
+func remainingObjects(t *testing.T, ctrlClient client.Client) []string {
+	t.Helper()
+
+	var remaining []string
+	for _, gvk := range kinds {
+		var list metav1.PartialObjectMetadataList
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		err := ctrlClient.List(context.Background(), &list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range list.Items {
+			remaining = append(remaining, gvk.Kind+"/"+item.GetName())
+		}
+	}
+	sort.Strings(remaining)
+
+	return remaining
+}
+
+func newObjectMeta(name, clusterID string, managed bool) metav1.ObjectMeta {
+	labels := map[string]string{label.Cluster: clusterID}
+	if managed {
+		labels[label.ManagedBy] = project.Name()
+	}
+
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: "org-acme",
+		Labels:    labels,
+	}
+}
+
+func newCluster(name, clusterID string) *capi.Cluster {
+	return &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "org-acme",
+			Labels:    map[string]string{label.Cluster: clusterID},
+		},
+	}
+}
+
+func newConfigMap(name, clusterID string, managed bool) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: newObjectMeta(name, clusterID, managed)}
+}
+
+func newSecret(name, clusterID string, managed bool) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: newObjectMeta(name, clusterID, managed)}
+}
+
+func newApp(name, clusterID string, managed bool) *v1alpha1.App {
+	return &v1alpha1.App{ObjectMeta: newObjectMeta(name, clusterID, managed)}
+}
+
+// primary is a sharding.Interface owning all clusters whose replica is
+// primary or not.
+type primary bool
+
+func (p primary) Owns(obj interface{}) (bool, error) {
+	return true, nil
+}
+
+func (p primary) IsPrimary() bool {
+	return bool(p)
+}
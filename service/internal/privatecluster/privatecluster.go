@@ -7,10 +7,8 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
 	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
 )
 
@@ -18,24 +16,14 @@ import (
 // -In CAPZ and CAPA, we respect WC attributes. A non-private WC can exist in a private MC
 // - In CAPV and CAPVCD, we respect MC attributes. If MC is private, all WCs are private.
 // - In CAPG, we don’t have private concept.
-func IsPrivateCluster(ctx context.Context, logger micrologger.Logger, ctrlclient client.Client, cr capi.Cluster) (bool, error) {
+func IsPrivateCluster(ctx context.Context, logger micrologger.Logger, resolver *infra.Resolver, cr key.Cluster) (bool, error) {
 	var privateCluster bool
-	var err error
 
-	infrastructureRef := cr.Spec.InfrastructureRef
+	infrastructureRef := key.InfrastructureRef(cr)
 	if infrastructureRef != nil {
 		switch infrastructureRef.Kind {
 		case infra.AzureClusterKind, infra.AzureManagedClusterKind:
-			capzCluster := &unstructured.Unstructured{}
-			capzCluster.SetGroupVersionKind(schema.GroupVersionKind{
-				Group:   infrastructureRef.GroupVersionKind().Group,
-				Kind:    infrastructureRef.Kind,
-				Version: infrastructureRef.GroupVersionKind().Version,
-			})
-			err = ctrlclient.Get(ctx, client.ObjectKey{
-				Namespace: cr.Namespace,
-				Name:      infrastructureRef.Name,
-			}, capzCluster)
+			capzCluster, err := resolver.Get(ctx, cr, infrastructureRef.GroupVersionKind(), infrastructureRef.Namespace, infrastructureRef.Name)
 			if err != nil {
 				return false, microerror.Mask(err)
 			}
@@ -47,16 +35,7 @@ func IsPrivateCluster(ctx context.Context, logger micrologger.Logger, ctrlclient
 
 			privateCluster = apiServerLbType == "Internal"
 		case infra.AWSClusterKind, infra.AWSManagedClusterKind:
-			awsCluster := &unstructured.Unstructured{}
-			awsCluster.SetGroupVersionKind(schema.GroupVersionKind{
-				Group:   infrastructureRef.GroupVersionKind().Group,
-				Kind:    infrastructureRef.Kind,
-				Version: infrastructureRef.GroupVersionKind().Version,
-			})
-			err = ctrlclient.Get(ctx, client.ObjectKey{
-				Namespace: cr.Namespace,
-				Name:      infrastructureRef.Name,
-			}, awsCluster)
+			awsCluster, err := resolver.Get(ctx, cr, infrastructureRef.GroupVersionKind(), infrastructureRef.Namespace, infrastructureRef.Name)
 			if err != nil {
 				return false, microerror.Mask(err)
 			}
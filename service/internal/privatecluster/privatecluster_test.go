@@ -16,6 +16,7 @@ import (
 	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure/infrastructuretest"
 )
 
 func TestIsPrivateCluster(t *testing.T) {
@@ -219,12 +220,26 @@ func TestIsPrivateCluster(t *testing.T) {
 				}
 
 				ctrlclient = clientfake.NewClientBuilder().
+					WithRESTMapper(infrastructuretest.NewRESTMapper(tt.infraRef)).
 					WithRuntimeObjects(tt.infraRef).
 					Build()
 			}
 
+			var resolver *infra.Resolver
+			{
+				c := infra.ResolverConfig{
+					CtrlClient: ctrlclient,
+				}
+
+				var err error
+				resolver, err = infra.NewResolver(c)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
 			cluster := clusterForInfrastructureRef(tt.infraRef)
-			got, err := IsPrivateCluster(context.Background(), microloggertest.New(), ctrlclient, cluster)
+			got, err := IsPrivateCluster(context.Background(), microloggertest.New(), resolver, cluster)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("IsPrivateCluster() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -236,8 +251,8 @@ func TestIsPrivateCluster(t *testing.T) {
 	}
 }
 
-func clusterForInfrastructureRef(ref *unstructured.Unstructured) capi.Cluster {
-	return capi.Cluster{
+func clusterForInfrastructureRef(ref *unstructured.Unstructured) *capi.Cluster {
+	return &capi.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "default",
 		},
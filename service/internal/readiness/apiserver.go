@@ -0,0 +1,44 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	apiServerDescription = "Ensure the Kubernetes API server is reachable."
+	apiServerName        = "apiserver"
+)
+
+type APIServerConfig struct {
+	Discovery discovery.DiscoveryInterface
+}
+
+type APIServer struct {
+	discovery discovery.DiscoveryInterface
+}
+
+func NewAPIServer(config APIServerConfig) (*APIServer, error) {
+	if config.Discovery == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Discovery must not be empty", config)
+	}
+
+	a := &APIServer{
+		discovery: config.Discovery,
+	}
+
+	return a, nil
+}
+
+func (a *APIServer) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	v, err := a.discovery.ServerVersion()
+	if err != nil {
+		return failed(apiServerName, apiServerDescription, err.Error()), nil
+	}
+
+	return succeeded(apiServerName, apiServerDescription, fmt.Sprintf("API server %s reachable.", v.GitVersion)), nil
+}
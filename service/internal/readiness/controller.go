@@ -0,0 +1,62 @@
+package readiness
+
+import (
+	"context"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	controllerDescription = "Ensure the cluster controller has started and synced its cache."
+	controllerName        = "controller"
+)
+
+// Booter is implemented by the operatorkit controller. The returned channel is
+// closed once the controller has booted and its informer cache is synced.
+type Booter interface {
+	Booted() chan struct{}
+}
+
+// Leader is implemented by the leader elector.
+type Leader interface {
+	IsLeader() bool
+}
+
+type ControllerConfig struct {
+	Controller Booter
+	// Leader is optional. When set, replicas not holding the lease are
+	// considered ready since they are not expected to boot the controller.
+	Leader Leader
+}
+
+type Controller struct {
+	controller Booter
+	leader     Leader
+}
+
+func NewController(config ControllerConfig) (*Controller, error) {
+	if config.Controller == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Controller must not be empty", config)
+	}
+
+	c := &Controller{
+		controller: config.Controller,
+		leader:     config.Leader,
+	}
+
+	return c, nil
+}
+
+func (c *Controller) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	if c.leader != nil && !c.leader.IsLeader() {
+		return succeeded(controllerName, controllerDescription, "Not leading, controller on standby."), nil
+	}
+
+	select {
+	case <-c.controller.Booted():
+		return succeeded(controllerName, controllerDescription, "Controller booted."), nil
+	default:
+		return failed(controllerName, controllerDescription, "Controller not booted yet."), nil
+	}
+}
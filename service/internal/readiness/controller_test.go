@@ -0,0 +1,76 @@
+package readiness
+
+import (
+	"context"
+	"testing"
+)
+
+type testBooter struct {
+	booted chan struct{}
+}
+
+func (b testBooter) Booted() chan struct{} {
+	return b.booted
+}
+
+type testLeader bool
+
+func (l testLeader) IsLeader() bool {
+	return bool(l)
+}
+
+func Test_Controller_GetHealthz(t *testing.T) {
+	closed := make(chan struct{})
+	close(closed)
+
+	testCases := []struct {
+		name   string
+		booted chan struct{}
+		leader Leader
+		failed bool
+	}{
+		{
+			name:   "case 0: booted without leader election",
+			booted: closed,
+			failed: false,
+		},
+		{
+			name:   "case 1: not booted without leader election",
+			booted: make(chan struct{}),
+			failed: true,
+		},
+		{
+			name:   "case 2: not booted while on standby",
+			booted: make(chan struct{}),
+			leader: testLeader(false),
+			failed: false,
+		},
+		{
+			name:   "case 3: not booted while leading",
+			booted: make(chan struct{}),
+			leader: testLeader(true),
+			failed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewController(ControllerConfig{
+				Controller: testBooter{booted: tc.booted},
+				Leader:     tc.leader,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := c.GetHealthz(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Failed != tc.failed {
+				t.Fatalf("expected failed %t, got %t: %s", tc.failed, res.Failed, res.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	crdDescription = "Ensure the custom resources the operator reconciles are installed and served."
+	crdName        = "crd"
+
+	// defaultCRDCacheTTL is how long discovered resources are reused, so
+	// frequent probes do not hit discovery every time.
+	defaultCRDCacheTTL = time.Minute
+)
+
+type CRDConfig struct {
+	Discovery discovery.DiscoveryInterface
+
+	// Required are the kinds which must all be served.
+	Required []schema.GroupVersionKind
+	// AnyOf are kinds of which at least one must be served in any version of
+	// its group, e.g. the infrastructure kinds of which every installation
+	// only serves those of its own provider.
+	AnyOf []schema.GroupKind
+	// CacheTTL is how long discovered resources are reused. It defaults to
+	// one minute.
+	CacheTTL time.Duration
+}
+
+type CRD struct {
+	discovery discovery.DiscoveryInterface
+
+	required []schema.GroupVersionKind
+	anyOf    []schema.GroupKind
+	cacheTTL time.Duration
+
+	mutex      sync.Mutex
+	served     map[schema.GroupVersionKind]bool
+	discovered time.Time
+}
+
+func NewCRD(config CRDConfig) (*CRD, error) {
+	if config.Discovery == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Discovery must not be empty", config)
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = defaultCRDCacheTTL
+	}
+
+	c := &CRD{
+		discovery: config.Discovery,
+
+		required: config.Required,
+		anyOf:    config.AnyOf,
+		cacheTTL: config.CacheTTL,
+	}
+
+	return c, nil
+}
+
+func (c *CRD) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	served, err := c.servedKinds()
+	if err != nil {
+		return failed(crdName, crdDescription, err.Error()), nil
+	}
+
+	var missing []string
+
+	for _, gvk := range c.required {
+		if !served[gvk] {
+			missing = append(missing, gvk.String())
+		}
+	}
+
+	if len(c.anyOf) > 0 && !isAnyServed(served, c.anyOf) {
+		var kinds []string
+		for _, gk := range c.anyOf {
+			kinds = append(kinds, gk.String())
+		}
+		missing = append(missing, fmt.Sprintf("any of %s", strings.Join(kinds, ", ")))
+	}
+
+	if len(missing) > 0 {
+		return failed(crdName, crdDescription, fmt.Sprintf("Not served: %s.", strings.Join(missing, "; "))), nil
+	}
+
+	return succeeded(crdName, crdDescription, "All custom resources served."), nil
+}
+
+// servedKinds returns all kinds served by the API server with a single
+// discovery call, reusing the result for the cache TTL. Groups which fail
+// discovery are treated as not served.
+func (c *CRD) servedKinds() (map[schema.GroupVersionKind]bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.served != nil && time.Since(c.discovered) < c.cacheTTL {
+		return c.served, nil
+	}
+
+	_, lists, err := c.discovery.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, microerror.Mask(err)
+	}
+
+	served := map[schema.GroupVersionKind]bool{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			served[gv.WithKind(r.Kind)] = true
+		}
+	}
+
+	c.served = served
+	c.discovered = time.Now()
+
+	return served, nil
+}
+
+func isAnyServed(served map[schema.GroupVersionKind]bool, kinds []schema.GroupKind) bool {
+	for gvk := range served {
+		for _, gk := range kinds {
+			if gvk.GroupKind() == gk {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package readiness
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func Test_CRD_GetHealthz(t *testing.T) {
+	testCases := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		failed    bool
+	}{
+		{
+			name: "case 0: all kinds served",
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "application.giantswarm.io/v1alpha1",
+					APIResources: []metav1.APIResource{{Name: "apps", Kind: "App"}},
+				},
+				{
+					GroupVersion: "infrastructure.cluster.x-k8s.io/v1beta2",
+					APIResources: []metav1.APIResource{{Name: "awsclusters", Kind: "AWSCluster"}},
+				},
+			},
+			failed: false,
+		},
+		{
+			name: "case 1: required kind not served",
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "infrastructure.cluster.x-k8s.io/v1beta2",
+					APIResources: []metav1.APIResource{{Name: "awsclusters", Kind: "AWSCluster"}},
+				},
+			},
+			failed: true,
+		},
+		{
+			name: "case 2: no infrastructure kind served",
+			resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "application.giantswarm.io/v1alpha1",
+					APIResources: []metav1.APIResource{{Name: "apps", Kind: "App"}},
+				},
+				{
+					GroupVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+					APIResources: []metav1.APIResource{{Name: "foos", Kind: "Foo"}},
+				},
+			},
+			failed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tc.resources}}
+
+			c, err := NewCRD(CRDConfig{
+				Discovery: d,
+
+				Required: []schema.GroupVersionKind{
+					{Group: "application.giantswarm.io", Version: "v1alpha1", Kind: "App"},
+				},
+				AnyOf: []schema.GroupKind{
+					{Group: "infrastructure.cluster.x-k8s.io", Kind: "AWSCluster"},
+					{Group: "infrastructure.cluster.x-k8s.io", Kind: "AzureCluster"},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := c.GetHealthz(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Failed != tc.failed {
+				t.Fatalf("expected failed %t, got %t: %s", tc.failed, res.Failed, res.Message)
+			}
+
+			// Discovery results are reused within the cache TTL.
+			actions := len(d.Actions())
+			_, err = c.GetHealthz(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(d.Actions()) != actions {
+				t.Fatalf("expected cached discovery, got %d new discovery calls", len(d.Actions())-actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+// Package readiness implements the health checks backing the readiness
+// endpoint. Every check implements the microendpoint healthz.Service
+// interface and reports failures through the response, not through errors.
+package readiness
+
+import (
+	"github.com/giantswarm/microendpoint/service/healthz"
+)
+
+func failed(name, description, message string) healthz.Response {
+	return healthz.Response{
+		Description: description,
+		Failed:      true,
+		Message:     message,
+		Name:        name,
+	}
+}
+
+func succeeded(name, description, message string) healthz.Response {
+	return healthz.Response{
+		Description: description,
+		Failed:      false,
+		Message:     message,
+		Name:        name,
+	}
+}
@@ -0,0 +1,50 @@
+package readiness
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+)
+
+const (
+	reconcileDescription = "Ensure recent reconciliations did not fail for all clusters."
+	reconcileName        = "reconcile"
+)
+
+type ReconcileConfig struct {
+	Recorder *clusterstatus.Recorder
+}
+
+type Reconcile struct {
+	recorder *clusterstatus.Recorder
+}
+
+func NewReconcile(config ReconcileConfig) (*Reconcile, error) {
+	if config.Recorder == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Recorder must not be empty", config)
+	}
+
+	r := &Reconcile{
+		recorder: config.Recorder,
+	}
+
+	return r, nil
+}
+
+func (r *Reconcile) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	total, failing := r.recorder.FailingClusters()
+
+	// Only clusters with a full window of results are taken into account, so
+	// a few errors right after start, e.g. while workload clusters are being
+	// created, do not flap readiness. A single broken cluster does not make
+	// the operator unready, only all clusters failing does.
+	if total > 0 && failing == total {
+		return failed(reconcileName, reconcileDescription, fmt.Sprintf("Last %d reconciliations of all %d clusters failed.", clusterstatus.ResultWindow, total)), nil
+	}
+
+	return succeeded(reconcileName, reconcileDescription, fmt.Sprintf("Last %d reconciliations of %d of %d clusters failed.", clusterstatus.ResultWindow, failing, total)), nil
+}
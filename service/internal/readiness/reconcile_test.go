@@ -0,0 +1,65 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+)
+
+func Test_Reconcile_GetHealthz(t *testing.T) {
+	testCases := []struct {
+		name     string
+		failures map[string]bool
+		failed   bool
+	}{
+		{
+			name:   "case 0: no clusters",
+			failed: false,
+		},
+		{
+			name:     "case 1: single broken cluster",
+			failures: map[string]bool{"a1": true, "b1": false},
+			failed:   false,
+		},
+		{
+			name:     "case 2: all clusters broken",
+			failures: map[string]bool{"a1": true, "b1": true},
+			failed:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := clusterstatus.New()
+			for name, fail := range tc.failures {
+				cluster := &capi.Cluster{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "org-acme"}}
+
+				var err error
+				if fail {
+					err = errors.New("boom")
+				}
+				for i := 0; i < clusterstatus.ResultWindow; i++ {
+					recorder.RecordResource(cluster, "app", false, err)
+				}
+			}
+
+			r, err := NewReconcile(ReconcileConfig{Recorder: recorder})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := r.GetHealthz(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Failed != tc.failed {
+				t.Fatalf("expected failed %t, got %t: %s", tc.failed, res.Failed, res.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+// Package sharding distributes the reconciliation of clusters across operator
+// replicas. Every replica announces itself with its own Lease and clusters are
+// assigned to the live replicas by rendezvous hashing of a shard key, so when
+// replicas come and go only the clusters of the affected replicas move.
+//
+// There is no fencing. Every replica decides ownership from its own view of
+// the live members, so while the views differ, for up to a renew period after
+// a membership change, two replicas may reconcile the same cluster. A replica
+// failing to renew its Lease for a lease duration drops all members, so it
+// owns no clusters until it renews again. All
+// resources are idempotent and ownership is checked again before every
+// resource, which narrows but does not close this window.
+//
+// Membership is only recorded in the Leases, nothing is written to Clusters.
+// A cluster moving to another replica is reconciled there with the next resync.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+)
+
+const (
+	// labelShardGroup is set on the Leases of all replicas taking part in the
+	// same sharded controller.
+	labelShardGroup = "cluster-apps-operator.giantswarm.io/shard-group"
+
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+type Config struct {
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// Identity identifies this replica. It defaults to the hostname, which is
+	// the pod name.
+	Identity string
+	// Label is the Cluster label used as shard key with StrategyLabel.
+	Label          string
+	LeaseDuration  time.Duration
+	LeaseName      string
+	LeaseNamespace string
+	RenewPeriod    time.Duration
+	Strategy       string
+}
+
+type Sharding struct {
+	k8sClient kubernetes.Interface
+	logger    micrologger.Logger
+
+	identity       string
+	label          string
+	leaseDuration  time.Duration
+	leaseName      string
+	leaseNamespace string
+	renewPeriod    time.Duration
+	strategy       string
+
+	mutex   sync.RWMutex
+	members []string
+	// renewed is the time of the last successful renewal of the own Lease.
+	renewed time.Time
+}
+
+func New(config Config) (*Sharding, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.LeaseDuration <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LeaseDuration must be greater than 0", config)
+	}
+	if config.LeaseName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LeaseName must not be empty", config)
+	}
+	if config.RenewPeriod <= 0 || config.RenewPeriod >= config.LeaseDuration {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RenewPeriod must be greater than 0 and less than %T.LeaseDuration", config, config)
+	}
+
+	switch config.Strategy {
+	case StrategyHash, StrategyNamespace:
+	case StrategyLabel:
+		if config.Label == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Label must not be empty for strategy %#q", config, StrategyLabel)
+		}
+	default:
+		return nil, microerror.Maskf(invalidConfigError, "%T.Strategy must be one of %#q, %#q or %#q, got %#q", config, StrategyHash, StrategyLabel, StrategyNamespace, config.Strategy)
+	}
+
+	if config.Identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		config.Identity = hostname
+	}
+
+	if config.LeaseNamespace == "" {
+		namespace, err := os.ReadFile(serviceAccountNamespaceFile)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.LeaseNamespace must not be empty when not running in a pod: %s", config, err.Error())
+		}
+		config.LeaseNamespace = strings.TrimSpace(string(namespace))
+	}
+
+	s := &Sharding{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		identity:       config.Identity,
+		label:          config.Label,
+		leaseDuration:  config.LeaseDuration,
+		leaseName:      config.LeaseName,
+		leaseNamespace: config.LeaseNamespace,
+		renewPeriod:    config.RenewPeriod,
+		strategy:       config.Strategy,
+	}
+
+	return s, nil
+}
+
+// Owns implements Interface.
+func (s *Sharding) Owns(obj interface{}) (bool, error) {
+	getter, ok := obj.(key.ObjectGetter)
+	if !ok {
+		return false, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", key.ObjectGetter(nil), obj)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return owner(s.members, s.shardKey(getter)) == s.identity, nil
+}
+
+// IsPrimary implements Interface. The primary replica is the first of the
+// sorted live members, so all replicas agree on it once their views of the
+// members converge.
+func (s *Sharding) IsPrimary() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.members) > 0 && s.members[0] == s.identity
+}
+
+// Members returns the identities of the live replicas.
+func (s *Sharding) Members() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return append([]string{}, s.members...)
+}
+
+// Boot renews the Lease of this replica and refreshes shard membership until
+// ctx is canceled. The Lease is deleted on return so the remaining replicas
+// take over immediately.
+func (s *Sharding) Boot(ctx context.Context) {
+	ticker := time.NewTicker(s.renewPeriod)
+	defer ticker.Stop()
+
+	for {
+		err := s.sync(ctx)
+		if err != nil {
+			s.logger.Errorf(ctx, err, "failed to sync shard membership")
+		}
+
+		select {
+		case <-ctx.Done():
+			s.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sharding) sync(ctx context.Context) error {
+	err := s.renew(ctx)
+	if err != nil {
+		s.expire(ctx, time.Now())
+		return microerror.Mask(err)
+	}
+
+	s.mutex.Lock()
+	s.renewed = time.Now()
+	s.mutex.Unlock()
+
+	list, err := s.k8sClient.CoordinationV1().Leases(s.leaseNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", labelShardGroup, s.leaseName),
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	now := time.Now()
+	members := liveMembers(list.Items, now)
+
+	s.deleteExpired(ctx, list.Items, now)
+
+	s.mutex.Lock()
+	if strings.Join(members, ",") != strings.Join(s.members, ",") {
+		s.logger.Debugf(ctx, "shard members changed from %v to %v", s.members, members)
+	}
+	s.members = members
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// expire clears the members once the own Lease was not renewed for a lease
+// duration. The other replicas consider this replica gone by then and take
+// over its clusters, so it must stop reconciling them.
+func (s *Sharding) expire(ctx context.Context, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.members) == 0 || !now.After(s.renewed.Add(s.leaseDuration)) {
+		return
+	}
+
+	s.logger.Debugf(ctx, "lease not renewed since %s, clearing shard members %v", s.renewed.Format(time.RFC3339), s.members)
+	s.members = nil
+}
+
+// deleteExpired deletes the Leases of other replicas which expired more than a
+// lease duration ago. Replicas only delete their own Lease on a clean
+// shutdown, so the Leases of crashed replicas would stay forever otherwise.
+// The resource version precondition keeps Leases renewed in the meantime.
+func (s *Sharding) deleteExpired(ctx context.Context, leases []coordinationv1.Lease, now time.Time) {
+	for _, l := range leases {
+		if l.Name == s.ownLeaseName() || l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+			continue
+		}
+
+		duration := time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second
+		if !now.After(l.Spec.RenewTime.Add(2 * duration)) {
+			continue
+		}
+
+		resourceVersion := l.ResourceVersion
+		err := s.k8sClient.CoordinationV1().Leases(l.Namespace).Delete(ctx, l.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &resourceVersion},
+		})
+		if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+			continue
+		} else if err != nil {
+			s.logger.Errorf(ctx, err, "failed to delete expired lease '%s/%s'", l.Namespace, l.Name)
+			continue
+		}
+
+		s.logger.Debugf(ctx, "deleted expired lease '%s/%s'", l.Namespace, l.Name)
+	}
+}
+
+func (s *Sharding) renew(ctx context.Context) error {
+	leases := s.k8sClient.CoordinationV1().Leases(s.leaseNamespace)
+	now := metav1.NewMicroTime(time.Now())
+	durationSeconds := int32(s.leaseDuration / time.Second)
+
+	lease, err := leases.Get(ctx, s.ownLeaseName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.ownLeaseName(),
+				Namespace: s.leaseNamespace,
+				Labels: map[string]string{
+					labelShardGroup: s.leaseName,
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &s.identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+
+		_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	lease.Spec.HolderIdentity = &s.identity
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.RenewTime = &now
+
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (s *Sharding) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.renewPeriod)
+	defer cancel()
+
+	err := s.k8sClient.CoordinationV1().Leases(s.leaseNamespace).Delete(ctx, s.ownLeaseName(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		s.logger.Errorf(ctx, err, "failed to delete lease '%s/%s'", s.leaseNamespace, s.ownLeaseName())
+	}
+}
+
+func (s *Sharding) ownLeaseName() string {
+	return fmt.Sprintf("%s-%s", s.leaseName, s.identity)
+}
+
+func (s *Sharding) shardKey(getter key.ObjectGetter) string {
+	switch s.strategy {
+	case StrategyLabel:
+		return getter.GetLabels()[s.label]
+	case StrategyNamespace:
+		return getter.GetNamespace()
+	default:
+		return fmt.Sprintf("%s/%s", getter.GetNamespace(), key.ClusterID(getter))
+	}
+}
+
+// liveMembers returns the sorted holder identities of all Leases renewed within
+// their lease duration.
+func liveMembers(leases []coordinationv1.Lease, now time.Time) []string {
+	var members []string
+
+	for _, l := range leases {
+		if l.Spec.HolderIdentity == nil || l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+			continue
+		}
+
+		expiry := l.Spec.RenewTime.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second)
+		if now.After(expiry) {
+			continue
+		}
+
+		members = append(members, *l.Spec.HolderIdentity)
+	}
+
+	sort.Strings(members)
+
+	return members
+}
+
+// owner returns the member with the highest hash for the given shard key, or
+// an empty string if there are no members.
+func owner(members []string, shardKey string) string {
+	var selected string
+	var highest uint64
+
+	for _, m := range members {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(m))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(shardKey))
+
+		sum := mix(h.Sum64())
+		if selected == "" || sum > highest {
+			selected = m
+			highest = sum
+		}
+	}
+
+	return selected
+}
+
+// mix is the 64 bit finalizer of MurmurHash3. FNV alone barely spreads
+// differences of member names sharing a long prefix into the high bits, which
+// skews the assignment towards single members.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+
+	return h
+}
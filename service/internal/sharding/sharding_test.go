@@ -0,0 +1,293 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+)
+
+func Test_owner_Rebalance(t *testing.T) {
+	members := []string{"replica-a", "replica-b", "replica-c"}
+
+	before := map[string]string{}
+	for i := 0; i < 300; i++ {
+		k := fmt.Sprintf("org-test/cluster%d", i)
+		before[k] = owner(members, k)
+	}
+
+	counts := map[string]int{}
+	for _, o := range before {
+		counts[o]++
+	}
+	for _, m := range members {
+		if counts[m] == 0 {
+			t.Fatalf("expected %#q to own clusters, got distribution %v", m, counts)
+		}
+	}
+
+	// Removing a replica must only move the clusters it owned.
+	remaining := []string{"replica-a", "replica-c"}
+	for k, o := range before {
+		after := owner(remaining, k)
+		if o != "replica-b" && after != o {
+			t.Fatalf("expected %#q to stay on %#q, moved to %#q", k, o, after)
+		}
+	}
+
+	if owner(nil, "org-test/cluster0") != "" {
+		t.Fatalf("expected no owner without members")
+	}
+}
+
+func Test_liveMembers(t *testing.T) {
+	now := time.Now()
+	duration := int32(30)
+
+	newLease := func(identity string, renewed time.Time) coordinationv1.Lease {
+		renewTime := metav1.NewMicroTime(renewed)
+		return coordinationv1.Lease{
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &identity,
+				LeaseDurationSeconds: &duration,
+				RenewTime:            &renewTime,
+			},
+		}
+	}
+
+	members := liveMembers([]coordinationv1.Lease{
+		newLease("replica-c", now.Add(-5*time.Second)),
+		newLease("replica-b", now.Add(-time.Minute)),
+		newLease("replica-a", now),
+	}, now)
+
+	if fmt.Sprint(members) != "[replica-a replica-c]" {
+		t.Fatalf("expected [replica-a replica-c], got %v", members)
+	}
+}
+
+func Test_Sharding_Owns(t *testing.T) {
+	testCases := []struct {
+		name     string
+		strategy string
+		label    string
+		a        *capi.Cluster
+		b        *capi.Cluster
+	}{
+		{
+			name:     "case 0: clusters in the same namespace share a shard",
+			strategy: StrategyNamespace,
+			a:        newCluster("org-test", "a1", nil),
+			b:        newCluster("org-test", "b1", nil),
+		},
+		{
+			name:     "case 1: clusters with the same label value share a shard",
+			strategy: StrategyLabel,
+			label:    "giantswarm.io/organization",
+			a:        newCluster("org-a", "a1", map[string]string{"giantswarm.io/organization": "test"}),
+			b:        newCluster("org-b", "b1", map[string]string{"giantswarm.io/organization": "test"}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				s, err := New(Config{
+					K8sClient: fake.NewClientset(),
+					Logger:    microloggertest.New(),
+
+					Identity:       fmt.Sprintf("replica-%d", i),
+					Label:          tc.label,
+					LeaseDuration:  30 * time.Second,
+					LeaseName:      "test",
+					LeaseNamespace: "giantswarm",
+					RenewPeriod:    10 * time.Second,
+					Strategy:       tc.strategy,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				s.members = []string{"replica-0", "replica-1", "replica-2", "replica-3", "replica-4", "replica-5", "replica-6", "replica-7", "replica-8", "replica-9"}
+
+				ownsA, err := s.Owns(tc.a)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ownsB, err := s.Owns(tc.b)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if ownsA != ownsB {
+					t.Fatalf("expected both clusters on the same shard")
+				}
+				if s.IsPrimary() != (i == 0) {
+					t.Fatalf("expected only replica-0 to be primary, got %t for replica-%d", s.IsPrimary(), i)
+				}
+			}
+		})
+	}
+}
+
+func Test_Sharding_sync(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	k8sClient := fake.NewClientset(
+		newLease("replica-b", now.Add(-5*time.Second)),
+		newLease("replica-c", now.Add(-2*time.Minute)),
+	)
+
+	s, err := New(Config{
+		K8sClient: k8sClient,
+		Logger:    microloggertest.New(),
+
+		Identity:       "replica-a",
+		LeaseDuration:  30 * time.Second,
+		LeaseName:      "test",
+		LeaseNamespace: "giantswarm",
+		RenewPeriod:    10 * time.Second,
+		Strategy:       StrategyHash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The lease of replica-c expired more than a lease duration ago.
+	_, err = k8sClient.CoordinationV1().Leases("giantswarm").Get(ctx, "test-replica-c", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected expired lease to be deleted, got %v", err)
+	}
+	_, err = k8sClient.CoordinationV1().Leases("giantswarm").Get(ctx, "test-replica-b", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected live lease to be kept, got %v", err)
+	}
+
+	// The own lease is created and the expired replica is no member.
+	_, err = k8sClient.CoordinationV1().Leases("giantswarm").Get(ctx, "test-replica-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected own lease to be created, got %v", err)
+	}
+	if fmt.Sprint(s.Members()) != "[replica-a replica-b]" {
+		t.Fatalf("expected members [replica-a replica-b], got %v", s.Members())
+	}
+}
+
+func Test_Sharding_sync_RenewFailure(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	k8sClient := fake.NewClientset(
+		newLease("replica-a", now),
+		newLease("replica-b", now),
+	)
+
+	s, err := New(Config{
+		K8sClient: k8sClient,
+		Logger:    microloggertest.New(),
+
+		Identity:       "replica-a",
+		LeaseDuration:  30 * time.Second,
+		LeaseName:      "test",
+		LeaseNamespace: "giantswarm",
+		RenewPeriod:    10 * time.Second,
+		Strategy:       StrategyHash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.IsPrimary() {
+		t.Fatalf("expected replica-a to be primary")
+	}
+
+	k8sClient.PrependReactor("update", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewServiceUnavailable("test")
+	})
+
+	// Members are kept while the lease has not expired yet.
+	err = s.sync(ctx)
+	if err == nil {
+		t.Fatalf("expected renew error")
+	}
+	if fmt.Sprint(s.Members()) != "[replica-a replica-b]" {
+		t.Fatalf("expected members [replica-a replica-b], got %v", s.Members())
+	}
+
+	// Members are cleared once the last renewal is older than a lease duration.
+	s.renewed = now.Add(-time.Minute)
+
+	err = s.sync(ctx)
+	if err == nil {
+		t.Fatalf("expected renew error")
+	}
+	if len(s.Members()) != 0 {
+		t.Fatalf("expected no members, got %v", s.Members())
+	}
+	if s.IsPrimary() {
+		t.Fatalf("expected replica-a not to be primary")
+	}
+
+	owns, err := s.Owns(newCluster("org-test", "a1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owns {
+		t.Fatalf("expected replica-a to own no clusters")
+	}
+}
+
+func newLease(identity string, renewTime time.Time) *coordinationv1.Lease {
+	durationSeconds := int32(30)
+	renew := metav1.NewMicroTime(renewTime)
+
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-" + identity,
+			Namespace: "giantswarm",
+			Labels: map[string]string{
+				labelShardGroup: "test",
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &identity,
+			LeaseDurationSeconds: &durationSeconds,
+			RenewTime:            &renew,
+		},
+	}
+}
+
+func newCluster(namespace, name string, labels map[string]string) *capi.Cluster {
+	l := map[string]string{
+		capi.ClusterNameLabel: name,
+	}
+	for k, v := range labels {
+		l[k] = v
+	}
+
+	return &capi.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    l,
+		},
+	}
+}
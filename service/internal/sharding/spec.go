@@ -0,0 +1,25 @@
+package sharding
+
+const (
+	// StrategyHash assigns every cluster independently by its namespace and
+	// cluster ID.
+	StrategyHash = "hash"
+	// StrategyLabel assigns all clusters carrying the same value of the
+	// configured label to the same replica.
+	StrategyLabel = "label"
+	// StrategyNamespace assigns all clusters of a namespace to the same
+	// replica.
+	StrategyNamespace = "namespace"
+)
+
+// Interface decides whether this replica is responsible for a cluster.
+type Interface interface {
+	// Owns returns whether the given object is reconciled by this replica. It
+	// returns false as long as shard membership is not known yet. Clusters
+	// skipped meanwhile are reconciled with the next resync.
+	Owns(obj interface{}) (bool, error)
+	// IsPrimary returns whether this replica runs the work which is not
+	// sharded by cluster, e.g. the orphan sweeper. It returns false as long as
+	// shard membership is not known yet.
+	IsPrimary() bool
+}
@@ -5,17 +5,29 @@ package service
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 
 	appv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
 	"github.com/giantswarm/k8sclient/v8/pkg/k8sclient"
 	"github.com/giantswarm/k8sclient/v8/pkg/k8srestconfig"
+	"github.com/giantswarm/k8smetadata/pkg/label"
+	"github.com/giantswarm/microendpoint/service/healthz"
 	"github.com/giantswarm/microendpoint/service/version"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
-	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	"k8s.io/client-go/tools/record"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	capo "github.com/giantswarm/cluster-apps-operator/v3/api/capo/v1alpha4"
 	capvcd "github.com/giantswarm/cluster-apps-operator/v3/api/capvcd/v1beta1"
@@ -26,7 +38,18 @@ import (
 	"github.com/giantswarm/cluster-apps-operator/v3/service/collector"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/controller"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/key"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/clusterconfigmap"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/capiversion"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/clusterstatus"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/cni"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/compatibility"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/extravalues"
+	infra "github.com/giantswarm/cluster-apps-operator/v3/service/internal/infrastructure"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/leaderelection"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/orphansweeper"
 	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/podcidr"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/readiness"
+	"github.com/giantswarm/cluster-apps-operator/v3/service/internal/sharding"
 )
 
 // Config represents the configuration used to create a new service.
@@ -38,11 +61,19 @@ type Config struct {
 }
 
 type Service struct {
-	Version *version.Service
+	ClusterStatus *clusterstatus.Recorder
+	// Readiness are the checks executed by the readiness endpoint.
+	Readiness []healthz.Service
+	Version   *version.Service
 
-	bootOnce          sync.Once
-	clusterController *controller.Cluster
-	operatorCollector *collector.Set
+	appCache            cache.Cache
+	bootOnce            sync.Once
+	clusterController   *controller.Cluster
+	clusterStatusPruner *clusterstatus.Pruner
+	leaderElector       *leaderelection.Elector
+	operatorCollector   *collector.Set
+	orphanSweeper       *orphansweeper.Sweeper
+	sharding            *sharding.Sharding
 }
 
 // New creates a new configured service object.
@@ -96,6 +127,10 @@ func New(config Config) (*Service, error) {
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
+
+		// Zero values keep the client-go defaults.
+		restConfig.QPS = float32(config.Viper.GetFloat64(config.Flag.Service.Controller.Client.QPS))
+		restConfig.Burst = config.Viper.GetInt(config.Flag.Service.Controller.Client.Burst)
 	}
 
 	var k8sClient k8sclient.Interface
@@ -104,6 +139,7 @@ func New(config Config) (*Service, error) {
 			Logger: config.Logger,
 			SchemeBuilder: k8sclient.SchemeBuilder{
 				appv1alpha1.AddToScheme,
+				capiv1beta1.AddToScheme,
 				capi.AddToScheme,
 				capo.AddToScheme,
 				capz.AddToScheme,
@@ -120,6 +156,17 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	// Clusters are reconciled as v1beta2 whenever the API server serves it
+	// and fall back to v1beta1 otherwise.
+	var clusterGroupVersion schema.GroupVersion
+	{
+		var err error
+		clusterGroupVersion, err = capiversion.Preferred(k8sClient.K8sClient().Discovery())
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var pc podcidr.Interface
 	{
 		calicoSubnet := config.Viper.GetString(config.Flag.Service.Workload.Cluster.Calico.Subnet)
@@ -135,29 +182,164 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	clusterStatus := clusterstatus.New()
+
+	var chartOperatorCompatibility *compatibility.Matrix
+	{
+		var err error
+		chartOperatorCompatibility, err = compatibility.Load(config.Viper.GetString(config.Flag.Service.App.ChartOperator.CompatibilityMatrix))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Events are emitted for Clusters, e.g. when the configured version of
+	// an operator app cannot be resolved.
+	var eventRecorder record.EventRecorder
+	{
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+			Interface: k8sClient.K8sClient().CoreV1().Events(""),
+		})
+
+		eventRecorder = broadcaster.NewRecorder(k8sClient.Scheme(), corev1.EventSource{Component: project.Name()})
+	}
+
+	// Apps are listed in every reconciliation to detect the CNI of the
+	// cluster, so they are served from an informer cache of the Apps
+	// labelled with a cluster.
+	var appCache cache.Cache
+	{
+		selector, err := labels.Parse(label.Cluster)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		appCache, err = cache.New(restConfig, cache.Options{
+			Scheme: k8sClient.Scheme(),
+			ByObject: map[client.Object]cache.ByObject{
+				&appv1alpha1.App{}: {Label: selector},
+			},
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// The informer is registered up front, so reads wait for it to
+		// sync once the cache is started in Boot.
+		_, err = appCache.GetInformer(context.Background(), &appv1alpha1.App{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var cniDetector *cni.Detector
+	{
+		c := cni.Config{
+			AppReader:  appCache,
+			CtrlClient: k8sClient.CtrlClient(),
+			Logger:     config.Logger,
+		}
+
+		var err error
+		cniDetector, err = cni.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var extraValues *extravalues.ExtraValues
+	{
+		c := extravalues.Config{
+			CtrlClient:    k8sClient.CtrlClient(),
+			EventRecorder: eventRecorder,
+			Logger:        config.Logger,
+			Recorder:      clusterStatus,
+
+			InstallationConfigMap: config.Viper.GetString(config.Flag.Service.Workload.Cluster.ExtraValues.ConfigMap),
+			InstallationSecret:    config.Viper.GetString(config.Flag.Service.Workload.Cluster.ExtraValues.Secret),
+
+			TopologyVariablesConfigMap: config.Viper.GetStringSlice(config.Flag.Service.Workload.Cluster.ExtraValues.TopologyVariables.ConfigMap),
+			TopologyVariablesSecret:    config.Viper.GetStringSlice(config.Flag.Service.Workload.Cluster.ExtraValues.TopologyVariables.Secret),
+
+			ConfigMapSchema: clusterconfigmap.Schema(),
+		}
+
+		var err error
+		extraValues, err = extravalues.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	if config.Viper.GetBool(config.Flag.Service.Controller.Sharding.Enabled) && config.Viper.GetBool(config.Flag.Service.Controller.LeaderElection.Enabled) {
+		return nil, microerror.Maskf(invalidConfigError, "sharding and leader election must not be enabled at the same time")
+	}
+
+	var shards *sharding.Sharding
+	if config.Viper.GetBool(config.Flag.Service.Controller.Sharding.Enabled) {
+		c := sharding.Config{
+			K8sClient: k8sClient.K8sClient(),
+			Logger:    config.Logger,
+
+			Label:          config.Viper.GetString(config.Flag.Service.Controller.Sharding.Label),
+			LeaseDuration:  config.Viper.GetDuration(config.Flag.Service.Controller.Sharding.LeaseDuration),
+			LeaseName:      config.Viper.GetString(config.Flag.Service.Controller.Sharding.LeaseName),
+			LeaseNamespace: config.Viper.GetString(config.Flag.Service.Controller.Sharding.LeaseNamespace),
+			RenewPeriod:    config.Viper.GetDuration(config.Flag.Service.Controller.Sharding.RenewPeriod),
+			Strategy:       config.Viper.GetString(config.Flag.Service.Controller.Sharding.Strategy),
+		}
+
+		var err error
+		shards, err = sharding.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var clusterController *controller.Cluster
 	{
 		c := controller.ClusterConfig{
-			K8sClient: k8sClient,
-			Logger:    config.Logger,
-			PodCIDR:   pc,
+			CNI:           cniDetector,
+			EventRecorder: eventRecorder,
+			ExtraValues:   extraValues,
+			K8sClient:     k8sClient,
+			Logger:        config.Logger,
+			PodCIDR:       pc,
+			Recorder:      clusterStatus,
+
+			ChartOperatorCompatibility: chartOperatorCompatibility,
+
+			ClusterGroupVersion: clusterGroupVersion,
 
-			ResyncPeriod: config.Viper.GetDuration(config.Flag.Service.Controller.ResyncPeriod),
+			BackoffInitialInterval: config.Viper.GetDuration(config.Flag.Service.Controller.Backoff.InitialInterval),
+			BackoffMaxInterval:     config.Viper.GetDuration(config.Flag.Service.Controller.Backoff.MaxInterval),
+			ResyncPeriod:           config.Viper.GetDuration(config.Flag.Service.Controller.ResyncPeriod),
+			RetryInterval:          config.Viper.GetDuration(config.Flag.Service.Controller.Retry.Interval),
+			RetryMaxRetries:        config.Viper.GetUint64(config.Flag.Service.Controller.Retry.MaxRetries),
 
 			AppOperatorCatalog:   config.Viper.GetString(config.Flag.Service.App.AppOperator.Catalog),
 			AppOperatorVersion:   config.Viper.GetString(config.Flag.Service.App.AppOperator.Version),
 			ChartOperatorCatalog: config.Viper.GetString(config.Flag.Service.App.ChartOperator.Catalog),
 			ChartOperatorVersion: config.Viper.GetString(config.Flag.Service.App.ChartOperator.Version),
 			BaseDomain:           config.Viper.GetString(config.Flag.Service.Workload.Cluster.BaseDomain),
+			ClusterDomain:        config.Viper.GetString(config.Flag.Service.Workload.Cluster.Kubernetes.ClusterDomain),
 			ClusterIPRange:       clusterIPRange,
 			DNSIP:                dnsIP,
 			ManagementClusterID:  config.Viper.GetString(config.Flag.Service.Workload.Cluster.Owner),
+			MetadataAnnotations:  config.Viper.GetStringSlice(config.Flag.Service.Workload.Cluster.Metadata.Annotations),
+			MetadataLabels:       config.Viper.GetStringSlice(config.Flag.Service.Workload.Cluster.Metadata.Labels),
 			Proxy: proxy.Proxy{
 				HttpProxy:  config.Viper.GetString(config.Flag.Service.Proxy.HttpProxy),
 				HttpsProxy: config.Viper.GetString(config.Flag.Service.Proxy.HttpsProxy),
 				NoProxy:    config.Viper.GetString(config.Flag.Service.Proxy.NoProxy),
 			},
 			RegistryDomain: config.Viper.GetString(config.Flag.Service.Image.Registry.Domain),
+
+			AWSManagementAccountID: config.Viper.GetString(config.Flag.Service.Workload.Cluster.AWS.ManagementAccountID),
+		}
+		if shards != nil {
+			c.Sharding = shards
 		}
 
 		var err error
@@ -167,11 +349,94 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	var orphanSweeper *orphansweeper.Sweeper
+	if config.Viper.GetBool(config.Flag.Service.Controller.OrphanSweeper.Enabled) {
+		c := orphansweeper.Config{
+			CtrlClient: k8sClient.CtrlClient(),
+			Logger:     config.Logger,
+
+			ClusterGroupVersion: clusterGroupVersion,
+			DryRun:              config.Viper.GetBool(config.Flag.Service.Controller.OrphanSweeper.DryRun),
+			Interval:            config.Viper.GetDuration(config.Flag.Service.Controller.OrphanSweeper.Interval),
+		}
+		if shards != nil {
+			c.Sharding = shards
+		}
+
+		var err error
+		orphanSweeper, err = orphansweeper.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clusterStatusPruner *clusterstatus.Pruner
+	{
+		c := clusterstatus.PrunerConfig{
+			CtrlClient: k8sClient.CtrlClient(),
+			Logger:     config.Logger,
+			Recorder:   clusterStatus,
+
+			ClusterGroupVersion: clusterGroupVersion,
+			Interval:            config.Viper.GetDuration(config.Flag.Service.Controller.ResyncPeriod),
+		}
+		if shards != nil {
+			c.Sharding = shards
+		}
+
+		var err error
+		clusterStatusPruner, err = clusterstatus.NewPruner(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var leaderElector *leaderelection.Elector
+	if config.Viper.GetBool(config.Flag.Service.Controller.LeaderElection.Enabled) {
+		c := leaderelection.Config{
+			K8sClient: k8sClient.K8sClient(),
+			Logger:    config.Logger,
+
+			LeaseDuration:  config.Viper.GetDuration(config.Flag.Service.Controller.LeaderElection.LeaseDuration),
+			LeaseName:      config.Viper.GetString(config.Flag.Service.Controller.LeaderElection.LeaseName),
+			LeaseNamespace: config.Viper.GetString(config.Flag.Service.Controller.LeaderElection.LeaseNamespace),
+			RenewDeadline:  config.Viper.GetDuration(config.Flag.Service.Controller.LeaderElection.RenewDeadline),
+			RetryPeriod:    config.Viper.GetDuration(config.Flag.Service.Controller.LeaderElection.RetryPeriod),
+
+			OnStartedLeading: func(ctx context.Context) {
+				if orphanSweeper != nil {
+					go orphanSweeper.Boot(ctx)
+				}
+				clusterController.Boot(ctx)
+			},
+			OnStoppedLeading: func() {
+				// The operatorkit controller cannot be stopped once booted, so
+				// the only way to stop reconciling is to exit and let another
+				// replica take over. Losing the lease is part of a normal
+				// handover, so it is logged instead of panicking.
+				err := microerror.Maskf(leadershipLostError, "lost lease %#q", config.Viper.GetString(config.Flag.Service.Controller.LeaderElection.LeaseName))
+				config.Logger.Errorf(context.Background(), err, "stopping operator")
+				os.Exit(1)
+			},
+		}
+
+		var err error
+		leaderElector, err = leaderelection.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var operatorCollector *collector.Set
 	{
 		c := collector.SetConfig{
 			K8sClient: k8sClient,
 			Logger:    config.Logger,
+
+			ClusterGroupVersion: clusterGroupVersion,
+
+			Gatherer:       ctrlmetrics.Registry,
+			ControllerName: controller.ClusterControllerName(),
 		}
 
 		var err error
@@ -181,6 +446,60 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	var readinessChecks []healthz.Service
+	{
+		apiServerCheck, err := readiness.NewAPIServer(readiness.APIServerConfig{
+			Discovery: k8sClient.K8sClient().Discovery(),
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		var infrastructureKinds []schema.GroupKind
+		for _, kind := range infra.ClusterKinds {
+			infrastructureKinds = append(infrastructureKinds, schema.GroupKind{Group: infra.Group, Kind: kind})
+		}
+
+		crdCheck, err := readiness.NewCRD(readiness.CRDConfig{
+			Discovery: k8sClient.K8sClient().Discovery(),
+
+			Required: []schema.GroupVersionKind{
+				appv1alpha1.SchemeGroupVersion.WithKind("App"),
+				clusterGroupVersion.WithKind("Cluster"),
+			},
+			AnyOf: infrastructureKinds,
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		controllerConfig := readiness.ControllerConfig{
+			Controller: clusterController,
+		}
+		if leaderElector != nil {
+			controllerConfig.Leader = leaderElector
+		}
+
+		controllerCheck, err := readiness.NewController(controllerConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		reconcileCheck, err := readiness.NewReconcile(readiness.ReconcileConfig{
+			Recorder: clusterStatus,
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		readinessChecks = []healthz.Service{
+			apiServerCheck,
+			crdCheck,
+			controllerCheck,
+			reconcileCheck,
+		}
+	}
+
 	var versionService *version.Service
 	{
 		c := version.Config{
@@ -199,11 +518,18 @@ func New(config Config) (*Service, error) {
 	}
 
 	s := &Service{
-		Version: versionService,
+		ClusterStatus: clusterStatus,
+		Readiness:     readinessChecks,
+		Version:       versionService,
 
-		bootOnce:          sync.Once{},
-		clusterController: clusterController,
-		operatorCollector: operatorCollector,
+		appCache:            appCache,
+		bootOnce:            sync.Once{},
+		clusterController:   clusterController,
+		clusterStatusPruner: clusterStatusPruner,
+		leaderElector:       leaderElector,
+		operatorCollector:   operatorCollector,
+		orphanSweeper:       orphanSweeper,
+		sharding:            shards,
 	}
 
 	return s, nil
@@ -211,8 +537,27 @@ func New(config Config) (*Service, error) {
 
 func (s *Service) Boot(ctx context.Context) {
 	s.bootOnce.Do(func() {
+		go s.appCache.Start(ctx)         // nolint:errcheck
 		go s.operatorCollector.Boot(ctx) // nolint:errcheck
+		go s.clusterStatusPruner.Boot(ctx)
 
-		go s.clusterController.Boot(ctx)
+		// With leader election enabled only the replica holding the lease
+		// reconciles clusters. With sharding enabled all replicas run the
+		// controller, each reconciling the clusters of its own shard. The
+		// collector and the HTTP endpoints are served by all replicas. The
+		// orphan sweeper runs next to the controller; with sharding only the
+		// primary replica sweeps. The status pruner runs on all replicas as it
+		// only drops the in-memory status this replica recorded itself.
+		if s.leaderElector != nil {
+			go s.leaderElector.Run(ctx)
+		} else {
+			if s.sharding != nil {
+				go s.sharding.Boot(ctx)
+			}
+			if s.orphanSweeper != nil {
+				go s.orphanSweeper.Boot(ctx)
+			}
+			go s.clusterController.Boot(ctx)
+		}
 	})
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/cluster-apps-operator/v3/service/controller/resource/clusterconfigmap"
+)
+
+// newValuesSchemaCommand returns the command printing the JSON schema of
+// the cluster values rendered into the <cluster>-cluster-values ConfigMap.
+func newValuesSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "values-schema",
+		Short: "Print the JSON schema of the generated cluster values.",
+		Long:  "Print the JSON schema of the cluster values consumed by chart-operator and workload cluster charts. Schema version " + clusterconfigmap.SchemaVersion + ".",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := clusterconfigmap.SchemaJSON()
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			_, err = cmd.OutOrStdout().Write(b)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			return nil
+		},
+	}
+}